@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKeyRequest 创建API密钥请求
+type CreateAPIKeyRequest struct {
+	Name          string     `json:"name" binding:"required"`
+	Permission    int        `json:"permission" binding:"required"`
+	ClassScopeIDs []int      `json:"class_scope_ids"` // 班级权限范围，为空表示沿用创建者的范围
+	ExpiresAt     *time.Time `json:"expires_at"`      // 过期时间，为空表示不过期
+}
+
+// GetAPIKeys 获取API密钥列表
+func GetAPIKeys(c *gin.Context) {
+	keys, err := models.GetAPIKeys()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取API密钥失败")
+		return
+	}
+
+	utils.ResponseOK(c, keys)
+}
+
+// CreateAPIKey 创建API密钥，明文密钥仅在创建时返回一次
+func CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	currentUser, err := middlewares.GetCurrentUser(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
+		return
+	}
+
+	// 密钥权限不能超出创建者的权限，权限与班级范围的组合规则与用户相同
+	if err := utils.ValidateUserCreateOrUpdate(currentUser.Permission, req.Permission, 0, req.ClassScopeIDs, false); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, rawKey, err := models.CreateAPIKey(strings.TrimSpace(req.Name), req.Permission, req.ClassScopeIDs, req.ExpiresAt, currentUser)
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyOwnerNotReady) {
+			utils.ResponseError(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, models.ErrAPIKeyNameRequired) ||
+			errors.Is(err, models.ErrAPIKeyScopeExceeded) ||
+			errors.Is(err, models.ErrInvalidExpireTime) {
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "创建API密钥失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"api_key": key,
+		"key":     rawKey, // 明文密钥，仅返回一次
+	})
+}
+
+// RevokeAPIKey 撤销API密钥
+func RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的API密钥ID")
+		return
+	}
+
+	if err := models.RevokeAPIKey(id); err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "撤销API密钥失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已撤销")
+}
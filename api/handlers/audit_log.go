@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditLogs 分页查询管理操作审计日志
+// 查询参数：actor_id、action、target_type、target_id、keyword、success（true/false）、
+// start_date、end_date（YYYY-MM-DD，含结束日期当天）、page、page_size
+func GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	filter := types.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Keyword:    c.Query("keyword"),
+	}
+	filter.ActorID, _ = strconv.Atoi(c.Query("actor_id"))
+	if success := c.Query("success"); success != "" {
+		value := success == "true"
+		filter.Success = &value
+	}
+	if startDate := c.Query("start_date"); startDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "无效的开始日期")
+			return
+		}
+		filter.StartTime = start
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "无效的结束日期")
+			return
+		}
+		filter.EndTime = end.AddDate(0, 0, 1)
+	}
+
+	logs, total, err := models.GetAuditLogs(filter, page, pageSize)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取审计日志失败")
+		return
+	}
+
+	utils.ResponsePaginated(c, logs, total, page, pageSize)
+}
+
+// GetAuditLog 获取审计日志详情（含操作前后的数据）
+func GetAuditLog(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的日志ID")
+		return
+	}
+
+	log, err := models.GetAuditLogByID(id)
+	if err != nil {
+		if errors.Is(err, models.ErrAuditLogNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "获取审计日志失败")
+		return
+	}
+
+	utils.ResponseOK(c, log)
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
 	"github.com/SHXZ-OSS/sports-meeting-system/services"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -14,16 +18,38 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginUser 登录响应中的用户信息
+type LoginUser struct {
+	ID            int    `json:"id"`
+	Username      string `json:"username"`
+	FullName      string `json:"full_name"`
+	Role          string `json:"role"`
+	Permission    int    `json:"permission"`
+	ClassScopeIDs []int  `json:"class_scope_ids,omitempty"` // 班级权限范围，为空表示全局
+	// 需先修改密码，为 true 时除修改密码与退出登录外的接口均不可访问
+	MustChangePassword bool `json:"must_change_password"`
+	// 需先启用两步验证，为 true 时除两步验证设置、修改密码与退出登录外的接口均不可访问
+	MustEnableTwoFactor bool `json:"must_enable_two_factor"`
+}
+
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  struct {
-		ID         int    `json:"id"`
-		Username   string `json:"username"`
-		FullName   string `json:"full_name"`
-		Role       string `json:"role"`
-		Permission int    `json:"permission"`
-	} `json:"user"`
+	Token        string    `json:"token"`         // 访问令牌
+	RefreshToken string    `json:"refresh_token"` // 刷新令牌，访问令牌过期后用于换取新令牌
+	ExpiresIn    int64     `json:"expires_in"`    // 访问令牌有效期（秒）
+	User         LoginUser `json:"user"`
+}
+
+// TwoFactorChallengeResponse 需两步验证时的登录响应
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	TwoFactorToken    string `json:"two_factor_token"` // 提交验证码时使用的凭据，5分钟内有效
+}
+
+// TwoFactorLoginRequest 两步验证登录请求
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // 动态验证码或恢复码
 }
 
 // DingTalkLoginRequest 钉钉登录请求
@@ -31,6 +57,54 @@ type DingTalkLoginRequest struct {
 	Code string `json:"code"`
 }
 
+// OIDCLoginRequest 统一身份认证登录请求
+type OIDCLoginRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// getClientInfo 获取登录客户端信息
+func getClientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// newLoginResponse 构建登录响应
+func newLoginResponse(tokens *services.TokenPair, user LoginUser) LoginResponse {
+	return LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
+	}
+}
+
+// responseLoginThrottled 返回登录受限（锁定或需等待）的错误响应
+func responseLoginThrottled(c *gin.Context, retryAfter int, err error) {
+	status := http.StatusTooManyRequests
+	if errors.Is(err, models.ErrAccountLocked) || errors.Is(err, models.ErrIPLocked) {
+		status = http.StatusLocked
+	}
+	message := err.Error()
+	if retryAfter > 0 {
+		message = fmt.Sprintf("%s，请%d秒后再试", message, retryAfter)
+	}
+	utils.ResponseError(c, status, message)
+}
+
 // Login 用户登录
 func Login(c *gin.Context) {
 	// 解析请求
@@ -40,63 +114,193 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// 检查账号与IP的登录失败限制
+	retryAfter, err := models.CheckLoginThrottle(req.Username, c.ClientIP())
+	if err != nil {
+		if retryAfter > 0 {
+			responseLoginThrottled(c, retryAfter, err)
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "登录失败")
+		return
+	}
+
 	// 验证用户凭据
-	token, user, err := services.Login(req.Username, req.Password)
+	tokens, user, challengeToken, err := services.Login(req.Username, req.Password, getClientInfo(c))
 	if err != nil {
 		// 尝试学生登录
 		studentLogin(c, req)
 		return
 	}
 
-	// 构建响应
-	resp := LoginResponse{
-		Token: token,
-		User: struct {
-			ID         int    `json:"id"`
-			Username   string `json:"username"`
-			FullName   string `json:"full_name"`
-			Role       string `json:"role"`
-			Permission int    `json:"permission"`
-		}{
-			ID:         user.ID,
-			Username:   user.Username,
-			FullName:   user.FullName,
-			Role:       string(services.RoleAdmin),
-			Permission: user.Permission,
-		},
+	// 已启用两步验证，验证码通过后才算登录成功
+	if challengeToken != "" {
+		utils.ResponseOK(c, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			TwoFactorToken:    challengeToken,
+		})
+		return
 	}
 
+	// 登录成功，清除账号的失败记录（记录失败不影响登录）
+	_ = models.ResetLoginFailures(req.Username)
+
 	// 返回响应
-	utils.ResponseOK(c, resp)
+	utils.ResponseOK(c, newLoginResponse(tokens, adminLoginUser(user)))
+}
+
+// TwoFactorLogin 两步验证登录（账号密码、钉钉或统一身份认证验证通过后提交动态验证码或恢复码）
+// 与账号密码登录共用账号与IP的登录失败限制，验证码错误计入失败次数
+func TwoFactorLogin(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	challengeUser, err := models.GetTwoFactorChallengeUser(req.TwoFactorToken)
+	if err != nil {
+		if errors.Is(err, models.ErrTwoFactorChallengeInvalid) {
+			utils.ResponseError(c, http.StatusUnauthorized, err.Error())
+		} else {
+			utils.ResponseError(c, http.StatusInternalServerError, "登录失败")
+		}
+		return
+	}
+
+	// 检查账号与IP的登录失败限制
+	retryAfter, err := models.CheckLoginThrottle(challengeUser.Username, c.ClientIP())
+	if err != nil {
+		if retryAfter > 0 {
+			responseLoginThrottled(c, retryAfter, err)
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "登录失败")
+		return
+	}
+
+	tokens, user, err := services.TwoFactorLogin(req.TwoFactorToken, req.Code, getClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrTwoFactorCodeIncorrect):
+			_ = models.RecordLoginFailure(challengeUser.Username, c.ClientIP())
+			utils.ResponseError(c, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, models.ErrTwoFactorChallengeInvalid):
+			utils.ResponseError(c, http.StatusUnauthorized, err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "登录失败")
+		}
+		return
+	}
+
+	_ = models.ResetLoginFailures(user.Username)
+
+	utils.ResponseOK(c, newLoginResponse(tokens, adminLoginUser(user)))
+}
+
+// adminLoginUser 构建账号密码登录的管理员用户信息
+func adminLoginUser(user *types.User) LoginUser {
+	return LoginUser{
+		ID:                  user.ID,
+		Username:            user.Username,
+		FullName:            user.FullName,
+		Role:                string(services.RoleAdmin),
+		Permission:          user.Permission,
+		ClassScopeIDs:       models.GetClassScopeIDs(user),
+		MustChangePassword:  user.MustChangePassword,
+		MustEnableTwoFactor: !user.TwoFactorEnabled && models.IsTwoFactorRequired(user),
+	}
 }
 
 // studentLogin 学生登录
 func studentLogin(c *gin.Context, req LoginRequest) {
 	// 验证学生凭据
-	token, student, err := services.StudentLogin(req.Username, req.Password)
+	tokens, student, err := services.StudentLogin(req.Username, req.Password, getClientInfo(c))
 	if err != nil {
+		// 管理员与学生均验证失败，记录失败次数
+		_ = models.RecordLoginFailure(req.Username, c.ClientIP())
 		utils.ResponseError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
-	// 构建响应
-	resp := LoginResponse{
-		Token: token,
-		User: struct {
-			ID         int    `json:"id"`
-			Username   string `json:"username"`
-			FullName   string `json:"full_name"`
-			Role       string `json:"role"`
-			Permission int    `json:"permission"`
-		}{
-			ID:         student.ID,
-			Username:   student.Username,
-			FullName:   student.FullName,
-			Role:       string(services.RoleStudent),
-			Permission: 0,
-		},
-	}
+	_ = models.ResetLoginFailures(req.Username)
+
 	// 返回响应
-	utils.ResponseOK(c, resp)
+	utils.ResponseOK(c, newLoginResponse(tokens, LoginUser{
+		ID:                 student.ID,
+		Username:           student.Username,
+		FullName:           student.FullName,
+		Role:               string(services.RoleStudent),
+		Permission:         0,
+		MustChangePassword: student.MustChangePassword,
+	}))
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌与刷新令牌
+// 同时返回数据库中最新的用户权限与班级权限范围，管理员调整的权限在访问令牌过期后即可生效
+func RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	tokens, session, err := services.RefreshToken(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, models.ErrAccountLocked) {
+			utils.ResponseError(c, http.StatusLocked, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var user LoginUser
+	switch services.UserRole(session.Role) {
+	case services.RoleAdmin:
+		admin, err := models.GetUserByID(session.UserID)
+		if err != nil {
+			utils.ResponseError(c, http.StatusUnauthorized, "user not found")
+			return
+		}
+		user = LoginUser{
+			ID:                 admin.ID,
+			Username:           admin.Username,
+			FullName:           admin.FullName,
+			Role:               session.Role,
+			Permission:         admin.Permission,
+			ClassScopeIDs:      models.GetClassScopeIDs(admin),
+			MustChangePassword: admin.MustChangePassword,
+			MustEnableTwoFactor: models.IsTwoFactorEnforcedMethod(session.Method) &&
+				!admin.TwoFactorEnabled && models.IsTwoFactorRequired(admin),
+		}
+	case services.RoleParent:
+		parent, err := models.GetParentByID(session.UserID)
+		if err != nil {
+			utils.ResponseError(c, http.StatusUnauthorized, "user not found")
+			return
+		}
+		user = LoginUser{
+			ID:       parent.ID,
+			Username: parent.DingTalkID,
+			FullName: parent.Name,
+			Role:     session.Role,
+		}
+	default:
+		student, err := models.GetStudentByID(session.UserID)
+		if err != nil {
+			utils.ResponseError(c, http.StatusUnauthorized, "user not found")
+			return
+		}
+		user = LoginUser{
+			ID:                 student.ID,
+			Username:           student.Username,
+			FullName:           student.FullName,
+			Role:               session.Role,
+			MustChangePassword: student.MustChangePassword,
+		}
+	}
+
+	utils.ResponseOK(c, newLoginResponse(tokens, user))
 }
 
 // DingTalkLogin 钉钉登录
@@ -109,15 +313,106 @@ func DingTalkLogin(c *gin.Context) {
 	}
 
 	// 进行钉钉免登录
-	token, userObj, err := services.DingTalkLogin(req.Code)
+	tokens, userObj, challengeToken, err := services.DingTalkLogin(req.Code, getClientInfo(c))
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// 管理员已启用两步验证，需继续提交动态验证码
+	if challengeToken != "" {
+		utils.ResponseOK(c, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			TwoFactorToken:    challengeToken,
+		})
+		return
+	}
+
+	// 返回响应（家长登录时 user 为家长信息，包含关联的学生）
+	utils.ResponseOK(c, map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          userObj,
+	})
+}
+
+// OIDCAuthorize 获取统一身份认证授权地址，前端跳转到该地址进行登录
+func OIDCAuthorize(c *gin.Context) {
+	url, err := services.OIDCAuthorize()
+	if err != nil {
+		if errors.Is(err, utils.ErrOIDCDisabled) {
+			utils.ResponseError(c, http.StatusForbidden, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "获取统一身份认证授权地址失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"authorization_url": url,
+	})
+}
+
+// OIDCLogin 统一身份认证登录（身份提供方回调后，前端提交授权码与 state）
+func OIDCLogin(c *gin.Context) {
+	// 解析请求
+	var req OIDCLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	tokens, userObj, challengeToken, err := services.OIDCLogin(req.Code, req.State, getClientInfo(c))
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
+	// 管理员已启用两步验证，需继续提交动态验证码
+	if challengeToken != "" {
+		utils.ResponseOK(c, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			TwoFactorToken:    challengeToken,
+		})
+		return
+	}
+
 	// 返回响应
 	utils.ResponseOK(c, map[string]interface{}{
-		"token": token,
-		"user":  userObj,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          userObj,
 	})
 }
+
+// ChangePassword 修改当前用户的密码（管理员与学生通用）
+// 修改成功后清除需修改密码标记，并退出其他设备上的登录，当前登录保持有效
+func ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	userID, role, sessionID, ok := getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := models.ChangePassword(userID, role, req.OldPassword, req.NewPassword, sessionID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrOldPasswordIncorrect),
+			errors.Is(err, models.ErrPasswordNotSupported),
+			errors.Is(err, utils.ErrPasswordTooShort),
+			errors.Is(err, utils.ErrPasswordUnchanged):
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "修改密码失败")
+		}
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "密码修改成功")
+}
@@ -34,13 +34,7 @@ func GetAllClasses(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -71,13 +65,7 @@ func GetClass(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -110,13 +98,7 @@ func CreateClass(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -156,13 +138,7 @@ func UpdateClass(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -203,13 +179,7 @@ func DeleteClass(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
 	"github.com/SHXZ-OSS/sports-meeting-system/services"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
@@ -37,7 +39,11 @@ func isValidCompetitionStatus(status types.CompetitionStatus) bool {
 		types.StatusApproved,
 		types.StatusRejected,
 		types.StatusPendingScoreReview,
-		types.StatusCompleted:
+		types.StatusCompleted,
+		types.StatusInProgress,
+		types.StatusPostponed,
+		types.StatusCancelled,
+		types.StatusChangesRequested:
 		return true
 	default:
 		return false
@@ -57,6 +63,8 @@ type CreateCompetitionRequest struct {
 	Unit                    string                `json:"unit" binding:"required"`                                   // 成绩单位
 	StartTime               *time.Time            `json:"start_time"`                                                // 比赛开始时间
 	EndTime                 *time.Time            `json:"end_time"`                                                  // 比赛结束时间
+	RequiresGuardianConsent bool                  `json:"requires_guardian_consent"`                                 // 报名是否需要家长确认
+	VenueID                 *int                  `json:"venue_id"`                                                  // 比赛场地
 }
 
 // UpdateCompetitionRequest 更新比赛项目请求
@@ -70,12 +78,19 @@ type UpdateCompetitionRequest struct {
 	Image                   string                `json:"image"`                                                     // Base64编码的图片
 	Unit                    string                `json:"unit" binding:"required"`                                   // 成绩单位
 	Gender                  int                   `json:"gender" binding:"required,min=1,max=3"`
-	StartTime               *time.Time            `json:"start_time"`  // 比赛开始时间
-	EndTime                 *time.Time            `json:"end_time"`    // 比赛结束时间
+	StartTime               *time.Time            `json:"start_time"`                // 比赛开始时间
+	EndTime                 *time.Time            `json:"end_time"`                  // 比赛结束时间
+	RequiresGuardianConsent bool                  `json:"requires_guardian_consent"` // 报名是否需要家长确认
+	VenueID                 *int                  `json:"venue_id"`                  // 比赛场地
 }
 
 // GetAllCompetitions 获取所有比赛项目
 func GetAllCompetitions(c *gin.Context) {
+	listCompetitions(c, nil)
+}
+
+// listCompetitions 获取当前届次的比赛项目列表，competitionIDs 不为 nil 时仅返回其中的比赛项目
+func listCompetitions(c *gin.Context, competitionIDs *[]int) {
 	// 解析分页参数
 	page, _ := strconv.Atoi(c.Query("page"))
 	pageSize, _ := strconv.Atoi(c.Query("page_size"))
@@ -104,7 +119,7 @@ func GetAllCompetitions(c *gin.Context) {
 	}
 
 	// 获取比赛列表
-	competitions, total, err := models.GetAllCompetitions(page, pageSize, statuses, 0, sortBy)
+	competitions, total, err := models.GetAllCompetitions(config.Get().CurrentEventID, page, pageSize, statuses, 0, sortBy, competitionIDs)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取比赛列表失败："+err.Error())
 		return
@@ -143,8 +158,8 @@ func GetAllEligibleCompetitions(c *gin.Context) {
 				utils.ResponseError(c, http.StatusBadRequest, "无效的状态值: "+s)
 				return
 			}
-			// 学生API不允许查询待审核和已拒绝的比赛
-			if status == types.StatusPendingApproval || status == types.StatusRejected {
+			// 学生API不允许查询仍在征集审核阶段（待审核、已拒绝、需修改）的比赛
+			if utils.IsProposalStatus(status) {
 				utils.ResponseError(c, http.StatusForbidden, "比赛项目不可见")
 				return
 			}
@@ -152,7 +167,7 @@ func GetAllEligibleCompetitions(c *gin.Context) {
 		}
 	} else {
 		// 默认显示非审核非拒绝比赛
-		statuses = []types.CompetitionStatus{types.StatusCompleted, types.StatusApproved, types.StatusPendingScoreReview}
+		statuses = []types.CompetitionStatus{types.StatusCompleted, types.StatusApproved, types.StatusPendingScoreReview, types.StatusInProgress, types.StatusPostponed, types.StatusCancelled}
 	}
 
 	studentId, ok := middlewares.GetUserIDFromContext(c)
@@ -167,7 +182,7 @@ func GetAllEligibleCompetitions(c *gin.Context) {
 	}
 
 	// 获取比赛列表
-	competitions, total, err := models.GetAllCompetitions(page, pageSize, statuses, student.Gender, sortBy)
+	competitions, total, err := models.GetAllCompetitions(config.Get().CurrentEventID, page, pageSize, statuses, student.Gender, sortBy, nil)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取比赛列表失败")
 		return
@@ -206,8 +221,8 @@ func GetAllPublicCompetitions(c *gin.Context) {
 				utils.ResponseError(c, http.StatusBadRequest, "无效的状态值: "+s)
 				return
 			}
-			// 公共API不允许查询待审核和已拒绝的比赛
-			if status == types.StatusPendingApproval || status == types.StatusRejected {
+			// 公共API不允许查询仍在征集审核阶段（待审核、已拒绝、需修改）的比赛
+			if utils.IsProposalStatus(status) {
 				utils.ResponseError(c, http.StatusForbidden, "比赛项目不可见")
 				return
 			}
@@ -218,8 +233,14 @@ func GetAllPublicCompetitions(c *gin.Context) {
 		statuses = []types.CompetitionStatus{types.StatusCompleted}
 	}
 
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
 	// 获取比赛列表
-	competitions, total, err := models.GetAllCompetitions(page, pageSize, statuses, 0, sortBy)
+	competitions, total, err := models.GetAllCompetitions(eventID, page, pageSize, statuses, 0, sortBy, nil)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取比赛列表失败")
 		return
@@ -242,14 +263,20 @@ func GetPublicCompetition(c *gin.Context) {
 		return
 	}
 
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
 	// 获取比赛信息
-	competition, err := models.GetCompetitionByID(id)
+	competition, err := models.GetEventCompetitionByID(eventID, id)
 	if err != nil {
 		utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
 		return
 	}
 
-	if competition.Status == types.StatusPendingApproval || competition.Status == types.StatusRejected {
+	if utils.IsProposalStatus(competition.Status) {
 		utils.ResponseError(c, http.StatusForbidden, "比赛项目不可见")
 		return
 	}
@@ -343,12 +370,24 @@ func CreateCompetition(c *gin.Context) {
 		req.CompetitionType = types.TypeIndividual
 	}
 
+	// 学生提交前检查当前届次是否已有疑似重复的推荐项目，仅提示不阻止提交
+	var duplicates []*types.DuplicateCandidate
+	if role == services.RoleStudent {
+		var err error
+		duplicates, err = models.FindDuplicateProposals(req.Name, 0)
+		if err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "检查重复项目失败")
+			return
+		}
+	}
+
 	// 创建比赛项目
 	var err error
 	if role == services.RoleStudent {
-		err = models.CreateCompetition(req.Name, req.Description, imagePath, req.Unit, req.Gender, req.RankingMode, req.CompetitionType, req.MinParticipantsPerClass, req.MaxParticipantsPerClass, studentID, req.StartTime, req.EndTime)
+		// 学生提交的项目不能要求家长确认或指定场地，由管理员审核时设置
+		err = models.CreateCompetition(req.Name, req.Description, imagePath, req.Unit, req.Gender, req.RankingMode, req.CompetitionType, req.MinParticipantsPerClass, req.MaxParticipantsPerClass, studentID, req.StartTime, req.EndTime, false, nil)
 	} else {
-		err = models.AdminCreateCompetition(req.Name, req.Description, imagePath, req.Unit, req.Gender, req.RankingMode, req.CompetitionType, req.MinParticipantsPerClass, req.MaxParticipantsPerClass, ID, req.StartTime, req.EndTime)
+		err = models.AdminCreateCompetition(req.Name, req.Description, imagePath, req.Unit, req.Gender, req.RankingMode, req.CompetitionType, req.MinParticipantsPerClass, req.MaxParticipantsPerClass, ID, req.StartTime, req.EndTime, req.RequiresGuardianConsent, req.VenueID)
 	}
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "创建比赛项目失败: "+err.Error())
@@ -356,6 +395,12 @@ func CreateCompetition(c *gin.Context) {
 	}
 
 	// 返回响应
+	if len(duplicates) > 0 {
+		utils.ResponseOKWithMessage(c, "创建成功，但已有名称相近的推荐项目，可能重复", map[string]interface{}{
+			"duplicates": duplicates,
+		})
+		return
+	}
 	utils.ResponseSuccessWithCustomMessage(c, "创建成功")
 }
 
@@ -389,6 +434,8 @@ func UpdateCompetition(c *gin.Context) {
 		utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
 		return
 	}
+	middlewares.SetAuditBefore(c, competition)
+
 	// 更新比赛项目
 	competition.Name = req.Name
 	competition.Description = req.Description
@@ -400,6 +447,9 @@ func UpdateCompetition(c *gin.Context) {
 	competition.MaxParticipantsPerClass = req.MaxParticipantsPerClass
 	competition.StartTime = req.StartTime
 	competition.EndTime = req.EndTime
+	competition.RequiresGuardianConsent = req.RequiresGuardianConsent
+	competition.VenueID = req.VenueID
+	competition.Venue = nil
 
 	// 确保图片目录存在
 	uploadDir := "./data/uploads"
@@ -427,6 +477,7 @@ func UpdateCompetition(c *gin.Context) {
 		utils.ResponseError(c, http.StatusInternalServerError, "更新比赛项目失败: "+err.Error())
 		return
 	}
+	auditCompetition(c, id, middlewares.SetAuditAfter)
 
 	// 返回响应
 	utils.ResponseSuccessWithCustomMessage(c, "更新成功")
@@ -460,6 +511,7 @@ func DeleteCompetition(c *gin.Context) {
 	}
 
 	// 删除比赛项目
+	auditCompetition(c, id, middlewares.SetAuditBefore)
 	if err := models.DeleteCompetition(id); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除比赛项目失败")
 		return
@@ -471,14 +523,20 @@ func DeleteCompetition(c *gin.Context) {
 
 // GetStatistics 获取最新比赛结果统计（看板API）
 func GetStatistics(c *gin.Context) {
-	results, err := models.GetStatistics()
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	results, err := models.GetStatistics(eventID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取统计数据失败")
 		return
 	}
 
 	// 获取最新的哈希值
-	currentHash := models.GetStatisticsHash()
+	currentHash := models.GetStatisticsHash(eventID)
 
 	// 设置 ETag 响应头
 	c.Header("ETag", fmt.Sprintf(`W/"%s"`, currentHash))
@@ -512,15 +570,23 @@ func ApproveCompetition(c *gin.Context) {
 	}
 
 	// 审核通过比赛项目
+	auditCompetition(c, id, middlewares.SetAuditBefore)
 	if err := models.ApproveCompetitionByID(id, userID); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "更新比赛项目失败")
 		return
 	}
+	auditCompetition(c, id, middlewares.SetAuditAfter)
 
 	// 返回响应
 	utils.ResponseSuccessWithCustomMessage(c, "审核成功")
 }
 
+// RejectCompetitionRequest 审核拒绝比赛项目请求
+type RejectCompetitionRequest struct {
+	Reason         string `json:"reason"`          // 拒绝原因，会展示给提交的学生
+	RequestChanges bool   `json:"request_changes"` // 是否要求学生修改后重新提交，为true时必须填写原因
+}
+
 // RejectCompetition 审核拒绝比赛项目
 func RejectCompetition(c *gin.Context) {
 	// 解析路径参数
@@ -535,12 +601,352 @@ func RejectCompetition(c *gin.Context) {
 		return
 	}
 
+	// 请求体可选，未提供时直接拒绝且不附带原因
+	var req RejectCompetitionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+			return
+		}
+	}
+
 	// 审核拒绝比赛项目
-	if err := models.RejectCompetitionByID(id, userID); err != nil {
+	auditCompetition(c, id, middlewares.SetAuditBefore)
+	if err := models.RejectCompetitionByID(id, userID, strings.TrimSpace(req.Reason), req.RequestChanges); err != nil {
+		if errors.Is(err, utils.ErrChangesReasonRequired) {
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.ResponseError(c, http.StatusInternalServerError, "更新比赛项目失败")
 		return
 	}
+	auditCompetition(c, id, middlewares.SetAuditAfter)
 
 	// 返回响应
 	utils.ResponseSuccessWithCustomMessage(c, "审核成功")
 }
+
+// GetCompetitionRevisions 获取推荐项目的提交版本记录（管理员）
+func GetCompetitionRevisions(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	if _, err := models.GetCompetitionByID(id); err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
+		return
+	}
+
+	revisions, err := models.GetCompetitionRevisions(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取提交版本记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, revisions)
+}
+
+// GetMyProposals 获取当前学生提交的推荐项目（包含审核状态与拒绝原因）
+func GetMyProposals(c *gin.Context) {
+	studentID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	proposals, err := models.GetStudentProposals(studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取推荐项目失败")
+		return
+	}
+
+	utils.ResponseOK(c, proposals)
+}
+
+// ResubmitProposal 学生修改并重新提交推荐项目
+func ResubmitProposal(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	// 解析请求（家长确认与场地由管理员设置，学生提交时忽略）
+	var req CreateCompetitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	studentID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	existing, err := models.GetCompetitionByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
+		return
+	}
+
+	// 验证比赛类型，默认为个人比赛
+	if req.CompetitionType != types.TypeIndividual && req.CompetitionType != types.TypeTeam {
+		req.CompetitionType = types.TypeIndividual
+	}
+
+	competition := &types.Competition{
+		ID:                      id,
+		Name:                    req.Name,
+		Description:             req.Description,
+		ImagePath:               existing.ImagePath,
+		RankingMode:             req.RankingMode,
+		Unit:                    req.Unit,
+		Gender:                  req.Gender,
+		CompetitionType:         req.CompetitionType,
+		MinParticipantsPerClass: req.MinParticipantsPerClass,
+		MaxParticipantsPerClass: req.MaxParticipantsPerClass,
+		StartTime:               req.StartTime,
+		EndTime:                 req.EndTime,
+	}
+
+	// 上传了新图片时替换原图片
+	if req.Image != "" {
+		uploadDir := "./data/uploads"
+		if err := os.MkdirAll(uploadDir, 0755); err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "创建目录失败")
+			return
+		}
+		timestamp := time.Now().Unix()
+		fileName, err := utils.SaveBase64Image(req.Image, uploadDir, "competition_"+strconv.Itoa(studentID), timestamp)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "保存图片失败: "+err.Error())
+			return
+		}
+		if fileName != "" {
+			competition.ImagePath = filepath.Join("/uploads", fileName)
+		}
+	}
+
+	if err := models.ResubmitCompetitionProposal(competition, studentID); err != nil {
+		switch {
+		case errors.Is(err, utils.ErrNotProposalSubmitter), errors.Is(err, utils.ErrSubmissionNotAllowed), errors.Is(err, utils.ErrProposalNotEditable):
+			utils.ResponseError(c, http.StatusForbidden, err.Error())
+		default:
+			utils.ResponseError(c, http.StatusBadRequest, "重新提交失败: "+err.Error())
+		}
+		return
+	}
+
+	// 提示名称相近的推荐项目
+	duplicates, err := models.FindDuplicateProposals(competition.Name, id)
+	if err == nil && len(duplicates) > 0 {
+		utils.ResponseOKWithMessage(c, "重新提交成功，但已有名称相近的推荐项目，可能重复", map[string]interface{}{
+			"duplicates": duplicates,
+		})
+		return
+	}
+	utils.ResponseSuccessWithCustomMessage(c, "重新提交成功")
+}
+
+// CheckDuplicateProposals 提交前检查当前届次是否已有名称相近的推荐项目
+func CheckDuplicateProposals(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" {
+		utils.ResponseError(c, http.StatusBadRequest, "项目名称不能为空")
+		return
+	}
+
+	duplicates, err := models.FindDuplicateProposals(name, 0)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "检查重复项目失败")
+		return
+	}
+
+	utils.ResponseOK(c, duplicates)
+}
+
+// GetCompetitionDuplicates 获取与指定项目名称相近的项目（管理员审核时使用）
+func GetCompetitionDuplicates(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	competition, err := models.GetCompetitionByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
+		return
+	}
+
+	duplicates, err := models.FindDuplicateProposals(competition.Name, id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "检查重复项目失败")
+		return
+	}
+
+	utils.ResponseOK(c, duplicates)
+}
+
+// MergeCompetitionsRequest 合并重复推荐项目请求
+type MergeCompetitionsRequest struct {
+	SourceIDs []int `json:"source_ids" binding:"required"` // 要合并到当前项目的重复推荐项目
+}
+
+// MergeCompetitions 将重复的推荐项目合并到指定项目（合并投票与提交者署名）
+func MergeCompetitions(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	var req MergeCompetitionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := models.MergeCompetitions(id, req.SourceIDs, userID); err != nil {
+		if errors.Is(err, utils.ErrCompetitionNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusBadRequest, "合并失败: "+err.Error())
+		return
+	}
+
+	competition, err := models.GetCompetitionByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取比赛项目失败")
+		return
+	}
+
+	utils.ResponseOK(c, competition)
+}
+
+// GetMyProposalRevisions 获取当前学生推荐项目的提交版本记录
+func GetMyProposalRevisions(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	studentID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	competition, err := models.GetCompetitionByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
+		return
+	}
+	if competition.SubmitterID == nil || *competition.SubmitterID != studentID {
+		utils.ResponseError(c, http.StatusForbidden, "只能查看自己提交的项目")
+		return
+	}
+
+	revisions, err := models.GetCompetitionRevisions(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取提交版本记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, revisions)
+}
+
+// ChangeCompetitionStatusRequest 变更比赛状态请求
+type ChangeCompetitionStatusRequest struct {
+	Status    types.CompetitionStatus `json:"status" binding:"required"`
+	Reason    string                  `json:"reason"`     // 变更原因，延期和取消时必填
+	StartTime *time.Time              `json:"start_time"` // 新的开始时间，为空表示不修改
+	EndTime   *time.Time              `json:"end_time"`   // 新的结束时间，为空表示不修改
+}
+
+// ChangeCompetitionStatus 变更比赛状态（进行中、延期、取消、恢复）
+func ChangeCompetitionStatus(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	var req ChangeCompetitionStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if !isValidCompetitionStatus(req.Status) {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的状态值: "+string(req.Status))
+		return
+	}
+
+	auditCompetition(c, id, middlewares.SetAuditBefore)
+	if err := models.ChangeCompetitionStatus(id, req.Status, strings.TrimSpace(req.Reason), req.StartTime, req.EndTime, userID); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "变更比赛状态失败: "+err.Error())
+		return
+	}
+	auditCompetition(c, id, middlewares.SetAuditAfter)
+
+	utils.ResponseSuccessWithCustomMessage(c, "状态已更新")
+}
+
+// GetCompetitionStatusLogs 获取比赛状态变更记录
+func GetCompetitionStatusLogs(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	competition, err := models.GetCompetitionByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
+		return
+	}
+
+	logs, err := models.GetCompetitionStatusLogs(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取状态变更记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"status":              competition.Status,
+		"allowed_transitions": utils.GetAllowedStatusTransitions(competition.Status),
+		"logs":                logs,
+	})
+}
+
+// auditCompetition 将比赛项目的当前数据记录为审计日志的操作前或操作后快照
+func auditCompetition(c *gin.Context, id int, set func(*gin.Context, interface{})) {
+	if competition, err := models.GetCompetitionByID(id); err == nil {
+		set(c, competition)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CompetitionOfficialRequest 指派裁判
+type CompetitionOfficialRequest struct {
+	UserID int                `json:"user_id" binding:"required"`
+	Role   types.OfficialRole `json:"role" binding:"required"`
+}
+
+// UpdateCompetitionOfficialsRequest 更新比赛项目裁判请求
+type UpdateCompetitionOfficialsRequest struct {
+	Officials []CompetitionOfficialRequest `json:"officials"`
+}
+
+// GetCompetitionOfficials 获取比赛项目指派的裁判
+func GetCompetitionOfficials(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	officials, err := models.GetCompetitionOfficials(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取裁判指派失败")
+		return
+	}
+
+	utils.ResponseOK(c, officials)
+}
+
+// UpdateCompetitionOfficials 更新比赛项目指派的裁判（整体替换）
+func UpdateCompetitionOfficials(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的比赛ID")
+		return
+	}
+
+	var req UpdateCompetitionOfficialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	officials := make([]*types.CompetitionOfficial, 0, len(req.Officials))
+	for _, official := range req.Officials {
+		officials = append(officials, &types.CompetitionOfficial{
+			UserID: official.UserID,
+			Role:   official.Role,
+		})
+	}
+
+	if err := models.SetCompetitionOfficials(id, officials); err != nil {
+		if errors.Is(err, utils.ErrCompetitionNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusBadRequest, "更新裁判指派失败: "+err.Error())
+		return
+	}
+
+	updated, err := models.GetCompetitionOfficials(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取裁判指派失败")
+		return
+	}
+
+	utils.ResponseOK(c, updated)
+}
+
+// getCurrentUser 获取当前登录的管理员用户，失败时直接返回错误响应
+func getCurrentUser(c *gin.Context) (*types.User, bool) {
+	user, err := middlewares.GetCurrentUser(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// requireCompetitionOfficial 检查当前用户能否以指定角色处理比赛项目（裁判账号仅能处理被指派的比赛），失败时直接返回错误响应
+func requireCompetitionOfficial(c *gin.Context, competitionID int, roles ...types.OfficialRole) bool {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return false
+	}
+
+	allowed, err := models.CanOfficiateCompetition(user, competitionID, roles...)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取裁判指派失败")
+		return false
+	}
+	if !allowed {
+		utils.ResponseError(c, http.StatusForbidden, models.ErrNotCompetitionOfficial.Error())
+		return false
+	}
+
+	return true
+}
+
+// listOfficialCompetitions 获取当前用户能以指定角色处理的比赛项目列表
+func listOfficialCompetitions(c *gin.Context, roles ...types.OfficialRole) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	competitionIDs, err := models.GetOfficialCompetitionScope(user, roles...)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取裁判指派失败")
+		return
+	}
+
+	listCompetitions(c, competitionIDs)
+}
+
+// GetScoreInputCompetitions 获取成绩录入的比赛列表（裁判账号仅返回担任记分员的比赛）
+func GetScoreInputCompetitions(c *gin.Context) {
+	listOfficialCompetitions(c, types.OfficialRoleScorer)
+}
+
+// GetStartListCompetitions 获取可查看检录名单的比赛列表（裁判账号仅返回担任记分员或发令员的比赛）
+func GetStartListCompetitions(c *gin.Context) {
+	listOfficialCompetitions(c, types.OfficialRoleScorer, types.OfficialRoleStarter)
+}
+
+// GetScoreReviewCompetitions 获取成绩审核的比赛列表（裁判账号仅返回担任审核员的比赛）
+func GetScoreReviewCompetitions(c *gin.Context) {
+	listOfficialCompetitions(c, types.OfficialRoleReviewer)
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/services"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ConsentResponse 家长确认页面响应
+type ConsentResponse struct {
+	StudentName     string              `json:"student_name"`
+	ClassName       string              `json:"class_name"`
+	CompetitionName string              `json:"competition_name"`
+	StartTime       *time.Time          `json:"start_time,omitempty"`
+	EndTime         *time.Time          `json:"end_time,omitempty"`
+	Status          types.ConsentStatus `json:"status"`
+	ExpiresAt       time.Time           `json:"expires_at"`
+}
+
+// notifyGuardiansAsync 异步通知家长确认报名
+func notifyGuardiansAsync(consentID int) {
+	go func() {
+		consent, err := models.GetConsentByID(consentID)
+		if err != nil {
+			utils.LogError("获取家长确认记录失败: " + err.Error())
+			return
+		}
+		if err := services.NotifyGuardians(consent, false); err != nil {
+			utils.LogError("通知家长确认报名失败: " + err.Error())
+		}
+	}()
+}
+
+// GetConsentByToken 获取家长确认信息（公共API，通过确认链接访问）
+func GetConsentByToken(c *gin.Context) {
+	consent, err := models.GetConsentByToken(c.Param("token"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "确认链接无效")
+		return
+	}
+
+	utils.ResponseOK(c, ConsentResponse{
+		StudentName:     consent.StudentName,
+		ClassName:       consent.ClassName,
+		CompetitionName: consent.CompetitionName,
+		StartTime:       consent.Competition.StartTime,
+		EndTime:         consent.Competition.EndTime,
+		Status:          consent.Status,
+		ExpiresAt:       consent.ExpiresAt,
+	})
+}
+
+// ApproveConsentByToken 家长同意报名（公共API，通过确认链接访问）
+func ApproveConsentByToken(c *gin.Context) {
+	consent, err := models.GetConsentByToken(c.Param("token"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "确认链接无效")
+		return
+	}
+
+	if err := models.ApproveConsent(consent.ID, "guardian"); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "确认失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已同意报名")
+}
+
+// RejectConsentByToken 家长拒绝报名（公共API，通过确认链接访问）
+func RejectConsentByToken(c *gin.Context) {
+	consent, err := models.GetConsentByToken(c.Param("token"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "确认链接无效")
+		return
+	}
+
+	if err := models.RejectConsent(consent.ID, "guardian"); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "操作失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已拒绝报名")
+}
+
+// GetStudentConsents 获取学生的家长确认记录（学生端使用）
+func GetStudentConsents(c *gin.Context) {
+	// 从上下文获取学生ID
+	studentID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	consents, err := models.GetConsentsByStudentID(studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取家长确认记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, consents)
+}
+
+// GetConsents 获取家长确认记录列表（管理员）
+func GetConsents(c *gin.Context) {
+	// 获取当前用户信息
+	user, err := middlewares.GetCurrentUser(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
+		return
+	}
+
+	// 计算scope
+	var scopeClassIDs *[]int
+	if !models.IsGlobalAdmin(user) {
+		ids := models.GetClassScopeIDs(user)
+		scopeClassIDs = &ids
+	}
+
+	consents, err := models.GetConsents(types.ConsentStatus(c.Query("status")), scopeClassIDs)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取家长确认记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, consents)
+}
+
+// getConsentForAdmin 获取确认记录并检查管理员的班级权限
+func getConsentForAdmin(c *gin.Context) (*types.RegistrationConsent, *types.User, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的确认记录ID")
+		return nil, nil, false
+	}
+
+	user, err := middlewares.GetCurrentUser(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
+		return nil, nil, false
+	}
+
+	consent, err := models.GetConsentByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, err.Error())
+		return nil, nil, false
+	}
+
+	if consent.Student == nil || !models.HasClassScope(user, consent.Student.ClassID) {
+		utils.ResponseError(c, http.StatusForbidden, "权限不足")
+		return nil, nil, false
+	}
+
+	return consent, user, true
+}
+
+// ApproveConsentForAdmin 管理员代为确认报名（如家长已线下签署同意书）
+func ApproveConsentForAdmin(c *gin.Context) {
+	consent, user, ok := getConsentForAdmin(c)
+	if !ok {
+		return
+	}
+
+	if err := models.ApproveConsent(consent.ID, "admin:"+user.Username); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "确认失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "确认成功")
+}
+
+// RejectConsentForAdmin 管理员拒绝报名
+func RejectConsentForAdmin(c *gin.Context) {
+	consent, user, ok := getConsentForAdmin(c)
+	if !ok {
+		return
+	}
+
+	if err := models.RejectConsent(consent.ID, "admin:"+user.Username); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "操作失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已拒绝报名")
+}
+
+// ResendConsentNotification 重新发送家长确认通知
+func ResendConsentNotification(c *gin.Context) {
+	consent, _, ok := getConsentForAdmin(c)
+	if !ok {
+		return
+	}
+
+	if consent.Status != types.ConsentPending {
+		utils.ResponseError(c, http.StatusBadRequest, models.ErrConsentAlreadyHandled.Error())
+		return
+	}
+
+	if err := services.NotifyGuardians(consent, true); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "发送通知失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "通知已发送")
+}
+
+// GetParentConsents 获取家长关联的所有学生在当前运动会的家长确认记录（家长端使用）
+func GetParentConsents(c *gin.Context) {
+	parent, err := middlewares.GetCurrentParent(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	studentIDs, err := models.GetParentChildIDs(parent.DingTalkID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取关联学生失败")
+		return
+	}
+
+	consents, err := models.GetConsentsByStudentIDs(studentIDs)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取家长确认记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, consents)
+}
+
+// getConsentForParent 获取确认记录并检查是否为家长关联的学生
+func getConsentForParent(c *gin.Context) (*types.RegistrationConsent, *types.Parent, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的确认记录ID")
+		return nil, nil, false
+	}
+
+	parent, err := middlewares.GetCurrentParent(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return nil, nil, false
+	}
+
+	consent, err := models.GetConsentByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, err.Error())
+		return nil, nil, false
+	}
+
+	isChild, err := models.IsParentOfStudent(parent.DingTalkID, consent.StudentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取关联学生失败")
+		return nil, nil, false
+	}
+	if !isChild {
+		// 不暴露其他学生的确认记录是否存在
+		utils.ResponseError(c, http.StatusNotFound, models.ErrConsentNotFound.Error())
+		return nil, nil, false
+	}
+
+	return consent, parent, true
+}
+
+// ApproveConsentForParent 家长在家长端同意报名
+func ApproveConsentForParent(c *gin.Context) {
+	consent, parent, ok := getConsentForParent(c)
+	if !ok {
+		return
+	}
+
+	if err := models.ApproveConsent(consent.ID, "parent:"+parent.DingTalkID); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "确认失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已同意报名")
+}
+
+// RejectConsentForParent 家长在家长端拒绝报名
+func RejectConsentForParent(c *gin.Context) {
+	consent, parent, ok := getConsentForParent(c)
+	if !ok {
+		return
+	}
+
+	if err := models.RejectConsent(consent.ID, "parent:"+parent.DingTalkID); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "操作失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已拒绝报名")
+}
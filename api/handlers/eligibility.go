@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// EligibilityRequest 创建或更新参赛资格限制请求
+type EligibilityRequest struct {
+	Type       types.EligibilityType `json:"type" binding:"required,oneof=medical_exemption disciplinary_ban"`
+	StartDate  *time.Time            `json:"start_date"` // 生效时间，为空表示立即生效
+	EndDate    *time.Time            `json:"end_date"`   // 失效时间，为空表示长期有效
+	Categories string                `json:"categories"` // 受影响的项目类别（all、individual、team 或比赛项目ID），逗号分隔，为空表示全部项目
+	Note       string                `json:"note"`
+}
+
+// getStudentForEligibility 获取学生并检查当前管理员的班级权限
+func getStudentForEligibility(c *gin.Context) (*types.Student, int, bool) {
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的学生ID")
+		return nil, 0, false
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return nil, 0, false
+	}
+
+	user, err := middlewares.GetCurrentUser(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
+		return nil, 0, false
+	}
+
+	student, err := models.GetStudentByID(studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "学生不存在")
+		return nil, 0, false
+	}
+
+	// 权限验证：全局管理员或有该学生所在班级权限的用户可以管理
+	if !models.HasClassScope(user, student.ClassID) {
+		utils.ResponseError(c, http.StatusForbidden, "权限不足")
+		return nil, 0, false
+	}
+
+	return student, userID, true
+}
+
+// getEligibilityOfStudent 获取属于指定学生的参赛资格限制记录
+func getEligibilityOfStudent(c *gin.Context, studentID int) (*types.StudentEligibility, bool) {
+	eligibilityID, err := strconv.Atoi(c.Param("eligibility_id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的记录ID")
+		return nil, false
+	}
+
+	eligibility, err := models.GetEligibilityByID(eligibilityID)
+	if err != nil || eligibility.StudentID != studentID {
+		utils.ResponseError(c, http.StatusNotFound, "参赛资格限制记录不存在")
+		return nil, false
+	}
+
+	return eligibility, true
+}
+
+// GetStudentEligibilities 获取学生的参赛资格限制记录
+func GetStudentEligibilities(c *gin.Context) {
+	student, _, ok := getStudentForEligibility(c)
+	if !ok {
+		return
+	}
+
+	eligibilities, err := models.GetEligibilitiesByStudentID(student.ID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取参赛资格限制记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, eligibilities)
+}
+
+// CreateStudentEligibility 为学生添加参赛资格限制
+func CreateStudentEligibility(c *gin.Context) {
+	var req EligibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	student, userID, ok := getStudentForEligibility(c)
+	if !ok {
+		return
+	}
+
+	eligibility := &types.StudentEligibility{
+		StudentID:  student.ID,
+		Type:       req.Type,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		Categories: req.Categories,
+		Note:       req.Note,
+		CreatedBy:  &userID,
+	}
+	if err := models.CreateEligibility(eligibility); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "添加参赛资格限制失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, eligibility)
+}
+
+// UpdateStudentEligibility 更新学生的参赛资格限制
+func UpdateStudentEligibility(c *gin.Context) {
+	var req EligibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	student, _, ok := getStudentForEligibility(c)
+	if !ok {
+		return
+	}
+
+	eligibility, ok := getEligibilityOfStudent(c, student.ID)
+	if !ok {
+		return
+	}
+
+	eligibility.Type = req.Type
+	eligibility.StartDate = req.StartDate
+	eligibility.EndDate = req.EndDate
+	eligibility.Categories = req.Categories
+	eligibility.Note = req.Note
+	if err := models.UpdateEligibility(eligibility); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "更新参赛资格限制失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "更新成功")
+}
+
+// DeleteStudentEligibility 删除学生的参赛资格限制
+func DeleteStudentEligibility(c *gin.Context) {
+	student, _, ok := getStudentForEligibility(c)
+	if !ok {
+		return
+	}
+
+	eligibility, ok := getEligibilityOfStudent(c, student.ID)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteEligibility(eligibility.ID); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "删除参赛资格限制失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "删除成功")
+}
@@ -3,9 +3,12 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
 	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -113,3 +116,168 @@ func SwitchEvent(c *gin.Context) {
 	// 返回响应
 	utils.ResponseSuccessWithCustomMessage(c, "切换成功")
 }
+
+// CloneCompetitionsRequest 从往届运动会复制比赛项目请求
+type CloneCompetitionsRequest struct {
+	CompetitionIDs  []int  `json:"competition_ids"`   // 要复制的比赛ID，为空时复制所有已完成的比赛
+	TargetStartDate string `json:"target_start_date"` // 新的比赛开始日期，格式 2006-01-02，为空时不设置比赛时间
+}
+
+// GetEventCompetitions 获取指定运动会届次的比赛项目
+func GetEventCompetitions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的运动会ID")
+		return
+	}
+
+	if _, err := models.GetEventByID(id); err != nil {
+		utils.ResponseError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	competitions, err := models.GetCompetitionsByEventID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取比赛列表失败")
+		return
+	}
+
+	utils.ResponseOK(c, competitions)
+}
+
+// CloneCompetitions 从往届运动会复制比赛项目到当前运动会
+func CloneCompetitions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的运动会ID")
+		return
+	}
+
+	var req CloneCompetitionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	var targetStartDate *time.Time
+	if req.TargetStartDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.TargetStartDate, time.Local)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "日期格式应为 YYYY-MM-DD")
+			return
+		}
+		targetStartDate = &date
+	}
+
+	userID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	cloned, skipped, err := models.CloneCompetitionsFromEvent(id, req.CompetitionIDs, targetStartDate, userID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "复制比赛项目失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"cloned":  cloned,
+		"skipped": skipped, // 当前运动会已存在同名项目而被跳过的比赛
+	})
+}
+
+// GetEventSettings 获取指定运动会届次的设置
+func GetEventSettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的运动会ID")
+		return
+	}
+
+	if _, err := models.GetEventByID(id); err != nil {
+		utils.ResponseError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	settings, err := models.GetEventSettings(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取运动会届次设置失败")
+		return
+	}
+
+	utils.ResponseOK(c, settings)
+}
+
+// UpdateEventSettings 更新指定运动会届次的设置（仅重新计算该届次的得分）
+func UpdateEventSettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的运动会ID")
+		return
+	}
+
+	var req types.EventSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	if before, err := models.GetEventSettings(id); err == nil {
+		middlewares.SetAuditBefore(c, before)
+	}
+	if err := models.UpdateEventSettings(id, &req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "更新运动会届次设置失败: "+err.Error())
+		return
+	}
+	if after, err := models.GetEventSettings(id); err == nil {
+		middlewares.SetAuditAfter(c, after)
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "更新成功")
+}
+
+// GetPublicEvents 获取运动会届次列表（公共API，供看板切换届次）
+func GetPublicEvents(c *gin.Context) {
+	events, err := models.GetAllEvents()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取运动会届次列表失败")
+		return
+	}
+
+	// 仅返回届次的基本信息，不包含时间安排等设置
+	list := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		list = append(list, map[string]interface{}{
+			"id":   event.ID,
+			"name": event.Name,
+		})
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"list":             list,
+		"current_event_id": config.Get().CurrentEventID,
+	})
+}
+
+// getRequestedEventID 解析请求中的 event_id 查询参数，未指定时使用当前届次
+// 解析失败或届次不存在时直接返回错误响应，调用方应在 ok 为 false 时结束处理
+func getRequestedEventID(c *gin.Context) (int, bool) {
+	eventIDStr := c.Query("event_id")
+	if eventIDStr == "" {
+		return config.Get().CurrentEventID, true
+	}
+
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的运动会ID")
+		return 0, false
+	}
+
+	if _, err := models.GetEventByID(eventID); err != nil {
+		utils.ResponseError(c, http.StatusNotFound, err.Error())
+		return 0, false
+	}
+
+	return eventID, true
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetLoginThrottles 获取账号与IP的登录失败记录及锁定状态
+// 查询参数 locked=true 时仅返回锁定中的记录
+func GetLoginThrottles(c *gin.Context) {
+	lockedOnly := c.Query("locked") == "true"
+
+	throttles, err := models.GetLoginThrottles(lockedOnly)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取登录限制记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, throttles)
+}
+
+// ClearLoginThrottle 清除登录失败记录，解除账号或IP的锁定
+func ClearLoginThrottle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的记录ID")
+		return
+	}
+
+	if err := models.ClearLoginThrottle(id); err != nil {
+		if errors.Is(err, models.ErrLoginThrottleNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "解除锁定失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已解除锁定")
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetParentProfile 获取当前家长信息及关联的学生
+func GetParentProfile(c *gin.Context) {
+	parent, err := middlewares.GetCurrentParent(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	children, err := models.GetParentChildren(parent.DingTalkID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取关联学生失败")
+		return
+	}
+	parent.Children = children
+
+	utils.ResponseOK(c, parent)
+}
+
+// getParentChildID 解析路径中的学生ID并检查是否为当前家长关联的学生，失败时直接返回错误响应
+func getParentChildID(c *gin.Context) (int, bool) {
+	studentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的学生ID")
+		return 0, false
+	}
+
+	parent, err := middlewares.GetCurrentParent(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return 0, false
+	}
+
+	isChild, err := models.IsParentOfStudent(parent.DingTalkID, studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取关联学生失败")
+		return 0, false
+	}
+	if !isChild {
+		utils.ResponseError(c, http.StatusForbidden, models.ErrNotParentChild.Error())
+		return 0, false
+	}
+
+	return studentID, true
+}
+
+// GetChildRegistrations 获取孩子的报名记录
+func GetChildRegistrations(c *gin.Context) {
+	studentID, ok := getParentChildID(c)
+	if !ok {
+		return
+	}
+
+	competitions, err := models.GetStudentRegistrationsByStudentID(studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取报名记录失败")
+		return
+	}
+
+	utils.ResponseOK(c, competitions)
+}
+
+// GetChildSchedule 获取孩子在当前运动会已安排时间的比赛日程，按开始时间排序
+func GetChildSchedule(c *gin.Context) {
+	studentID, ok := getParentChildID(c)
+	if !ok {
+		return
+	}
+
+	competitions, err := models.GetStudentRegistrationsByStudentID(studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取报名记录失败")
+		return
+	}
+
+	eventID := config.Get().CurrentEventID
+	schedule := make([]*types.Competition, 0, len(competitions))
+	for _, competition := range competitions {
+		if competition.EventID == eventID && competition.StartTime != nil {
+			schedule = append(schedule, competition)
+		}
+	}
+	sort.Slice(schedule, func(i, j int) bool {
+		return schedule[i].StartTime.Before(*schedule[j].StartTime)
+	})
+
+	utils.ResponseOK(c, schedule)
+}
+
+// GetChildScores 获取孩子在当前运动会的成绩
+func GetChildScores(c *gin.Context) {
+	studentID, ok := getParentChildID(c)
+	if !ok {
+		return
+	}
+
+	scores, err := models.GetScoresByStudentID(config.Get().CurrentEventID, studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取成绩失败")
+		return
+	}
+
+	utils.ResponseOK(c, scores)
+}
+
+// GetChildPointsSummary 获取孩子在当前运动会的得分和排名
+func GetChildPointsSummary(c *gin.Context) {
+	studentID, ok := getParentChildID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := models.GetStudentPointsSummaryByID(config.Get().CurrentEventID, studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, summary)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"github.com/gin-gonic/gin"
@@ -47,7 +48,13 @@ func AddCustomPointsToClass(c *gin.Context) {
 
 // GetClassPointsSummary 获取班级得分汇总
 func GetClassPointsSummary(c *gin.Context) {
-	summaries, err := models.GetClassPointsSummary()
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	summaries, err := models.GetClassPointsSummary(eventID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -58,7 +65,13 @@ func GetClassPointsSummary(c *gin.Context) {
 
 // GetStudentPointsSummary 获取学生得分汇总
 func GetStudentPointsSummary(c *gin.Context) {
-	summaries, err := models.GetStudentPointsSummary()
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	summaries, err := models.GetStudentPointsSummary(eventID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -75,7 +88,13 @@ func GetClassPointDetails(c *gin.Context) {
 		return
 	}
 
-	details, err := models.GetClassPointDetails(classID)
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	details, err := models.GetClassPointDetails(eventID, classID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -92,7 +111,13 @@ func GetStudentPointDetails(c *gin.Context) {
 		return
 	}
 
-	details, err := models.GetStudentPointDetails(studentID)
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	details, err := models.GetStudentPointDetails(eventID, studentID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -109,6 +134,9 @@ func DeleteCustomPoint(c *gin.Context) {
 		return
 	}
 
+	if point, err := models.GetPointByID(pointID); err == nil {
+		middlewares.SetAuditBefore(c, point)
+	}
 	if err := models.DeleteCustomPoint(pointID); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -125,7 +153,13 @@ func GetClassPointsSummaryByID(c *gin.Context) {
 		return
 	}
 
-	summary, err := models.GetClassPointsSummaryByID(classID)
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := models.GetClassPointsSummaryByID(eventID, classID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -142,7 +176,13 @@ func GetStudentPointsSummaryByID(c *gin.Context) {
 		return
 	}
 
-	summary, err := models.GetStudentPointsSummaryByID(studentID)
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := models.GetStudentPointsSummaryByID(eventID, studentID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
@@ -160,7 +200,7 @@ func GetMyPointsSummary(c *gin.Context) {
 		return
 	}
 
-	summary, err := models.GetStudentPointsSummaryByID(studentID)
+	summary, err := models.GetStudentPointsSummaryByID(config.Get().CurrentEventID, studentID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
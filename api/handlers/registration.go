@@ -29,9 +29,10 @@ type AdminUnregisterRequest struct {
 
 // RegistrationResponse 报名响应
 type RegistrationResponse struct {
-	Message     string   `json:"message"`
-	Exceeding   bool     `json:"exceeding"`
-	Registrants []string `json:"registrants"`
+	Message        string   `json:"message"`
+	Exceeding      bool     `json:"exceeding"`
+	Registrants    []string `json:"registrants"`
+	PendingConsent bool     `json:"pending_consent"` // 是否等待家长确认
 }
 
 // GetStudentRegistrations 获取学生的报名记录（学生端使用）
@@ -63,13 +64,19 @@ func GetCompetitionRegistrationsForPublic(c *gin.Context) {
 		return
 	}
 
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
 	// 游客仅能查看状态为"已批准"的比赛报名列表
-	competition, err := models.GetCompetitionByID(id)
+	competition, err := models.GetEventCompetitionByID(eventID, id)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取比赛状态失败")
 		return
 	}
-	if competition.Status != types.StatusApproved && competition.Status != types.StatusCompleted && competition.Status != types.StatusPendingScoreReview {
+	if utils.IsProposalStatus(competition.Status) {
 		utils.ResponseError(c, http.StatusForbidden, "仅允许查看已批准的比赛报名列表")
 		return
 	}
@@ -117,11 +124,22 @@ func RegisterForCompetitionForStudent(c *gin.Context) {
 	}
 
 	// 报名比赛（学生报名，传入nil表示非管理员）
-	if err := models.RegisterForCompetitionForStudent(&studentID, nil, req.CompetitionID, nil); err != nil {
+	consent, err := models.RegisterForCompetitionForStudent(&studentID, nil, req.CompetitionID, nil)
+	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "报名失败: "+err.Error())
 		return
 	}
 
+	// 需要家长确认的比赛，通知家长后直接返回
+	if consent != nil {
+		notifyGuardiansAsync(consent.ID)
+		utils.ResponseOK(c, RegistrationResponse{
+			Message:        "已提交报名，等待家长确认",
+			PendingConsent: true,
+		})
+		return
+	}
+
 	// 检查班级报名人数是否超限
 	exceeding := false
 	var registrants []string
@@ -196,15 +214,20 @@ func GetCompetitionRegistrations(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+	user, err := middlewares.GetCurrentUser(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
 	}
 
-	user, err := models.GetUserByID(userID)
+	// 裁判账号仅能查看被指派的比赛报名名单
+	allowed, err := models.CanOfficiateCompetition(user, id, types.OfficialRoleScorer, types.OfficialRoleReviewer, types.OfficialRoleStarter)
 	if err != nil {
-		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
+		utils.ResponseError(c, http.StatusInternalServerError, "获取裁判指派失败")
+		return
+	}
+	if !allowed {
+		utils.ResponseError(c, http.StatusForbidden, models.ErrNotCompetitionOfficial.Error())
 		return
 	}
 
@@ -238,13 +261,7 @@ func RegisterForCompetitionForAdmin(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -266,11 +283,18 @@ func RegisterForCompetitionForAdmin(c *gin.Context) {
 	}
 
 	// 执行报名（传入user对象，只有全局管理员可以跳过时间和数量限制）
-	if err := models.RegisterForCompetitionForStudent(&req.StudentID, nil, req.CompetitionID, user); err != nil {
+	consent, err := models.RegisterForCompetitionForStudent(&req.StudentID, nil, req.CompetitionID, user)
+	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "报名失败: "+err.Error())
 		return
 	}
 
+	if consent != nil {
+		notifyGuardiansAsync(consent.ID)
+		utils.ResponseSuccessWithCustomMessage(c, "已提交报名，等待家长确认")
+		return
+	}
+
 	utils.ResponseSuccessWithCustomMessage(c, "报名成功")
 }
 
@@ -293,13 +317,7 @@ func UnregisterFromCompetitionForAdmin(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -332,13 +350,7 @@ func UnregisterFromCompetitionForAdmin(c *gin.Context) {
 // GetCompetitionChecklist 获取比赛报名检查清单
 func GetCompetitionChecklist(c *gin.Context) {
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
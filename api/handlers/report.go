@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ClassLineageMemberRequest 班级沿革成员
+type ClassLineageMemberRequest struct {
+	EventID int `json:"event_id" binding:"required"`
+	ClassID int `json:"class_id" binding:"required"`
+}
+
+// ClassLineageRequest 创建或更新班级沿革请求
+type ClassLineageRequest struct {
+	Name        string                      `json:"name" binding:"required"`
+	Description string                      `json:"description"`
+	Members     []ClassLineageMemberRequest `json:"members"` // 各届次对应的班级
+}
+
+// toClassLineage 将请求转换为班级沿革模型
+func (req *ClassLineageRequest) toClassLineage(id int) *types.ClassLineage {
+	lineage := &types.ClassLineage{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Members:     make([]*types.ClassLineageMember, 0, len(req.Members)),
+	}
+	for _, member := range req.Members {
+		lineage.Members = append(lineage.Members, &types.ClassLineageMember{
+			EventID: member.EventID,
+			ClassID: member.ClassID,
+		})
+	}
+	return lineage
+}
+
+// GetAllClassLineages 获取所有班级沿革
+func GetAllClassLineages(c *gin.Context) {
+	lineages, err := models.GetAllClassLineages()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取班级沿革失败")
+		return
+	}
+
+	utils.ResponseOK(c, lineages)
+}
+
+// CreateClassLineage 创建班级沿革
+func CreateClassLineage(c *gin.Context) {
+	var req ClassLineageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	lineage := req.toClassLineage(0)
+	if err := models.CreateClassLineage(lineage); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "创建班级沿革失败: "+err.Error())
+		return
+	}
+
+	created, err := models.GetClassLineageByID(lineage.ID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取班级沿革失败")
+		return
+	}
+
+	utils.ResponseOK(c, created)
+}
+
+// UpdateClassLineage 更新班级沿革
+func UpdateClassLineage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的班级沿革ID")
+		return
+	}
+
+	var req ClassLineageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	if err := models.UpdateClassLineage(req.toClassLineage(id)); err != nil {
+		if errors.Is(err, models.ErrClassLineageNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusBadRequest, "更新班级沿革失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "更新成功")
+}
+
+// DeleteClassLineage 删除班级沿革
+func DeleteClassLineage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的班级沿革ID")
+		return
+	}
+
+	if err := models.DeleteClassLineage(id); err != nil {
+		if errors.Is(err, models.ErrClassLineageNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "删除班级沿革失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "删除成功")
+}
+
+// parseReportEventIDs 解析报表的 event_ids 查询参数（逗号分隔），为空时对比所有届次
+func parseReportEventIDs(c *gin.Context) ([]int, bool) {
+	var eventIDs []int
+	for _, s := range splitAndTrim(c.Query("event_ids"), ",") {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "无效的运动会ID: "+s)
+			return nil, false
+		}
+		eventIDs = append(eventIDs, id)
+	}
+	return eventIDs, true
+}
+
+// GetClassLineageReports 获取班级沿革跨届次对比报表（参赛人数、第一名次数、得分与排名）
+func GetClassLineageReports(c *gin.Context) {
+	eventIDs, ok := parseReportEventIDs(c)
+	if !ok {
+		return
+	}
+
+	lineageID := 0
+	if s := c.Query("lineage_id"); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "无效的班级沿革ID")
+			return
+		}
+		lineageID = id
+	}
+
+	reports, err := models.GetClassLineageReports(eventIDs, lineageID)
+	if err != nil {
+		if errors.Is(err, models.ErrClassLineageNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusBadRequest, "获取对比报表失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, reports)
+}
+
+// GetCompetitionReports 获取同名比赛项目跨届次对比报表（报名人数、第一名成绩）
+func GetCompetitionReports(c *gin.Context) {
+	eventIDs, ok := parseReportEventIDs(c)
+	if !ok {
+		return
+	}
+
+	reports, err := models.GetCompetitionReports(eventIDs, c.Query("name"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "获取对比报表失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, reports)
+}
+
+// GetVoteAnalytics 获取推荐项目的投票分布（按班级、年级、性别）与各班级投票参与率（默认当前届次）
+func GetVoteAnalytics(c *gin.Context) {
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := models.GetVoteAnalytics(eventID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取投票分析失败")
+		return
+	}
+
+	utils.ResponseOK(c, analytics)
+}
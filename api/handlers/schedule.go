@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/services"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateScheduleEntryRequest 调整日程条目请求
+type UpdateScheduleEntryRequest struct {
+	VenueID   *int      `json:"venue_id"`
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+}
+
+// PublishScheduleRequest 发布日程请求
+type PublishScheduleRequest struct {
+	RestMinutes int  `json:"rest_minutes"` // 检查冲突时使用的最短休息时间（分钟）
+	Force       bool `json:"force"`        // 存在休息时间不足等冲突时仍然发布（场地冲突始终不允许发布）
+}
+
+// GenerateSchedule 自动生成日程草稿
+func GenerateSchedule(c *gin.Context) {
+	var req types.ScheduleOptions
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	draft, err := services.GenerateSchedule(req)
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "生成日程失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, draft)
+}
+
+// GetScheduleDraft 获取日程草稿
+func GetScheduleDraft(c *gin.Context) {
+	restMinutes, _ := strconv.Atoi(c.DefaultQuery("rest_minutes", "0"))
+
+	draft, err := services.GetScheduleDraft(restMinutes)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取日程草稿失败")
+		return
+	}
+
+	utils.ResponseOK(c, draft)
+}
+
+// UpdateScheduleEntry 手动调整日程草稿条目
+func UpdateScheduleEntry(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的日程条目ID")
+		return
+	}
+
+	var req UpdateScheduleEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	if err := models.UpdateScheduleEntry(id, req.VenueID, req.StartTime, req.EndTime); err != nil {
+		if errors.Is(err, models.ErrScheduleEntryNotFound) || errors.Is(err, utils.ErrVenueNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusBadRequest, "调整日程失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "调整成功")
+}
+
+// DeleteScheduleDraft 放弃日程草稿
+func DeleteScheduleDraft(c *gin.Context) {
+	if err := models.DeleteScheduleDraft(); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "删除日程草稿失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "日程草稿已删除")
+}
+
+// PublishSchedule 发布日程草稿，写入各比赛项目的开始和结束时间
+func PublishSchedule(c *gin.Context) {
+	var req PublishScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	// 发布前检查手动调整后是否存在冲突，场地开放时间与占用情况在发布时另行校验，不能强制忽略
+	if !req.Force {
+		draft, err := services.GetScheduleDraft(req.RestMinutes)
+		if err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "获取日程草稿失败")
+			return
+		}
+		if len(draft.Conflicts) > 0 {
+			utils.ResponseError(c, http.StatusBadRequest, "日程存在 "+strconv.Itoa(len(draft.Conflicts))+" 处冲突，请调整后再发布")
+			return
+		}
+	}
+
+	count, err := models.PublishScheduleDraft()
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "发布日程失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "日程已发布，共更新 "+strconv.Itoa(count)+" 个比赛项目")
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
@@ -31,8 +33,14 @@ func GetPublicCompetitionScores(c *gin.Context) {
 		return
 	}
 
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
 	// 检查比赛是否存在
-	competition, err := models.GetCompetitionByID(id)
+	competition, err := models.GetEventCompetitionByID(eventID, id)
 	if err != nil {
 		utils.ResponseError(c, http.StatusNotFound, "比赛不存在")
 		return
@@ -44,7 +52,7 @@ func GetPublicCompetitionScores(c *gin.Context) {
 	}
 
 	// 获取成绩列表
-	scores, err := models.GetScoresByCompetitionID(id)
+	scores, err := models.GetScoresByCompetitionID(id, nil)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取成绩列表失败")
 		return
@@ -63,8 +71,19 @@ func GetCompetitionScores(c *gin.Context) {
 		return
 	}
 
+	// 裁判账号仅能查看被指派的比赛成绩
+	if !requireCompetitionOfficial(c, id, types.OfficialRoleScorer, types.OfficialRoleReviewer, types.OfficialRoleStarter) {
+		return
+	}
+
+	// 计算scope（限定班级的用户只能处理所管理班级的成绩）
+	scopeClassIDs, ok := getScoreClassScope(c)
+	if !ok {
+		return
+	}
+
 	// 获取成绩信息
-	score, err := models.GetScoresByCompetitionID(id)
+	score, err := models.GetScoresByCompetitionID(id, scopeClassIDs)
 	if err != nil {
 		utils.ResponseError(c, http.StatusNotFound, "成绩记录不存在")
 		return
@@ -84,7 +103,7 @@ func GetStudentScores(c *gin.Context) {
 	}
 
 	// 获取学生成绩
-	scores, err := models.GetScoresByStudentID(studentID)
+	scores, err := models.GetScoresByStudentID(config.Get().CurrentEventID, studentID)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取成绩失败")
 		return
@@ -103,8 +122,14 @@ func GetStudentScoresById(c *gin.Context) {
 		return
 	}
 
+	// 解析运动会届次（默认为当前届次）
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
 	// 获取学生成绩
-	scores, err := models.GetScoresByStudentID(id)
+	scores, err := models.GetScoresByStudentID(eventID, id)
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "获取成绩失败")
 		return
@@ -129,8 +154,23 @@ func CreateOrUpdateScores(c *gin.Context) {
 		return
 	}
 
+	// 裁判账号仅能录入担任记分员的比赛成绩
+	if !requireCompetitionOfficial(c, req.CompetitionID, types.OfficialRoleScorer) {
+		return
+	}
+
+	// 计算scope（限定班级的用户只能处理所管理班级的成绩）
+	scopeClassIDs, ok := getScoreClassScope(c)
+	if !ok {
+		return
+	}
+
 	// 创建或更新成绩
-	err := models.CreateOrUpdateScores(req.CompetitionID, req.StudentScores, submitterID)
+	err := models.CreateOrUpdateScores(req.CompetitionID, req.StudentScores, submitterID, scopeClassIDs)
+	if errors.Is(err, models.ErrScoreOutOfClassScope) {
+		utils.ResponseError(c, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "提交成绩失败: "+err.Error())
 		return
@@ -156,6 +196,21 @@ func ReviewScores(c *gin.Context) {
 		return
 	}
 
+	// 裁判账号仅能审核担任审核员的比赛成绩
+	if !requireCompetitionOfficial(c, req.CompetitionID, types.OfficialRoleReviewer) {
+		return
+	}
+
+	// 审核会完成整个比赛，仅全局用户可以审核
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+	if !models.IsGlobalAdmin(user) {
+		utils.ResponseError(c, http.StatusForbidden, "限定班级的用户不能审核成绩")
+		return
+	}
+
 	// 审核成绩
 	if err := models.ReviewCompetitionScoresByID(req.CompetitionID, reviewerID); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "审核成绩失败: "+err.Error())
@@ -175,8 +230,29 @@ func DeleteScores(c *gin.Context) {
 		return
 	}
 
+	userID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	// 裁判账号仅能删除担任记分员的比赛成绩
+	if !requireCompetitionOfficial(c, id, types.OfficialRoleScorer) {
+		return
+	}
+
+	// 计算scope（限定班级的用户只能处理所管理班级的成绩）
+	scopeClassIDs, ok := getScoreClassScope(c)
+	if !ok {
+		return
+	}
+
 	// 删除成绩记录
-	if err := models.DeleteCompetitionScoresByID(id); err != nil {
+	if err := models.DeleteCompetitionScoresByID(id, userID, scopeClassIDs); err != nil {
+		if errors.Is(err, models.ErrScoreOutOfClassScope) {
+			utils.ResponseError(c, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.ResponseError(c, http.StatusInternalServerError, "删除成绩记录失败: "+err.Error())
 		return
 	}
@@ -184,3 +260,22 @@ func DeleteScores(c *gin.Context) {
 	// 返回响应
 	utils.ResponseSuccessWithCustomMessage(c, "删除成功")
 }
+
+// getScoreClassScope 获取当前用户处理成绩的班级scope，全局用户返回 nil（不限制）
+// 限定班级的用户仅在启用班级成绩模式时可以处理所管理班级的成绩
+func getScoreClassScope(c *gin.Context) (*[]int, bool) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return nil, false
+	}
+	if models.IsGlobalAdmin(user) {
+		return nil, true
+	}
+	if !config.Get().ScoreEntry.ClassScoped {
+		utils.ResponseError(c, http.StatusForbidden, "未启用班级成绩模式，限定班级的用户不能处理成绩")
+		return nil, false
+	}
+
+	ids := models.GetClassScopeIDs(user)
+	return &ids, true
+}
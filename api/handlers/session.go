@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// getSessionOwner 获取当前请求的用户ID、会话所属用户类型和会话ID
+func getSessionOwner(c *gin.Context) (int, string, int, bool) {
+	userID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return 0, "", 0, false
+	}
+	role, ok := middlewares.GetRoleFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return 0, "", 0, false
+	}
+	sessionID, _ := middlewares.GetSessionIDFromContext(c)
+	return userID, string(role), sessionID, true
+}
+
+// GetMySessions 获取当前用户的登录会话列表
+func GetMySessions(c *gin.Context) {
+	userID, role, sessionID, ok := getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := models.GetActiveSessions(userID, role)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取登录会话失败")
+		return
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == sessionID
+	}
+
+	utils.ResponseOK(c, sessions)
+}
+
+// RevokeMySession 撤销当前用户的指定登录会话（如丢失的设备）
+func RevokeMySession(c *gin.Context) {
+	userID, role, _, ok := getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的会话ID")
+		return
+	}
+
+	if err := models.RevokeSession(id, userID, role); err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "撤销登录会话失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已退出该设备")
+}
+
+// Logout 退出登录（撤销当前会话）
+func Logout(c *gin.Context) {
+	userID, role, sessionID, ok := getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := models.RevokeSession(sessionID, userID, role); err != nil && !errors.Is(err, models.ErrSessionNotFound) {
+		utils.ResponseError(c, http.StatusInternalServerError, "退出登录失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已退出登录")
+}
+
+// LogoutAll 退出所有设备（撤销当前用户的全部会话）
+func LogoutAll(c *gin.Context) {
+	userID, role, _, ok := getSessionOwner(c)
+	if !ok {
+		return
+	}
+
+	if _, err := models.RevokeUserSessions(userID, role); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "退出登录失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已退出所有设备")
+}
+
+// GetUserSessions 获取指定管理员用户的登录会话
+func GetUserSessions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	if _, err := models.GetUserByID(id); err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "用户不存在")
+		return
+	}
+
+	sessions, err := models.GetActiveSessions(id, types.SessionRoleAdmin)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取登录会话失败")
+		return
+	}
+
+	utils.ResponseOK(c, sessions)
+}
+
+// RevokeUserSessions 撤销指定管理员用户的全部登录会话
+func RevokeUserSessions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := models.GetUserByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "用户不存在")
+		return
+	}
+
+	// 不能强制权限更高的用户下线
+	if utils.HasMorePermissions(user.Permission, currentUser.Permission) {
+		utils.ResponseError(c, http.StatusForbidden, utils.ErrCannotModifyHigherPermissionUser.Error())
+		return
+	}
+
+	count, err := models.RevokeUserSessions(id, types.SessionRoleAdmin)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "撤销登录会话失败")
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{"revoked": count})
+}
+
+// getScopedStudent 获取当前管理员班级权限范围内的学生，失败时直接返回错误响应
+func getScopedStudent(c *gin.Context) (*types.Student, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的学生ID")
+		return nil, false
+	}
+
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return nil, false
+	}
+
+	student, err := models.GetStudentByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "学生不存在")
+		return nil, false
+	}
+
+	if !models.HasClassScope(user, student.ClassID) {
+		utils.ResponseError(c, http.StatusForbidden, "权限不足")
+		return nil, false
+	}
+
+	return student, true
+}
+
+// GetStudentSessions 获取指定学生的登录会话
+func GetStudentSessions(c *gin.Context) {
+	student, ok := getScopedStudent(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := models.GetActiveSessions(student.ID, types.SessionRoleStudent)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取登录会话失败")
+		return
+	}
+
+	utils.ResponseOK(c, sessions)
+}
+
+// RevokeStudentSessions 撤销指定学生的全部登录会话
+func RevokeStudentSessions(c *gin.Context) {
+	student, ok := getScopedStudent(c)
+	if !ok {
+		return
+	}
+
+	count, err := models.RevokeUserSessions(student.ID, types.SessionRoleStudent)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "撤销登录会话失败")
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{"revoked": count})
+}
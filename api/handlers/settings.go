@@ -3,8 +3,10 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
 	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -17,6 +19,18 @@ type UpdateSettingsRequest struct {
 		AgentID   string `json:"agent_id"`
 		CorpID    string `json:"corp_id"`
 	} `json:"dingtalk"`
+	OIDC *struct {
+		Enabled         bool     `json:"enabled"`
+		DisplayName     string   `json:"display_name"`
+		Issuer          string   `json:"issuer"`
+		ClientID        string   `json:"client_id"`
+		ClientSecret    string   `json:"client_secret"`
+		RedirectURL     string   `json:"redirect_url"`
+		Scopes          []string `json:"scopes"`
+		MatchBy         string   `json:"match_by"`
+		UsernameClaim   string   `json:"username_claim"`
+		ExternalIDClaim string   `json:"external_id_claim"`
+	} `json:"oidc"` // 统一身份认证设置，未提供时保持不变
 	Website struct {
 		Name           string `json:"name"`
 		ICPBeian       string `json:"icp_beian"`
@@ -32,28 +46,80 @@ type UpdateSettingsRequest struct {
 		RegistrationEndTime       string `json:"registration_end_time"`
 		MaxRegistrationsPerPerson int    `json:"max_registrations_per_person"`
 	} `json:"competition"`
+	Voting    *types.VotingRules `json:"voting"` // 投票规则，未提供时保持不变
 	Dashboard struct {
 		Enabled *bool `json:"enabled"`
 	} `json:"dashboard"`
+	ScoreEntry struct {
+		ClassScoped *bool `json:"class_scoped"`
+	} `json:"score_entry"`
+	Consent struct {
+		ExpireHours           *int `json:"expire_hours"`
+		ReminderIntervalHours *int `json:"reminder_interval_hours"`
+		MaxReminders          *int `json:"max_reminders"`
+	} `json:"consent"`
 	Scoring struct {
 		TeamPointsMapping       map[string]float64 `json:"team_points_mapping"`
 		IndividualPointsMapping map[string]float64 `json:"individual_points_mapping"`
 	} `json:"scoring"`
+	Security struct {
+		TwoFactorRequiredPermissions *int `json:"two_factor_required_permissions"`
+	} `json:"security"`
+}
+
+// maskedSecret 设置中已保存的密钥不返回原文，以此代替；更新时提交该值表示保持不变
+const maskedSecret = "******"
+
+// maskSecret 隐藏已保存的密钥，未设置时返回空字符串
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return maskedSecret
 }
 
 // GetSettings 获取系统设置
 func GetSettings(c *gin.Context) {
+	settings, err := buildSettings()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取运动会届次设置失败")
+		return
+	}
+
+	// 返回响应
+	utils.ResponseOK(c, settings)
+}
+
+// buildSettings 构建系统设置（配置文件与当前届次设置）
+func buildSettings() (map[string]interface{}, error) {
 	// 获取配置
 	cfg := config.Get()
 
-	// 构建响应
-	settings := map[string]interface{}{
+	// 时间安排、报名上限、投票规则与得分映射按运动会届次保存，这里返回当前届次的设置
+	eventSettings, err := models.GetEventSettings(cfg.CurrentEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
 		"dingtalk": map[string]interface{}{
 			"app_key":    cfg.DingTalk.AppKey,
-			"app_secret": cfg.DingTalk.AppSecret,
+			"app_secret": maskSecret(cfg.DingTalk.AppSecret),
 			"agent_id":   cfg.DingTalk.AgentID,
 			"corp_id":    cfg.DingTalk.CorpID,
 		},
+		"oidc": map[string]interface{}{
+			"enabled":           cfg.OIDC.Enabled,
+			"display_name":      cfg.OIDC.DisplayName,
+			"issuer":            cfg.OIDC.Issuer,
+			"client_id":         cfg.OIDC.ClientID,
+			"client_secret":     maskSecret(cfg.OIDC.ClientSecret),
+			"redirect_url":      cfg.OIDC.RedirectURL,
+			"scopes":            cfg.OIDC.Scopes,
+			"match_by":          cfg.OIDC.MatchBy,
+			"username_claim":    cfg.OIDC.UsernameClaim,
+			"external_id_claim": cfg.OIDC.ExternalIDClaim,
+		},
 		"website": map[string]interface{}{
 			"name":             cfg.Website.Name,
 			"icp_beian":        cfg.Website.ICPBeian,
@@ -61,25 +127,34 @@ func GetSettings(c *gin.Context) {
 			"domain":           cfg.Website.Domain,
 		},
 		"competition": map[string]interface{}{
-			"submission_start_time":        cfg.Competition.SubmissionStartTime,
-			"submission_end_time":          cfg.Competition.SubmissionEndTime,
-			"voting_start_time":            cfg.Competition.VotingStartTime,
-			"voting_end_time":              cfg.Competition.VotingEndTime,
-			"registration_start_time":      cfg.Competition.RegistrationStartTime,
-			"registration_end_time":        cfg.Competition.RegistrationEndTime,
-			"max_registrations_per_person": cfg.Competition.MaxRegistrationsPerPerson,
+			"submission_start_time":        eventSettings.SubmissionStartTime,
+			"submission_end_time":          eventSettings.SubmissionEndTime,
+			"voting_start_time":            eventSettings.VotingStartTime,
+			"voting_end_time":              eventSettings.VotingEndTime,
+			"registration_start_time":      eventSettings.RegistrationStartTime,
+			"registration_end_time":        eventSettings.RegistrationEndTime,
+			"max_registrations_per_person": eventSettings.MaxRegistrationsPerPerson,
 		},
+		"voting": eventSettings.VotingRules,
 		"dashboard": map[string]interface{}{
 			"enabled": cfg.Dashboard.Enabled,
 		},
+		"score_entry": map[string]interface{}{
+			"class_scoped": cfg.ScoreEntry.ClassScoped,
+		},
+		"consent": map[string]interface{}{
+			"expire_hours":            cfg.Consent.ExpireHours,
+			"reminder_interval_hours": cfg.Consent.ReminderIntervalHours,
+			"max_reminders":           cfg.Consent.MaxReminders,
+		},
 		"scoring": map[string]interface{}{
-			"team_points_mapping":       cfg.Scoring.TeamPointsMapping,
-			"individual_points_mapping": cfg.Scoring.IndividualPointsMapping,
+			"team_points_mapping":       eventSettings.TeamPointsMapping,
+			"individual_points_mapping": eventSettings.IndividualPointsMapping,
 		},
-	}
-
-	// 返回响应
-	utils.ResponseOK(c, settings)
+		"security": map[string]interface{}{
+			"two_factor_required_permissions": cfg.Security.TwoFactorRequiredPermissions,
+		},
+	}, nil
 }
 
 // UpdateSettings 更新系统设置
@@ -91,34 +166,102 @@ func UpdateSettings(c *gin.Context) {
 		return
 	}
 
+	// 记录修改前的设置
+	if before, err := buildSettings(); err == nil {
+		middlewares.SetAuditBefore(c, before)
+	}
+
 	// 获取配置
 	cfg := config.Get()
 
+	// 未提供投票规则时沿用当前届次已保存的规则
+	votingRules := req.Voting
+	if votingRules == nil {
+		current, err := models.GetEventSettings(cfg.CurrentEventID)
+		if err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "获取运动会届次设置失败")
+			return
+		}
+		votingRules = &current.VotingRules
+	} else if err := utils.ValidateVotingRules(votingRules); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 校验统一身份认证设置
+	if req.OIDC != nil {
+		if req.OIDC.MatchBy != utils.OIDCMatchByUsername && req.OIDC.MatchBy != utils.OIDCMatchByExternalID {
+			utils.ResponseError(c, http.StatusBadRequest, "账号匹配方式只能为 username 或 external_id")
+			return
+		}
+		// 管理员始终按外部ID匹配，按用户名匹配仅适用于学生
+		if req.OIDC.ExternalIDClaim == "" || (req.OIDC.MatchBy == utils.OIDCMatchByUsername && req.OIDC.UsernameClaim == "") {
+			utils.ResponseError(c, http.StatusBadRequest, "需填写外部ID声明，按用户名匹配学生时还需填写用户名声明")
+			return
+		}
+		if req.OIDC.Enabled && (req.OIDC.Issuer == "" || req.OIDC.ClientID == "" || req.OIDC.RedirectURL == "") {
+			utils.ResponseError(c, http.StatusBadRequest, "启用统一身份认证需填写身份提供方地址、客户端ID与回调地址")
+			return
+		}
+	}
+
+	// 校验须启用两步验证的权限
+	if req.Security.TwoFactorRequiredPermissions != nil {
+		required := *req.Security.TwoFactorRequiredPermissions
+		if required < 0 || required&^utils.GetAllPermissions() != 0 {
+			utils.ResponseError(c, http.StatusBadRequest, "无效的权限")
+			return
+		}
+	}
+
 	// 更新配置
 	cfg.DingTalk.AppKey = req.DingTalk.AppKey
-	cfg.DingTalk.AppSecret = req.DingTalk.AppSecret
+	if req.DingTalk.AppSecret != maskedSecret {
+		cfg.DingTalk.AppSecret = req.DingTalk.AppSecret
+	}
 	cfg.DingTalk.AgentID = req.DingTalk.AgentID
 	cfg.DingTalk.CorpID = req.DingTalk.CorpID
 
+	if req.OIDC != nil {
+		cfg.OIDC.Enabled = req.OIDC.Enabled
+		cfg.OIDC.DisplayName = req.OIDC.DisplayName
+		cfg.OIDC.Issuer = req.OIDC.Issuer
+		cfg.OIDC.ClientID = req.OIDC.ClientID
+		if req.OIDC.ClientSecret != maskedSecret {
+			cfg.OIDC.ClientSecret = req.OIDC.ClientSecret
+		}
+		cfg.OIDC.RedirectURL = req.OIDC.RedirectURL
+		cfg.OIDC.Scopes = req.OIDC.Scopes
+		cfg.OIDC.MatchBy = req.OIDC.MatchBy
+		cfg.OIDC.UsernameClaim = req.OIDC.UsernameClaim
+		cfg.OIDC.ExternalIDClaim = req.OIDC.ExternalIDClaim
+	}
+
 	cfg.Website.Name = req.Website.Name
 	cfg.Website.ICPBeian = req.Website.ICPBeian
 	cfg.Website.PublicSecBeian = req.Website.PublicSecBeian
 	cfg.Website.Domain = req.Website.Domain
 
-	cfg.Competition.SubmissionStartTime = req.Competition.SubmissionStartTime
-	cfg.Competition.SubmissionEndTime = req.Competition.SubmissionEndTime
-	cfg.Competition.VotingStartTime = req.Competition.VotingStartTime
-	cfg.Competition.VotingEndTime = req.Competition.VotingEndTime
-	cfg.Competition.RegistrationStartTime = req.Competition.RegistrationStartTime
-	cfg.Competition.RegistrationEndTime = req.Competition.RegistrationEndTime
-	cfg.Competition.MaxRegistrationsPerPerson = req.Competition.MaxRegistrationsPerPerson
-
 	if req.Dashboard.Enabled != nil {
 		cfg.Dashboard.Enabled = *req.Dashboard.Enabled
 	}
+	if req.ScoreEntry.ClassScoped != nil {
+		cfg.ScoreEntry.ClassScoped = *req.ScoreEntry.ClassScoped
+	}
+
+	if req.Consent.ExpireHours != nil && *req.Consent.ExpireHours > 0 {
+		cfg.Consent.ExpireHours = *req.Consent.ExpireHours
+	}
+	if req.Consent.ReminderIntervalHours != nil && *req.Consent.ReminderIntervalHours > 0 {
+		cfg.Consent.ReminderIntervalHours = *req.Consent.ReminderIntervalHours
+	}
+	if req.Consent.MaxReminders != nil && *req.Consent.MaxReminders >= 0 {
+		cfg.Consent.MaxReminders = *req.Consent.MaxReminders
+	}
 
-	cfg.Scoring.TeamPointsMapping = req.Scoring.TeamPointsMapping
-	cfg.Scoring.IndividualPointsMapping = req.Scoring.IndividualPointsMapping
+	if req.Security.TwoFactorRequiredPermissions != nil {
+		cfg.Security.TwoFactorRequiredPermissions = *req.Security.TwoFactorRequiredPermissions
+	}
 
 	// 保存配置
 	if err := config.Save(); err != nil {
@@ -126,19 +269,25 @@ func UpdateSettings(c *gin.Context) {
 		return
 	}
 
-	// 重新计算所有有分数的比赛的分数
-	completedCompetitions, _, err := models.GetAllCompetitions(0, 0, nil, 0, "")
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "获取已完成比赛列表失败")
+	// 时间安排、报名上限、投票规则与得分映射保存到当前届次，并仅重新计算当前届次的得分
+	eventSettings := &types.EventSettings{
+		SubmissionStartTime:       req.Competition.SubmissionStartTime,
+		SubmissionEndTime:         req.Competition.SubmissionEndTime,
+		VotingStartTime:           req.Competition.VotingStartTime,
+		VotingEndTime:             req.Competition.VotingEndTime,
+		RegistrationStartTime:     req.Competition.RegistrationStartTime,
+		RegistrationEndTime:       req.Competition.RegistrationEndTime,
+		MaxRegistrationsPerPerson: req.Competition.MaxRegistrationsPerPerson,
+		VotingRules:               *votingRules,
+		TeamPointsMapping:         req.Scoring.TeamPointsMapping,
+		IndividualPointsMapping:   req.Scoring.IndividualPointsMapping,
+	}
+	if err := models.UpdateEventSettings(cfg.CurrentEventID, eventSettings); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "保存运动会届次设置失败: "+err.Error())
 		return
 	}
-
-	// 重新计算每个比赛的分数
-	for _, comp := range completedCompetitions {
-		if err := models.RecalculatePointsByCompetitionID(comp.ID); err != nil {
-			utils.ResponseError(c, http.StatusInternalServerError, "重新计算分数失败: "+err.Error())
-			return
-		}
+	if after, err := buildSettings(); err == nil {
+		middlewares.SetAuditAfter(c, after)
 	}
 
 	// 返回响应
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ConfirmShortlistRequest 确认入围报告请求
+type ConfirmShortlistRequest struct {
+	Decisions map[int]types.ShortlistDecision `json:"decisions"` // 可选，按比赛项目ID覆盖入围结果
+}
+
+// GetShortlistReports 获取入围报告列表（默认当前届次）
+func GetShortlistReports(c *gin.Context) {
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	reports, err := models.GetShortlistReports(eventID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取入围报告失败")
+		return
+	}
+
+	utils.ResponseOK(c, reports)
+}
+
+// GetShortlistReport 获取入围报告详情
+func GetShortlistReport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的入围报告ID")
+		return
+	}
+
+	report, err := models.GetShortlistReportByID(id)
+	if err != nil {
+		if errors.Is(err, models.ErrShortlistNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "获取入围报告失败")
+		return
+	}
+
+	utils.ResponseOK(c, report)
+}
+
+// GenerateShortlistReport 根据投票结果重新生成入围报告（默认当前届次）
+func GenerateShortlistReport(c *gin.Context) {
+	eventID, ok := getRequestedEventID(c)
+	if !ok {
+		return
+	}
+
+	report, err := models.GenerateShortlistReport(eventID)
+	if err != nil {
+		if errors.Is(err, models.ErrShortlistModeNotSet) || errors.Is(err, utils.ErrVotingNotEnded) {
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusInternalServerError, "生成入围报告失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, report)
+}
+
+// ConfirmShortlistReport 确认入围报告，批量通过入围项目并拒绝未入围项目
+func ConfirmShortlistReport(c *gin.Context) {
+	userID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的入围报告ID")
+		return
+	}
+
+	// 请求体可选
+	var req ConfirmShortlistRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+			return
+		}
+	}
+
+	report, err := models.ConfirmShortlistReport(id, userID, req.Decisions)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrShortlistNotFound):
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrShortlistNotPending), errors.Is(err, models.ErrInvalidShortlistItem):
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "确认入围报告失败: "+err.Error())
+		}
+		return
+	}
+
+	utils.ResponseOK(c, report)
+}
+
+// DiscardShortlistReport 作废入围报告
+func DiscardShortlistReport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的入围报告ID")
+		return
+	}
+
+	if err := models.DiscardShortlistReport(id); err != nil {
+		switch {
+		case errors.Is(err, models.ErrShortlistNotFound):
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrShortlistNotPending):
+			utils.ResponseError(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.ResponseError(c, http.StatusInternalServerError, "作废入围报告失败")
+		}
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "入围报告已作废")
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // CreateStudentRequest 创建学生请求
@@ -17,6 +16,7 @@ type CreateStudentRequest struct {
 	ClassName  string `json:"class_name" binding:"required"`
 	Gender     int    `json:"gender" binding:"required,min=1,max=2"`
 	DingTalkID string `json:"dingtalk_id"`
+	ExternalID string `json:"external_id"`
 }
 
 // UpdateStudentRequest 更新学生请求
@@ -25,18 +25,13 @@ type UpdateStudentRequest struct {
 	ClassName  string `json:"class_name" binding:"required"`
 	Gender     int    `json:"gender" binding:"required,min=1,max=2"`
 	DingTalkID string `json:"dingtalk_id"`
+	ExternalID string `json:"external_id"`
 }
 
 // GetAllStudents 获取所有学生
 func GetAllStudents(c *gin.Context) {
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -92,13 +87,40 @@ func GetStudent(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+	user, err := middlewares.GetCurrentUser(c)
+	if err != nil {
+		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
+		return
+	}
+
+	// 获取学生信息
+	student, err := models.GetStudentByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "学生不存在")
+		return
+	}
+
+	// 权限验证：全局管理员或有该学生所在班级权限的用户可以查看
+	if !models.HasClassScope(user, student.ClassID) {
+		utils.ResponseError(c, http.StatusForbidden, "权限不足")
+		return
+	}
+
+	// 返回响应
+	utils.ResponseOK(c, student)
+}
+
+// GetStudentProfile 获取学生跨届次参赛档案（管理员）
+func GetStudentProfile(c *gin.Context) {
+	// 解析路径参数
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的学生ID")
 		return
 	}
 
-	user, err := models.GetUserByID(userID)
+	// 获取当前用户信息
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -117,8 +139,31 @@ func GetStudent(c *gin.Context) {
 		return
 	}
 
-	// 返回响应
-	utils.ResponseOK(c, student)
+	profile, err := models.GetStudentProfile(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取参赛档案失败")
+		return
+	}
+
+	utils.ResponseOK(c, profile)
+}
+
+// GetMyProfile 获取当前登录学生的跨届次参赛档案（学生端使用）
+func GetMyProfile(c *gin.Context) {
+	// 从上下文获取学生ID
+	studentID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	profile, err := models.GetStudentProfile(studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取参赛档案失败")
+		return
+	}
+
+	utils.ResponseOK(c, profile)
 }
 
 // CreateStudent 创建学生
@@ -131,13 +176,7 @@ func CreateStudent(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -166,6 +205,15 @@ func CreateStudent(c *gin.Context) {
 		return
 	}
 
+	// 设置外部身份ID
+	if req.ExternalID != "" {
+		student.ExternalID = req.ExternalID
+		if err := models.UpdateStudent(student); err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "设置外部身份ID失败")
+			return
+		}
+	}
+
 	// 返回响应
 	utils.ResponseOK(c, map[string]interface{}{
 		"student":  student,
@@ -190,13 +238,7 @@ func UpdateStudent(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -231,15 +273,18 @@ func UpdateStudent(c *gin.Context) {
 	}
 
 	// 更新学生信息
+	middlewares.SetAuditBefore(c, student)
 	student.FullName = req.FullName
 	student.ClassID = class
 	student.Gender = req.Gender
 	student.DingTalkID = req.DingTalkID
+	student.ExternalID = req.ExternalID
 
 	if err := models.UpdateStudent(student); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "更新学生失败: "+err.Error())
 		return
 	}
+	middlewares.SetAuditAfter(c, student)
 
 	// 返回响应
 	utils.ResponseSuccessWithCustomMessage(c, "更新成功")
@@ -255,13 +300,7 @@ func DeleteStudent(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -281,6 +320,7 @@ func DeleteStudent(c *gin.Context) {
 	}
 
 	// 删除学生
+	middlewares.SetAuditBefore(c, student)
 	if err := models.DeleteStudent(id); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除学生失败")
 		return
@@ -300,13 +340,7 @@ func ResetStudentPassword(c *gin.Context) {
 	}
 
 	// 获取当前用户信息
-	userID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	user, err := models.GetUserByID(userID)
+	user, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -331,14 +365,8 @@ func ResetStudentPassword(c *gin.Context) {
 		utils.ResponseError(c, http.StatusInternalServerError, "生成随机密码失败")
 		return
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
-	if err != nil {
-		utils.ResponseError(c, http.StatusInternalServerError, "生成密码哈希失败")
-		return
-	}
-	// 更新学生密码
-	student.Password = string(hashedPassword)
-	if err := models.UpdateStudent(student); err != nil {
+	// 更新学生密码，并撤销该学生已登录的会话
+	if err := models.UpdateStudentPassword(student.ID, randomPassword); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "重置密码失败")
 		return
 	}
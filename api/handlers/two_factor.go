@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorCodeRequest 提交两步验证验证码的请求
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // 动态验证码或恢复码
+}
+
+// DisableTwoFactorRequest 关闭两步验证请求
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 动态验证码或恢复码
+}
+
+// responseTwoFactorError 返回两步验证操作的错误响应
+func responseTwoFactorError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrTwoFactorCodeIncorrect),
+		errors.Is(err, models.ErrTwoFactorPasswordInvalid),
+		errors.Is(err, models.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, models.ErrTwoFactorNotSetup),
+		errors.Is(err, models.ErrTwoFactorNotEnabled):
+		utils.ResponseError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrTwoFactorRequired):
+		utils.ResponseError(c, http.StatusForbidden, err.Error())
+	default:
+		utils.ResponseError(c, http.StatusInternalServerError, message)
+	}
+}
+
+// GetTwoFactorStatus 获取当前用户的两步验证状态
+func GetTwoFactorStatus(c *gin.Context) {
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	remaining, err := models.CountRecoveryCodes(user.ID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取两步验证状态失败")
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"enabled":                  user.TwoFactorEnabled,
+		"required":                 models.IsTwoFactorRequired(user),
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// SetupTwoFactor 生成两步验证密钥，返回供验证器应用扫码添加的地址
+func SetupTwoFactor(c *gin.Context) {
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := models.SetupTwoFactor(currentUser.ID)
+	if err != nil {
+		responseTwoFactorError(c, err, "生成两步验证密钥失败")
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"secret":      user.TOTPSecret,
+		"otpauth_uri": utils.GetTOTPURI(config.Get().Website.Name, user.Username, user.TOTPSecret),
+	})
+}
+
+// EnableTwoFactor 使用验证码确认并启用两步验证，返回恢复码（仅显示一次）
+func EnableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := models.EnableTwoFactor(user.ID, req.Code)
+	if err != nil {
+		responseTwoFactorError(c, err, "启用两步验证失败")
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+func DisableTwoFactor(c *gin.Context) {
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := models.DisableTwoFactor(user.ID, req.Password, req.Code); err != nil {
+		responseTwoFactorError(c, err, "关闭两步验证失败")
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已关闭两步验证")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部作废
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	user, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := models.RegenerateRecoveryCodes(user.ID, req.Code)
+	if err != nil {
+		responseTwoFactorError(c, err, "生成恢复码失败")
+		return
+	}
+
+	utils.ResponseOK(c, map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// ResetUserTwoFactor 重置指定管理员用户的两步验证（用户丢失验证器且恢复码用尽时）
+// 重置后用户可使用账号密码登录并重新设置两步验证
+func ResetUserTwoFactor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	currentUser, ok := getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := models.GetUserByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, "用户不存在")
+		return
+	}
+
+	// 不能重置权限更高的用户
+	if utils.HasMorePermissions(user.Permission, currentUser.Permission) {
+		utils.ResponseError(c, http.StatusForbidden, utils.ErrCannotModifyHigherPermissionUser.Error())
+		return
+	}
+	middlewares.SetAuditBefore(c, user)
+
+	if err := models.ResetTwoFactor(id); err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "重置两步验证失败")
+		return
+	}
+	if user, err := models.GetUserByID(id); err == nil {
+		middlewares.SetAuditAfter(c, user)
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "已重置两步验证")
+}
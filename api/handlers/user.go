@@ -17,7 +17,10 @@ type CreateUserRequest struct {
 	FullName      string `json:"full_name" binding:"required"`
 	Permission    int    `json:"permission" binding:"required"`
 	DingTalkID    string `json:"dingtalk_id"`
+	ExternalID    string `json:"external_id"`
 	ClassScopeIDs []int  `json:"class_scope_ids" binding:"required"`
+	// 是否限定为只处理指派的比赛项目（裁判账号）
+	CompetitionScoped bool `json:"competition_scoped"`
 }
 
 // UpdateUserRequest 更新用户请求
@@ -26,7 +29,10 @@ type UpdateUserRequest struct {
 	Permission    int    `json:"permission" binding:"required"`
 	Password      string `json:"password,omitempty"`
 	DingTalkID    string `json:"dingtalk_id"`
+	ExternalID    string `json:"external_id"`
 	ClassScopeIDs []int  `json:"class_scope_ids" binding:"required"`
+	// 是否限定为只处理指派的比赛项目（裁判账号）
+	CompetitionScoped bool `json:"competition_scoped"`
 }
 
 // GetAllUsers 获取所有用户
@@ -87,20 +93,14 @@ func CreateUser(c *gin.Context) {
 	}
 
 	// 获取当前用户信息以检查权限
-	currentUserID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	currentUser, err := models.GetUserByID(currentUserID)
+	currentUser, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
 	}
 
 	// 验证用户创建操作
-	if err := utils.ValidateUserCreateOrUpdate(currentUser.Permission, req.Permission, 0, req.ClassScopeIDs); err != nil {
+	if err := utils.ValidateUserCreateOrUpdate(currentUser.Permission, req.Permission, 0, req.ClassScopeIDs, req.CompetitionScoped); err != nil {
 		utils.ResponseError(c, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -111,6 +111,16 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
+	// 设置是否为裁判账号及外部身份ID
+	if req.CompetitionScoped || req.ExternalID != "" {
+		user.CompetitionScoped = req.CompetitionScoped
+		user.ExternalID = req.ExternalID
+		if err := models.UpdateUser(user); err != nil {
+			utils.ResponseError(c, http.StatusInternalServerError, "更新用户失败")
+			return
+		}
+	}
+
 	// 更新班级权限
 	if err := models.UpdateUserClassScopes(user.ID, req.ClassScopeIDs); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "更新班级权限失败")
@@ -138,13 +148,7 @@ func UpdateUser(c *gin.Context) {
 	}
 
 	// 获取当前用户信息以检查权限
-	currentUserID, ok := middlewares.GetUserIDFromContext(c)
-	if !ok {
-		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
-		return
-	}
-
-	currentUser, err := models.GetUserByID(currentUserID)
+	currentUser, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -158,15 +162,11 @@ func UpdateUser(c *gin.Context) {
 	}
 
 	// 验证用户更新操作
-	if err := utils.ValidateUserCreateOrUpdate(currentUser.Permission, req.Permission, user.Permission, req.ClassScopeIDs); err != nil {
+	if err := utils.ValidateUserCreateOrUpdate(currentUser.Permission, req.Permission, user.Permission, req.ClassScopeIDs, req.CompetitionScoped); err != nil {
 		utils.ResponseError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-
-	// 更新用户信息
-	user.FullName = req.FullName
-	user.Permission = req.Permission
-	user.DingTalkID = req.DingTalkID
+	middlewares.SetAuditBefore(c, user)
 
 	// 更新班级权限
 	if err := models.UpdateUserClassScopes(user.ID, req.ClassScopeIDs); err != nil {
@@ -181,6 +181,13 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	// 更新用户信息（需在重新加载之后设置，否则会被覆盖）
+	user.FullName = req.FullName
+	user.Permission = req.Permission
+	user.DingTalkID = req.DingTalkID
+	user.ExternalID = req.ExternalID
+	user.CompetitionScoped = req.CompetitionScoped
+
 	// 如果提供了新密码，更新密码
 	if req.Password != "" {
 		if err := models.UpdatePassword(user.ID, req.Password); err != nil {
@@ -194,6 +201,7 @@ func UpdateUser(c *gin.Context) {
 		utils.ResponseError(c, http.StatusInternalServerError, "更新用户失败")
 		return
 	}
+	middlewares.SetAuditAfter(c, user)
 
 	// 返回响应
 	utils.ResponseSuccessWithCustomMessage(c, "更新成功")
@@ -221,7 +229,7 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
-	currentUser, err := models.GetUserByID(currentUserID)
+	currentUser, err := middlewares.GetCurrentUser(c)
 	if err != nil {
 		utils.ResponseError(c, http.StatusUnauthorized, "用户信息获取失败")
 		return
@@ -241,6 +249,7 @@ func DeleteUser(c *gin.Context) {
 	}
 
 	// 删除用户
+	middlewares.SetAuditBefore(c, user)
 	if err := models.DeleteUser(id); err != nil {
 		utils.ResponseError(c, http.StatusInternalServerError, "删除用户失败")
 		return
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// VenueRequest 创建或更新比赛场地请求
+type VenueRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Capacity    int    `json:"capacity" binding:"min=0"` // 同时容纳的参赛人数，0表示无限制
+	OpenTime    string `json:"open_time"`                // 每日开放时间，格式 HH:MM
+	CloseTime   string `json:"close_time"`               // 每日关闭时间，格式 HH:MM
+}
+
+// GetAllVenues 获取所有比赛场地
+func GetAllVenues(c *gin.Context) {
+	venues, err := models.GetAllVenues()
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, "获取场地列表失败")
+		return
+	}
+
+	utils.ResponseOK(c, venues)
+}
+
+// CreateVenue 创建比赛场地
+func CreateVenue(c *gin.Context) {
+	var req VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	venue := &types.Venue{
+		Name:        req.Name,
+		Description: req.Description,
+		Capacity:    req.Capacity,
+		OpenTime:    req.OpenTime,
+		CloseTime:   req.CloseTime,
+	}
+	if err := models.CreateVenue(venue); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "创建场地失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, venue)
+}
+
+// UpdateVenue 更新比赛场地
+func UpdateVenue(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的场地ID")
+		return
+	}
+
+	var req VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效请求")
+		return
+	}
+
+	venue, err := models.GetVenueByID(id)
+	if err != nil {
+		utils.ResponseError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	venue.Name = req.Name
+	venue.Description = req.Description
+	venue.Capacity = req.Capacity
+	venue.OpenTime = req.OpenTime
+	venue.CloseTime = req.CloseTime
+	if err := models.UpdateVenue(venue); err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "更新场地失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "更新成功")
+}
+
+// DeleteVenue 删除比赛场地
+func DeleteVenue(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, http.StatusBadRequest, "无效的场地ID")
+		return
+	}
+
+	if err := models.DeleteVenue(id); err != nil {
+		if errors.Is(err, utils.ErrVenueNotFound) {
+			utils.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ResponseError(c, http.StatusBadRequest, "删除场地失败: "+err.Error())
+		return
+	}
+
+	utils.ResponseSuccessWithCustomMessage(c, "删除成功")
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/api/middlewares"
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/database"
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
@@ -27,13 +29,6 @@ func VoteCompetition(c *gin.Context) {
 		return
 	}
 
-	// 检查是否允许投票（管理员不受时间限制）
-	isAdmin := middlewares.IsAdmin(c)
-	if !isAdmin && !utils.IsVotingAllowed() {
-		utils.ResponseError(c, http.StatusForbidden, utils.ErrVotingNotAllowed.Error())
-		return
-	}
-
 	// 解析请求体
 	var req VoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -41,6 +36,20 @@ func VoteCompetition(c *gin.Context) {
 		return
 	}
 
+	// 检查是否允许投票（按比赛所属届次的投票时间，管理员不受时间限制）
+	isAdmin := middlewares.IsAdmin(c)
+	if !isAdmin {
+		settings, err := models.GetEventSettingsByCompetitionID(req.CompetitionID)
+		if err != nil {
+			utils.ResponseError(c, http.StatusNotFound, "比赛项目不存在")
+			return
+		}
+		if !utils.IsVotingAllowed(settings) {
+			utils.ResponseError(c, http.StatusForbidden, utils.ErrVotingNotAllowed.Error())
+			return
+		}
+	}
+
 	// 验证投票类型
 	if req.VoteType != types.VoteTypeUp && req.VoteType != types.VoteTypeDown {
 		utils.ResponseError(c, http.StatusBadRequest, "无效的投票类型")
@@ -50,6 +59,10 @@ func VoteCompetition(c *gin.Context) {
 	// 执行投票
 	db := database.GetDB()
 	if err := models.VoteCompetition(db, studentID, req.CompetitionID, req.VoteType); err != nil {
+		if errors.Is(err, utils.ErrDownvoteDisabled) || errors.Is(err, utils.ErrUpvoteLimitReached) {
+			utils.ResponseError(c, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -90,3 +103,22 @@ func GetStudentVotes(c *gin.Context) {
 
 	utils.ResponseOK(c, votes)
 }
+
+// GetMyVotingQuota 获取当前学生在本届的投票规则与剩余赞成票数
+func GetMyVotingQuota(c *gin.Context) {
+	// 从上下文获取学生ID
+	studentID, ok := middlewares.GetUserIDFromContext(c)
+	if !ok {
+		utils.ResponseError(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	db := database.GetDB()
+	quota, err := models.GetStudentVotingQuota(db, config.Get().CurrentEventID, studentID)
+	if err != nil {
+		utils.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.ResponseOK(c, quota)
+}
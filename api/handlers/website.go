@@ -15,6 +15,10 @@ type WebsiteInfoResponse struct {
 	PublicSecBeian string `json:"public_sec_beian"`
 	DingTalkCorpID string `json:"dingtalk_corp_id"`
 	Domain         string `json:"domain"`
+	OIDC           struct {
+		Enabled     bool   `json:"enabled"`
+		DisplayName string `json:"display_name"`
+	} `json:"oidc"` // 统一身份认证登录入口
 }
 
 // GetWebsiteInfo 获取网站信息（公共API）
@@ -30,6 +34,8 @@ func GetWebsiteInfo(c *gin.Context) {
 		DingTalkCorpID: cfg.DingTalk.CorpID,
 		Domain:         cfg.Website.Domain,
 	}
+	resp.OIDC.Enabled = cfg.OIDC.Enabled
+	resp.OIDC.DisplayName = cfg.OIDC.DisplayName
 
 	// 返回响应
 	utils.ResponseOK(c, resp)
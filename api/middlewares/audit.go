@@ -0,0 +1,180 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/gin-gonic/gin"
+)
+
+// 审计相关上下文键
+const (
+	AuditBeforeKey string = "audit_before"
+	AuditAfterKey  string = "audit_after"
+)
+
+// maxAuditBodySize 记录到审计日志的请求内容上限，超出（如导入文件）时不记录请求内容
+const maxAuditBodySize = 64 << 10
+
+// sensitiveAuditFields 审计日志中需要脱敏的字段（字段名包含以下内容）
+var sensitiveAuditFields = []string{"password", "secret", "token"}
+
+// auditResponseWriter 记录响应内容，用于获取响应中的状态码与消息
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// AuditMiddleware 审计日志中间件
+// 记录管理员接口中所有会修改数据的请求（POST、PUT、PATCH、DELETE），需在 AuthMiddleware 之后使用
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		// 读取 JSON 请求内容后放回，供处理程序继续读取
+		var requestBody []byte
+		if c.Request.Body != nil && c.ContentType() == "application/json" && c.Request.ContentLength <= maxAuditBodySize {
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBodySize+1))
+			if err == nil && len(body) <= maxAuditBodySize {
+				requestBody = body
+			}
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		actorID, _ := GetUserIDFromContext(c)
+		actorName, _ := c.Get(UsernameKey)
+		log := &types.AuditLog{
+			ActorID:    actorID,
+			Action:     c.Request.Method + " " + c.FullPath(),
+			Path:       c.Request.URL.Path,
+			IPAddress:  c.ClientIP(),
+			StatusCode: c.Writer.Status(),
+		}
+		log.ActorName, _ = actorName.(string)
+		if keyID, ok := GetAPIKeyIDFromContext(c); ok {
+			log.APIKeyID = &keyID
+		}
+		log.TargetType, log.TargetID = auditTarget(c)
+
+		// 业务错误同样返回 HTTP 200，以响应内容中的状态码为准
+		var response struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(writer.body.Bytes(), &response); err == nil && response.Code != 0 {
+			log.StatusCode = response.Code
+			log.Message = response.Message
+		}
+
+		if before, ok := c.Get(AuditBeforeKey); ok {
+			log.Before = before.(json.RawMessage)
+		}
+		if after, ok := c.Get(AuditAfterKey); ok {
+			log.After = after.(json.RawMessage)
+		} else if len(requestBody) > 0 {
+			log.After = redactAuditJSON(requestBody)
+		}
+
+		// 请求已处理完成，写入审计日志失败不影响响应
+		_ = models.CreateAuditLog(log)
+	}
+}
+
+// auditTarget 根据路由推断操作对象：对象类型为 :id 参数前的路径段（无 :id 时为 /admin 后的第一段）
+func auditTarget(c *gin.Context) (string, string) {
+	segments := strings.Split(strings.Trim(c.FullPath(), "/"), "/")
+	for i, segment := range segments {
+		if segment == ":id" && i > 0 {
+			return segments[i-1], c.Param("id")
+		}
+	}
+	for i, segment := range segments {
+		if segment == "admin" && i+1 < len(segments) {
+			return segments[i+1], ""
+		}
+	}
+	return "", ""
+}
+
+// SetAuditBefore 记录操作前的数据快照
+func SetAuditBefore(c *gin.Context, data interface{}) {
+	setAuditSnapshot(c, AuditBeforeKey, data)
+}
+
+// SetAuditAfter 记录操作后的数据快照（未设置时记录请求内容）
+func SetAuditAfter(c *gin.Context, data interface{}) {
+	setAuditSnapshot(c, AuditAfterKey, data)
+}
+
+// setAuditSnapshot 序列化并脱敏数据快照，在设置时序列化以保留当时的数据
+func setAuditSnapshot(c *gin.Context, key string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	c.Set(key, redactAuditJSON(raw))
+}
+
+// redactAuditJSON 对 JSON 中的密码、密钥、令牌等字段脱敏，非 JSON 内容不记录
+func redactAuditJSON(raw []byte) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactAuditValue(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactAuditValue 递归替换敏感字段的字符串值
+func redactAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			// 仅替换字符串值，布尔值等标记字段（如 must_change_password）保留
+			if text, ok := item.(string); ok && text != "" && isSensitiveAuditField(key) {
+				v[key] = "******"
+				continue
+			}
+			v[key] = redactAuditValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactAuditValue(item)
+		}
+	}
+	return value
+}
+
+// isSensitiveAuditField 判断字段是否需要脱敏
+func isSensitiveAuditField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveAuditFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,14 @@
 package middlewares
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/models"
 	"github.com/SHXZ-OSS/sports-meeting-system/services"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -16,8 +19,58 @@ const (
 	UsernameKey    string = "username"
 	RoleKey        string = "role"
 	PermissionsKey string = "permission"
+	SessionIDKey   string = "session_id"
+	// MustChangePasswordKey 当前用户是否需先修改密码
+	MustChangePasswordKey string = "must_change_password"
+	// MustEnableTwoFactorKey 当前用户是否需先启用两步验证
+	MustEnableTwoFactorKey string = "must_enable_two_factor"
+	// CurrentUserKey 当前管理员用户（使用API密钥时为按密钥限定权限与班级范围后的用户）
+	CurrentUserKey string = "current_user"
+	// APIKeyIDKey 使用API密钥调用时的密钥ID
+	APIKeyIDKey string = "api_key_id"
+	// CurrentParentKey 当前家长
+	CurrentParentKey string = "current_parent"
 )
 
+// apiKeyPathPrefix API密钥可调用的接口前缀
+const apiKeyPathPrefix = "/api/admin/"
+
+// apiKeyForbiddenRoutes API密钥不能调用的接口（按路由匹配，含其下的所有接口）
+// 账号、密钥、登录会话、两步验证与系统设置相关的操作只能由管理员登录后完成
+var apiKeyForbiddenRoutes = []string{
+	"/api/admin/users",
+	"/api/admin/api_keys",
+	"/api/admin/students/:id/sessions",
+	"/api/admin/settings",
+}
+
+// isAPIKeyForbiddenRoute 判断API密钥是否不能调用该路由
+func isAPIKeyForbiddenRoute(route string) bool {
+	for _, forbidden := range apiKeyForbiddenRoutes {
+		if route == forbidden || strings.HasPrefix(route, forbidden+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// passwordChangeAllowedPaths 需修改密码时仍允许访问的接口
+var passwordChangeAllowedPaths = map[string]bool{
+	"/api/password":   true,
+	"/api/logout":     true,
+	"/api/logout/all": true,
+}
+
+// twoFactorEnrollmentAllowedPaths 需启用两步验证时仍允许访问的接口
+var twoFactorEnrollmentAllowedPaths = map[string]bool{
+	"/api/2fa":        true,
+	"/api/2fa/setup":  true,
+	"/api/2fa/enable": true,
+	"/api/password":   true,
+	"/api/logout":     true,
+	"/api/logout/all": true,
+}
+
 // AuthMiddleware 身份验证中间件
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -38,6 +91,12 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 		tokenString := parts[1]
 
+		// API密钥
+		if strings.HasPrefix(tokenString, types.APIKeyPrefix) {
+			authenticateAPIKey(c, tokenString)
+			return
+		}
+
 		// 验证令牌
 		claims, err := services.ValidateToken(tokenString)
 		if err != nil {
@@ -46,12 +105,55 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 验证用户存在（根据角色验证不同的表）
+		// 验证服务端会话（未撤销、未过期），撤销后令牌立即失效
+		session, err := models.ValidateSession(claims.Id, claims.UserID, string(claims.Role))
+		if err != nil {
+			utils.ResponseError(c, http.StatusUnauthorized, models.ErrSessionInvalid.Error())
+			c.Abort()
+			return
+		}
+
+		// 账号因登录失败次数过多被锁定期间，已登录的会话同样不可使用
+		if retryAfter, err := models.GetAccountLock(claims.Username); err != nil {
+			if errors.Is(err, models.ErrAccountLocked) {
+				utils.ResponseError(c, http.StatusLocked, fmt.Sprintf("%s，请%d秒后再试", err.Error(), retryAfter))
+			} else {
+				utils.ResponseError(c, http.StatusInternalServerError, "验证账号状态失败")
+			}
+			c.Abort()
+			return
+		}
+
+		// 验证用户存在（根据角色验证不同的表），权限以数据库中的当前值为准
+		// 需修改密码仅限制账号密码登录的会话，钉钉免登录的用户可能并不知道初始密码
+		// 需启用两步验证限制所有登录方式的管理员会话，避免通过钉钉或统一身份认证登录绕过
+		var permissions []string
+		mustChangePassword := false
+		mustEnableTwoFactor := false
 		switch claims.Role {
 		case services.RoleAdmin:
-			_, err = models.GetUserByID(claims.UserID)
+			var user *types.User
+			user, err = models.GetUserByID(claims.UserID)
+			if err == nil {
+				permissions = models.GetPermissionList(user)
+				mustChangePassword = user.MustChangePassword
+				mustEnableTwoFactor = !user.TwoFactorEnabled && models.IsTwoFactorRequired(user)
+				c.Set(CurrentUserKey, user)
+			}
 		case services.RoleStudent:
-			_, err = models.GetStudentByID(claims.UserID)
+			var student *types.Student
+			student, err = models.GetStudentByID(claims.UserID)
+			if err == nil {
+				mustChangePassword = student.MustChangePassword
+			}
+		case services.RoleParent:
+			var parent *types.Parent
+			parent, err = models.GetParentByID(claims.UserID)
+			if err == nil {
+				c.Set(CurrentParentKey, parent)
+			}
+		default:
+			err = models.ErrSessionInvalid
 		}
 
 		if err != nil {
@@ -64,13 +166,82 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set(UserIDKey, claims.UserID)
 		c.Set(UsernameKey, claims.Username)
 		c.Set(RoleKey, claims.Role)
-		c.Set(PermissionsKey, claims.Permission)
+		c.Set(PermissionsKey, permissions)
+		c.Set(SessionIDKey, session.ID)
+		c.Set(MustChangePasswordKey, mustChangePassword && session.Method == types.LoginMethodPassword)
+		c.Set(MustEnableTwoFactorKey, mustEnableTwoFactor && models.IsTwoFactorEnforcedMethod(session.Method))
 
 		// 调用下一个处理程序
 		c.Next()
 	}
 }
 
+// authenticateAPIKey 验证API密钥，以密钥创建者的身份（按密钥限定权限与班级范围）继续处理请求
+// API密钥没有登录会话，仅能调用管理接口（用户、密钥、登录会话管理与系统设置除外）
+// 创建者需修改密码或启用两步验证期间，其密钥不可使用
+func authenticateAPIKey(c *gin.Context, rawKey string) {
+	if !strings.HasPrefix(c.Request.URL.Path, apiKeyPathPrefix) {
+		utils.ResponseError(c, http.StatusForbidden, "API密钥仅可调用管理接口")
+		c.Abort()
+		return
+	}
+	if isAPIKeyForbiddenRoute(c.FullPath()) {
+		utils.ResponseError(c, http.StatusForbidden, "API密钥不能调用用户、密钥、登录会话管理与系统设置接口")
+		c.Abort()
+		return
+	}
+
+	key, user, err := models.AuthenticateAPIKey(rawKey, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyInvalid) || errors.Is(err, models.ErrAPIKeyExpired) || errors.Is(err, models.ErrAPIKeyNoPermission) {
+			utils.ResponseError(c, http.StatusUnauthorized, err.Error())
+		} else if errors.Is(err, models.ErrAPIKeyOwnerNotReady) {
+			utils.ResponseError(c, http.StatusForbidden, err.Error())
+		} else {
+			utils.ResponseError(c, http.StatusInternalServerError, "验证API密钥失败")
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set(UserIDKey, user.ID)
+	c.Set(UsernameKey, user.Username)
+	c.Set(RoleKey, services.RoleAdmin)
+	c.Set(PermissionsKey, models.GetPermissionList(user))
+	c.Set(CurrentUserKey, user)
+	c.Set(APIKeyIDKey, key.ID)
+
+	c.Next()
+}
+
+// PasswordChangeMiddleware 强制修改密码中间件
+// 账号使用初始密码或被重置密码时，除修改密码与退出登录外的接口均不可访问
+func PasswordChangeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(MustChangePasswordKey) && !passwordChangeAllowedPaths[c.FullPath()] {
+			utils.ResponseError(c, http.StatusForbidden, "需先修改密码")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TwoFactorEnrollmentMiddleware 强制启用两步验证中间件
+// 拥有须启用两步验证的权限但尚未启用的管理员，除设置两步验证、修改密码与退出登录外的接口均不可访问
+func TwoFactorEnrollmentMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(MustEnableTwoFactorKey) && !twoFactorEnrollmentAllowedPaths[c.FullPath()] {
+			utils.ResponseError(c, http.StatusForbidden, "需先启用两步验证")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // AdminMiddleware 管理员验证中间件
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -89,16 +260,8 @@ func AdminMiddleware() gin.HandlerFunc {
 // PermissionMiddleware 权限验证中间件
 func PermissionMiddleware(requiredPermission int) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从上下文获取用户ID
-		userID, ok := GetUserIDFromContext(c)
-		if !ok {
-			utils.ResponseError(c, http.StatusUnauthorized, "unauthorized")
-			c.Abort()
-			return
-		}
-
-		// 获取用户信息
-		user, err := models.GetUserByID(userID)
+		// 获取当前用户信息
+		user, err := GetCurrentUser(c)
 		if err != nil {
 			utils.ResponseError(c, http.StatusUnauthorized, "user not found")
 			c.Abort()
@@ -119,16 +282,8 @@ func PermissionMiddleware(requiredPermission int) gin.HandlerFunc {
 // PermissionAnyMiddleware 允许多个权限之一的中间件
 func PermissionAnyMiddleware(requiredPermissions ...int) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从上下文获取用户ID
-		userID, ok := GetUserIDFromContext(c)
-		if !ok {
-			utils.ResponseError(c, http.StatusUnauthorized, "unauthorized")
-			c.Abort()
-			return
-		}
-
-		// 获取用户信息
-		user, err := models.GetUserByID(userID)
+		// 获取当前用户信息
+		user, err := GetCurrentUser(c)
 		if err != nil {
 			utils.ResponseError(c, http.StatusUnauthorized, "user not found")
 			c.Abort()
@@ -169,6 +324,21 @@ func StudentMiddleware() gin.HandlerFunc {
 	}
 }
 
+// ParentMiddleware 家长验证中间件
+func ParentMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 从上下文获取用户角色
+		role, ok := GetRoleFromContext(c)
+		if !ok || role != services.RoleParent {
+			utils.ResponseError(c, http.StatusForbidden, "parent access required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // GetUserIDFromContext 从上下文获取用户ID
 func GetUserIDFromContext(c *gin.Context) (int, bool) {
 	userID, ok := c.Get(UserIDKey)
@@ -179,6 +349,52 @@ func GetUserIDFromContext(c *gin.Context) (int, bool) {
 	return id, ok
 }
 
+// GetCurrentUser 获取当前管理员用户
+// 使用API密钥调用时返回按密钥限定权限与班级范围后的用户，处理程序应使用该用户进行权限判断
+func GetCurrentUser(c *gin.Context) (*types.User, error) {
+	if value, ok := c.Get(CurrentUserKey); ok {
+		if user, ok := value.(*types.User); ok {
+			return user, nil
+		}
+	}
+
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		return nil, errors.New("未授权")
+	}
+	return models.GetUserByID(userID)
+}
+
+// GetCurrentParent 获取当前家长
+func GetCurrentParent(c *gin.Context) (*types.Parent, error) {
+	if value, ok := c.Get(CurrentParentKey); ok {
+		if parent, ok := value.(*types.Parent); ok {
+			return parent, nil
+		}
+	}
+	return nil, errors.New("未授权")
+}
+
+// GetAPIKeyIDFromContext 从上下文获取API密钥ID，非API密钥调用时返回 false
+func GetAPIKeyIDFromContext(c *gin.Context) (int, bool) {
+	keyID, ok := c.Get(APIKeyIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := keyID.(int)
+	return id, ok
+}
+
+// GetSessionIDFromContext 从上下文获取当前登录会话ID
+func GetSessionIDFromContext(c *gin.Context) (int, bool) {
+	sessionID, ok := c.Get(SessionIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := sessionID.(int)
+	return id, ok
+}
+
 // GetRoleFromContext 从上下文获取用户角色
 func GetRoleFromContext(c *gin.Context) (services.UserRole, bool) {
 	role, ok := c.Get(RoleKey)
@@ -224,6 +440,12 @@ func GetFullnameFromContext(c *gin.Context) (string, bool) {
 			return "", false
 		}
 		return student.FullName, true
+	case services.RoleParent:
+		parent, err := models.GetParentByID(userID)
+		if err != nil {
+			return "", false
+		}
+		return parent.Name, true
 	}
 
 	return "", false
@@ -35,6 +35,10 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	// 公开API路由（游客访问）
 	public := api.Group("/public")
 	public.GET("/website_info", handlers.GetWebsiteInfo)
+	// 家长确认报名（通过钉钉卡片中的确认链接访问）
+	public.GET("/consents/:token", handlers.GetConsentByToken)
+	public.POST("/consents/:token/approve", handlers.ApproveConsentByToken)
+	public.POST("/consents/:token/reject", handlers.RejectConsentByToken)
 
 	// 看板相关API路由
 	dashboard := public.Group("")
@@ -45,6 +49,8 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	dashboard.GET("/competitions/:id/registrations", handlers.GetCompetitionRegistrationsForPublic)
 	dashboard.GET("/scores/student/:id", handlers.GetStudentScoresById)
 	dashboard.GET("/statistics", handlers.GetStatistics)
+	dashboard.GET("/venues", handlers.GetAllVenues)
+	dashboard.GET("/events", handlers.GetPublicEvents) // 运动会届次列表，其余看板接口可通过 event_id 参数查看往届数据
 	// 得分相关（公开）
 	dashboard.GET("/points/classes/summary", handlers.GetClassPointsSummary)
 	dashboard.GET("/points/students/summary", handlers.GetStudentPointsSummary)
@@ -53,15 +59,37 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 
 	// 认证API路由
 	api.POST("/login", handlers.Login)
+	api.POST("/login/2fa", handlers.TwoFactorLogin) // 已启用两步验证的账号，提交动态验证码完成登录
 	api.POST("/dingtalk/login", handlers.DingTalkLogin)
+	api.GET("/oidc/authorize", handlers.OIDCAuthorize)
+	api.POST("/oidc/login", handlers.OIDCLogin)
+	api.POST("/refresh", handlers.RefreshToken) // 使用刷新令牌换取新的访问令牌
 
 	// 需要身份验证的API路由
 	secured := api.Group("")
-	secured.Use(middlewares.AuthMiddleware())
+	secured.Use(middlewares.AuthMiddleware(), middlewares.PasswordChangeMiddleware(), middlewares.TwoFactorEnrollmentMiddleware())
+
+	// 修改密码（管理员与学生通用，使用初始密码登录后须先修改密码）
+	secured.POST("/password", handlers.ChangePassword)
+
+	// 登录会话管理（管理员与学生通用）
+	secured.GET("/sessions", handlers.GetMySessions)          // 当前用户的登录设备
+	secured.DELETE("/sessions/:id", handlers.RevokeMySession) // 退出指定设备
+	secured.POST("/logout", handlers.Logout)                  // 退出当前登录
+	secured.POST("/logout/all", handlers.LogoutAll)           // 退出所有设备
+
+	// 两步验证（仅管理员账号）
+	twoFactor := secured.Group("/2fa")
+	twoFactor.Use(middlewares.AdminMiddleware())
+	twoFactor.GET("", handlers.GetTwoFactorStatus)
+	twoFactor.POST("/setup", handlers.SetupTwoFactor)                   // 生成密钥
+	twoFactor.POST("/enable", handlers.EnableTwoFactor)                 // 确认并启用
+	twoFactor.POST("/disable", handlers.DisableTwoFactor)               // 关闭
+	twoFactor.POST("/recovery_codes", handlers.RegenerateRecoveryCodes) // 重新生成恢复码
 
 	// 管理员API路由
 	adminAPI := secured.Group("/admin")
-	adminAPI.Use(middlewares.AdminMiddleware())
+	adminAPI.Use(middlewares.AdminMiddleware(), middlewares.AuditMiddleware()) // 修改数据的请求记录审计日志
 
 	// 用户管理（需要用户管理权限）
 	userMgmt := adminAPI.Group("/users")
@@ -72,6 +100,18 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	userMgmt.PUT("/:id", handlers.UpdateUser)
 	userMgmt.DELETE("/:id", handlers.DeleteUser)
 	userMgmt.GET("/classes", handlers.GetAllClasses)
+	userMgmt.GET("/lockouts", handlers.GetLoginThrottles)         // 登录失败记录与锁定状态（含学生账号与IP）
+	userMgmt.DELETE("/lockouts/:id", handlers.ClearLoginThrottle) // 解除锁定
+	userMgmt.GET("/:id/sessions", handlers.GetUserSessions)       // 用户的登录会话
+	userMgmt.DELETE("/:id/sessions", handlers.RevokeUserSessions) // 强制用户下线
+	userMgmt.DELETE("/:id/2fa", handlers.ResetUserTwoFactor)      // 重置两步验证
+
+	// API密钥管理（需要用户管理权限，供大屏、成绩打印脚本等程序调用管理接口）
+	apiKeyMgmt := adminAPI.Group("/api_keys")
+	apiKeyMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionUserManagement))
+	apiKeyMgmt.GET("", handlers.GetAPIKeys)
+	apiKeyMgmt.POST("", handlers.CreateAPIKey)
+	apiKeyMgmt.DELETE("/:id", handlers.RevokeAPIKey)
 
 	// 学生与班级管理（需要学生管理权限）
 	studentMgmt := adminAPI.Group("/students")
@@ -82,6 +122,14 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	studentMgmt.PUT("/:id", handlers.UpdateStudent)
 	studentMgmt.DELETE("/:id", handlers.DeleteStudent)
 	studentMgmt.POST("/:id/reset_password", handlers.ResetStudentPassword)
+	studentMgmt.GET("/:id/sessions", handlers.GetStudentSessions)       // 学生的登录会话
+	studentMgmt.DELETE("/:id/sessions", handlers.RevokeStudentSessions) // 强制学生下线
+	studentMgmt.GET("/:id/profile", handlers.GetStudentProfile)         // 跨届次参赛档案
+	// 参赛资格限制（医疗免赛、违纪禁赛，仅学生管理员可见）
+	studentMgmt.GET("/:id/eligibilities", handlers.GetStudentEligibilities)
+	studentMgmt.POST("/:id/eligibilities", handlers.CreateStudentEligibility)
+	studentMgmt.PUT("/:id/eligibilities/:eligibility_id", handlers.UpdateStudentEligibility)
+	studentMgmt.DELETE("/:id/eligibilities/:eligibility_id", handlers.DeleteStudentEligibility)
 
 	classMgmt := adminAPI.Group("/classes")
 	classMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionStudentAndClassManagement))
@@ -91,6 +139,14 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	classMgmt.PUT("/:id", handlers.UpdateClass)
 	classMgmt.DELETE("/:id", handlers.DeleteClass)
 
+	// 班级沿革（不同届次中班级的对应关系，用于跨届次对比）
+	lineageMgmt := adminAPI.Group("/class_lineages")
+	lineageMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionStudentAndClassManagement))
+	lineageMgmt.GET("", handlers.GetAllClassLineages)
+	lineageMgmt.POST("", handlers.CreateClassLineage)
+	lineageMgmt.PUT("/:id", handlers.UpdateClassLineage)
+	lineageMgmt.DELETE("/:id", handlers.DeleteClassLineage)
+
 	// 项目管理（需要项目管理权限）
 	projectMgmt := adminAPI.Group("/competitions")
 	projectMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionProjectManagement))
@@ -102,6 +158,46 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	projectMgmt.POST("/:id/approve", handlers.ApproveCompetition)
 	projectMgmt.POST("/:id/reject", handlers.RejectCompetition)
 	projectMgmt.GET("/:id/registrations", handlers.GetCompetitionRegistrations)
+	projectMgmt.POST("/:id/status", handlers.ChangeCompetitionStatus)      // 变更比赛状态（进行中、延期、取消）
+	projectMgmt.GET("/:id/status_logs", handlers.GetCompetitionStatusLogs) // 状态变更记录
+	projectMgmt.GET("/:id/revisions", handlers.GetCompetitionRevisions)    // 推荐项目提交版本记录
+	projectMgmt.GET("/:id/duplicates", handlers.GetCompetitionDuplicates)  // 名称相近的疑似重复项目
+	projectMgmt.POST("/:id/merge", handlers.MergeCompetitions)             // 合并重复推荐项目
+	projectMgmt.GET("/:id/officials", handlers.GetCompetitionOfficials)    // 指派的裁判
+	projectMgmt.PUT("/:id/officials", handlers.UpdateCompetitionOfficials) // 更新指派的裁判
+
+	// 投票入围报告（需要项目管理权限）
+	shortlistMgmt := adminAPI.Group("/shortlists")
+	shortlistMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionProjectManagement))
+	shortlistMgmt.GET("", handlers.GetShortlistReports)
+	shortlistMgmt.POST("", handlers.GenerateShortlistReport) // 根据投票结果重新生成入围报告
+	shortlistMgmt.GET("/:id", handlers.GetShortlistReport)
+	shortlistMgmt.POST("/:id/confirm", handlers.ConfirmShortlistReport) // 确认并批量审核
+	shortlistMgmt.POST("/:id/discard", handlers.DiscardShortlistReport)
+
+	// 场地管理（需要项目管理权限）
+	venueMgmt := adminAPI.Group("/venues")
+	venueMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionProjectManagement))
+	venueMgmt.GET("", handlers.GetAllVenues)
+	venueMgmt.POST("", handlers.CreateVenue)
+	venueMgmt.PUT("/:id", handlers.UpdateVenue)
+	venueMgmt.DELETE("/:id", handlers.DeleteVenue)
+
+	// 日程编排（需要项目管理权限）
+	scheduleMgmt := adminAPI.Group("/schedule")
+	scheduleMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionProjectManagement))
+	scheduleMgmt.POST("/generate", handlers.GenerateSchedule)    // 自动生成日程草稿
+	scheduleMgmt.GET("/draft", handlers.GetScheduleDraft)        // 获取日程草稿及冲突
+	scheduleMgmt.PUT("/draft/:id", handlers.UpdateScheduleEntry) // 调整日程条目
+	scheduleMgmt.DELETE("/draft", handlers.DeleteScheduleDraft)  // 放弃日程草稿
+	scheduleMgmt.POST("/publish", handlers.PublishSchedule)      // 发布日程
+
+	// 跨届次对比与投票分析报表（需要项目管理权限）
+	reportMgmt := adminAPI.Group("/reports")
+	reportMgmt.Use(middlewares.PermissionMiddleware(utils.PermissionProjectManagement))
+	reportMgmt.GET("/class_lineages", handlers.GetClassLineageReports) // 按班级沿革对比
+	reportMgmt.GET("/competitions", handlers.GetCompetitionReports)    // 按比赛名称对比
+	reportMgmt.GET("/voting", handlers.GetVoteAnalytics)               // 投票分布与参与率
 
 	// 报名管理（需要报名管理权限）
 	registrationMgmt := adminAPI.Group("/registrations")
@@ -113,6 +209,10 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	registrationMgmt.POST("/register", handlers.RegisterForCompetitionForAdmin)                   // 为学生报名
 	registrationMgmt.DELETE("/unregister/:id", handlers.UnregisterFromCompetitionForAdmin)        // 取消学生报名
 	registrationMgmt.GET("/checklist", handlers.GetCompetitionChecklist)                          // 检查清单
+	registrationMgmt.GET("/consents", handlers.GetConsents)                                       // 家长确认列表
+	registrationMgmt.POST("/consents/:id/approve", handlers.ApproveConsentForAdmin)               // 代为确认报名
+	registrationMgmt.POST("/consents/:id/reject", handlers.RejectConsentForAdmin)                 // 拒绝报名
+	registrationMgmt.POST("/consents/:id/resend", handlers.ResendConsentNotification)             // 重新通知家长
 
 	// 成绩管理
 	scoreMgmt := adminAPI.Group("/scores")
@@ -120,7 +220,8 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	// 成绩提交（需要成绩提交权限）
 	scoreInput := scoreMgmt.Group("/input")
 	scoreInput.Use(middlewares.PermissionMiddleware(utils.PermissionScoreInput))
-	scoreInput.GET("/competitions", handlers.GetAllCompetitions)
+	scoreInput.GET("/competitions", handlers.GetScoreInputCompetitions)
+	scoreInput.GET("/start_list/competitions", handlers.GetStartListCompetitions)
 	scoreInput.GET("/:id/registrations", handlers.GetCompetitionRegistrations)
 	scoreInput.POST("", handlers.CreateOrUpdateScores)
 	scoreInput.GET("/:id", handlers.GetCompetitionScores)
@@ -129,7 +230,7 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	// 成绩审核（需要成绩审核权限）
 	scoreReview := scoreMgmt.Group("/review")
 	scoreReview.Use(middlewares.PermissionMiddleware(utils.PermissionScoreReview))
-	scoreReview.GET("/competitions", handlers.GetAllCompetitions)
+	scoreReview.GET("/competitions", handlers.GetScoreReviewCompetitions)
 	scoreReview.GET("/:id", handlers.GetCompetitionScores)
 	scoreReview.POST("", handlers.ReviewScores)
 
@@ -153,12 +254,19 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	// 危险API
 	websiteMgmt.POST("/rebuild-mapping", handlers.RebuildParentStudentMapping)
 	websiteMgmt.GET("/rebuild-mapping/logs", handlers.GetMappingLogs)
+	// 管理操作审计日志
+	websiteMgmt.GET("/audit_logs", handlers.GetAuditLogs)
+	websiteMgmt.GET("/audit_logs/:id", handlers.GetAuditLog)
 	// 运动会届次管理
 	websiteMgmt.GET("/events", handlers.GetEvents)
 	websiteMgmt.POST("/events", handlers.CreateEvent)
 	websiteMgmt.PUT("/events/:id", handlers.UpdateEvent)
 	websiteMgmt.DELETE("/events/:id", handlers.DeleteEvent)
 	websiteMgmt.POST("/events/:id/switch", handlers.SwitchEvent)
+	websiteMgmt.GET("/events/:id/settings", handlers.GetEventSettings)         // 届次设置（时间安排、报名上限、投票规则、得分映射）
+	websiteMgmt.PUT("/events/:id/settings", handlers.UpdateEventSettings)      // 更新届次设置
+	websiteMgmt.GET("/events/:id/competitions", handlers.GetEventCompetitions) // 往届比赛项目
+	websiteMgmt.POST("/events/:id/clone", handlers.CloneCompetitions)          // 复制往届比赛项目到当前届次
 
 	// 学生API路由
 	studentAPI := secured.Group("/student")
@@ -167,13 +275,32 @@ func SetupRouter(staticFS fs.FS) *gin.Engine {
 	// 学生功能（学生在提交推荐项目后不能进行修改与删除）
 	studentAPI.POST("/competitions", handlers.CreateCompetition)                       // 提交推荐项目
 	studentAPI.GET("/competitions", handlers.GetAllEligibleCompetitions)               // 获取项目列表
+	studentAPI.GET("/proposals", handlers.GetMyProposals)                              // 获取自己提交的推荐项目及审核结果
+	studentAPI.GET("/proposals/duplicates", handlers.CheckDuplicateProposals)          // 提交前检查名称相近的推荐项目
+	studentAPI.PUT("/proposals/:id", handlers.ResubmitProposal)                        // 修改并重新提交推荐项目
+	studentAPI.GET("/proposals/:id/revisions", handlers.GetMyProposalRevisions)        // 推荐项目提交版本记录
 	studentAPI.GET("/registrations", handlers.GetStudentRegistrations)                 // 获取报名记录
+	studentAPI.GET("/consents", handlers.GetStudentConsents)                           // 获取家长确认记录
 	studentAPI.POST("/register", handlers.RegisterForCompetitionForStudent)            // 报名项目
 	studentAPI.DELETE("/unregister/:id", handlers.UnregisterFromCompetitionForStudent) // 取消报名
 	studentAPI.GET("/scores", handlers.GetStudentScores)                               // 获取个人成绩
 	studentAPI.POST("/vote", handlers.VoteCompetition)                                 // 投票
 	studentAPI.GET("/votes", handlers.GetStudentVotes)                                 // 获取投票记录
+	studentAPI.GET("/votes/quota", handlers.GetMyVotingQuota)                          // 投票规则与剩余赞成票数
 	studentAPI.GET("/points/summary", handlers.GetMyPointsSummary)                     // 获取个人得分和排名
+	studentAPI.GET("/profile", handlers.GetMyProfile)                                  // 获取历届参赛档案
+
+	// 家长API路由（只读查看关联学生的信息，并处理报名确认）
+	parentAPI := secured.Group("/parent")
+	parentAPI.Use(middlewares.ParentMiddleware())
+	parentAPI.GET("/profile", handlers.GetParentProfile)                          // 家长信息及关联的学生
+	parentAPI.GET("/children/:id/registrations", handlers.GetChildRegistrations)  // 孩子的报名记录
+	parentAPI.GET("/children/:id/schedule", handlers.GetChildSchedule)            // 孩子的比赛日程
+	parentAPI.GET("/children/:id/scores", handlers.GetChildScores)                // 孩子的成绩
+	parentAPI.GET("/children/:id/points/summary", handlers.GetChildPointsSummary) // 孩子的得分和排名
+	parentAPI.GET("/consents", handlers.GetParentConsents)                        // 家长确认记录
+	parentAPI.POST("/consents/:id/approve", handlers.ApproveConsentForParent)     // 同意报名
+	parentAPI.POST("/consents/:id/reject", handlers.RejectConsentForParent)       // 拒绝报名
 
 	// 静态文件服务
 	rootStaticFiles := []string{
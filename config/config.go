@@ -27,8 +27,23 @@ type Config struct {
 		AgentID   string `json:"agent_id"`
 		CorpID    string `json:"corp_id"`
 	} `json:"dingtalk"`
+	// OIDC 统一身份认证（OpenID Connect 授权码模式）
+	OIDC struct {
+		Enabled         bool     `json:"enabled"`
+		DisplayName     string   `json:"display_name"`      // 登录按钮显示的名称
+		Issuer          string   `json:"issuer"`            // 身份提供方地址，用于获取 /.well-known/openid-configuration
+		ClientID        string   `json:"client_id"`         // 客户端ID
+		ClientSecret    string   `json:"client_secret"`     // 客户端密钥
+		RedirectURL     string   `json:"redirect_url"`      // 回调地址（需与身份提供方中登记的一致）
+		Scopes          []string `json:"scopes"`            // 申请的范围，需包含 openid
+		MatchBy         string   `json:"match_by"`          // 学生账号匹配方式：external_id 按外部ID（默认），username 按用户名（需显式开启）；管理员始终按外部ID匹配
+		UsernameClaim   string   `json:"username_claim"`    // 按用户名匹配学生时使用的声明
+		ExternalIDClaim string   `json:"external_id_claim"` // 按外部ID匹配时使用的声明
+	} `json:"oidc"`
 	Security struct {
 		JWTSecret string `json:"jwt_secret"`
+		// 须启用两步验证的权限（与用户权限相同的位定义），拥有其中任一权限的管理员须启用两步验证，0表示不强制
+		TwoFactorRequiredPermissions int `json:"two_factor_required_permissions"`
 	} `json:"security"`
 	Website struct {
 		Name           string `json:"name"`
@@ -36,6 +51,8 @@ type Config struct {
 		PublicSecBeian string `json:"public_sec_beian"`
 		Domain         string `json:"domain"`
 	} `json:"website"`
+	// Competition 与 Scoring 为旧版本的全局设置，现按运动会届次保存在 types.Event 中，
+	// 这里仅作为未设置届次的默认值和升级迁移使用
 	Competition struct {
 		SubmissionStartTime       string `json:"submission_start_time"`        // 项目征集开始时间
 		SubmissionEndTime         string `json:"submission_end_time"`          // 项目征集结束时间
@@ -48,6 +65,16 @@ type Config struct {
 	Dashboard struct {
 		Enabled bool `json:"enabled"` // 看板功能是否启用
 	} `json:"dashboard"`
+	// ScoreEntry 班级成绩模式（适用于班级自办的趣味赛等）
+	// 启用后限定班级的用户可拥有成绩提交权限，但只能查看、提交和删除所管理班级的成绩
+	ScoreEntry struct {
+		ClassScoped bool `json:"class_scoped"` // 是否启用班级成绩模式，默认不启用
+	} `json:"score_entry"`
+	Consent struct {
+		ExpireHours           int `json:"expire_hours"`            // 家长确认有效时长（小时）
+		ReminderIntervalHours int `json:"reminder_interval_hours"` // 提醒间隔（小时）
+		MaxReminders          int `json:"max_reminders"`           // 最多提醒次数，0表示不提醒
+	} `json:"consent"`
 	CurrentEventID int `json:"current_event_id"` // 当前选中的运动会届次ID
 	Scoring        struct {
 		TeamPointsMapping       map[string]float64 `json:"team_points_mapping"`       // 团体赛名次对应得分映射
@@ -79,6 +106,14 @@ func Load() error {
 		config.Competition.MaxRegistrationsPerPerson = 0 // 默认无限制
 		config.Dashboard.Enabled = true                  // 默认启用看板功能
 		config.CurrentEventID = 1                        // 默认选中第一届运动会
+		config.Consent.ExpireHours = 72                  // 默认家长确认72小时内有效
+		config.Consent.ReminderIntervalHours = 24        // 默认每24小时提醒一次
+		config.Consent.MaxReminders = 2                  // 默认最多提醒2次
+		config.OIDC.DisplayName = "统一身份认证"
+		config.OIDC.Scopes = []string{"openid", "profile"}
+		config.OIDC.MatchBy = "external_id"
+		config.OIDC.UsernameClaim = "preferred_username"
+		config.OIDC.ExternalIDClaim = "sub"
 
 		// 默认得分映射配置
 		config.Scoring.TeamPointsMapping = map[string]float64{
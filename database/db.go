@@ -116,6 +116,8 @@ func setupInitialSystem() error {
 		Permission:  utils.GetAllPermissions(),
 		DingTalkID:  "0",
 		ClassScopes: []types.Class{},
+		// 初始密码打印在日志中，首次登录后须修改
+		MustChangePassword: true,
 	}
 
 	err = db.Create(&adminUser).Error
@@ -125,7 +127,8 @@ func setupInitialSystem() error {
 
 	// 创建第一个运动会届次
 	defaultEvent := types.Event{
-		Name: "运动会",
+		Name:     "运动会",
+		Settings: utils.DefaultEventSettings(),
 	}
 	err = db.Create(&defaultEvent).Error
 	if err != nil {
@@ -182,6 +185,27 @@ func autoMigrate() error {
 		&types.Score{},
 		&types.Vote{},
 		&types.Points{},
+		&types.RegistrationConsent{},
+		&types.StudentEligibility{},
+		&types.Venue{},
+		&types.ScheduleEntry{},
+		&types.CompetitionStatusLog{},
+		&types.ClassLineage{},
+		&types.ClassLineageMember{},
+		&types.CompetitionRevision{},
+		&types.ProposalCredit{},
+		&types.ShortlistReport{},
+		&types.ShortlistItem{},
+		&types.CompetitionOfficial{},
+		&types.Session{},
+		&types.RefreshToken{},
+		&types.LoginThrottle{},
+		&types.AuditLog{},
+		&types.APIKey{},
+		&types.OIDCState{},
+		&types.TwoFactorRecoveryCode{},
+		&types.TwoFactorChallenge{},
+		&types.Parent{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto migrate: %v", err)
@@ -196,6 +220,28 @@ func autoMigrate() error {
 		log.Printf("Warning: failed to create unique index for registrations: %v", err)
 	}
 
+	// 旧版本的时间安排与得分映射保存在全局配置中，迁移到尚未设置的运动会届次
+	if err := migrateEventSettings(); err != nil {
+		log.Printf("Warning: failed to migrate event settings: %v", err)
+	}
+
+	return nil
+}
+
+// migrateEventSettings 将全局配置中的时间安排与得分映射复制到尚未设置的运动会届次
+func migrateEventSettings() error {
+	var events []types.Event
+	if err := db.Where("team_points_mapping IS NULL OR individual_points_mapping IS NULL").Find(&events).Error; err != nil {
+		return err
+	}
+
+	settings := utils.DefaultEventSettings()
+	for _, event := range events {
+		if err := db.Model(&event).Select("*").Omit("id", "name").Updates(&types.Event{Settings: settings}).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -16,6 +16,7 @@ import (
 	"github.com/SHXZ-OSS/sports-meeting-system/api/routes"
 	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/services"
 )
 
 //go:embed all:web/dist
@@ -41,6 +42,12 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// 启动家长确认后台任务（过期与提醒）
+	services.StartConsentWorker()
+
+	// 启动投票入围后台任务（投票结束后生成待确认的入围报告）
+	services.StartShortlistWorker()
+
 	// 确保上传目录存在
 	uploadDir := "./data/uploads"
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
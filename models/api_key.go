@@ -0,0 +1,207 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API密钥不存在或已撤销")
+	ErrAPIKeyInvalid       = errors.New("无效的API密钥")
+	ErrAPIKeyExpired       = errors.New("API密钥已过期")
+	ErrAPIKeyNameRequired  = errors.New("API密钥名称不能为空")
+	ErrAPIKeyScopeExceeded = errors.New("API密钥的班级权限范围不能超出创建者的范围")
+	ErrAPIKeyNoPermission  = errors.New("API密钥的创建者已不具备密钥所需的权限")
+	ErrInvalidExpireTime   = errors.New("过期时间必须晚于当前时间")
+	ErrAPIKeyOwnerNotReady = errors.New("API密钥的创建者需先修改初始密码并启用要求的两步验证")
+)
+
+// apiKeyPrefixLength 列表中展示的密钥开头长度（含前缀）
+const apiKeyPrefixLength = len(types.APIKeyPrefix) + 6
+
+// CreateAPIKey 创建API密钥，返回密钥记录与明文密钥（明文仅在创建时返回一次）
+func CreateAPIKey(name string, permission int, classScopeIDs []int, expiresAt *time.Time, creator *types.User) (*types.APIKey, string, error) {
+	db := database.GetDB()
+
+	if name == "" {
+		return nil, "", ErrAPIKeyNameRequired
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidExpireTime
+	}
+	if isAPIKeyOwnerPending(creator) {
+		return nil, "", ErrAPIKeyOwnerNotReady
+	}
+
+	// 班级范围需在创建者的范围之内
+	if !IsGlobalAdmin(creator) {
+		for _, classID := range classScopeIDs {
+			if !HasClassScope(creator, classID) {
+				return nil, "", ErrAPIKeyScopeExceeded
+			}
+		}
+	}
+
+	var classes []types.Class
+	if len(classScopeIDs) > 0 {
+		if err := db.Where("id IN ?", classScopeIDs).Find(&classes).Error; err != nil {
+			return nil, "", err
+		}
+		if len(classes) != len(classScopeIDs) {
+			return nil, "", errors.New("班级不存在")
+		}
+	}
+
+	token, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	rawKey := types.APIKeyPrefix + token
+
+	key := &types.APIKey{
+		Name:        name,
+		Prefix:      rawKey[:apiKeyPrefixLength],
+		KeyHash:     utils.HashToken(rawKey),
+		Permission:  permission,
+		ClassScopes: classes,
+		CreatedByID: creator.ID,
+		ExpiresAt:   expiresAt,
+	}
+	if err := db.Create(key).Error; err != nil {
+		return nil, "", err
+	}
+	key.CreatedByName = creator.FullName
+
+	return key, rawKey, nil
+}
+
+// GetAPIKeys 获取所有API密钥（含已撤销的密钥）
+func GetAPIKeys() ([]*types.APIKey, error) {
+	db := database.GetDB()
+
+	var keys []*types.APIKey
+	if err := db.Preload("ClassScopes").Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	// 填充创建者姓名
+	names := make(map[int]string)
+	for _, key := range keys {
+		if _, ok := names[key.CreatedByID]; !ok {
+			var user types.User
+			if err := db.Select("id", "full_name").First(&user, key.CreatedByID).Error; err == nil {
+				names[key.CreatedByID] = user.FullName
+			}
+		}
+		key.CreatedByName = names[key.CreatedByID]
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey 撤销API密钥，撤销后立即失效
+func RevokeAPIKey(id int) error {
+	db := database.GetDB()
+
+	result := db.Model(&types.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey 验证API密钥，返回密钥及按密钥限定后的创建者用户，并记录最近使用时间与IP
+func AuthenticateAPIKey(rawKey, ipAddress string) (*types.APIKey, *types.User, error) {
+	db := database.GetDB()
+
+	var key types.APIKey
+	if err := db.Preload("ClassScopes").Where("key_hash = ?", utils.HashToken(rawKey)).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAPIKeyInvalid
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, nil, ErrAPIKeyExpired
+	}
+
+	owner, err := GetUserByID(key.CreatedByID)
+	if err != nil {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+	if isAPIKeyOwnerPending(owner) {
+		return nil, nil, ErrAPIKeyOwnerNotReady
+	}
+	user, err := apiKeyEffectiveUser(&key, owner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > sessionTouchInterval || key.LastUsedIP != ipAddress {
+		if err := db.Model(&key).UpdateColumns(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ipAddress,
+		}).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &key, user, nil
+}
+
+// isAPIKeyOwnerPending 判断密钥创建者是否仍需修改密码或启用两步验证
+// 此时账号登录后只能完成这些操作，密钥同样不可创建和使用
+func isAPIKeyOwnerPending(owner *types.User) bool {
+	return owner.MustChangePassword || (!owner.TwoFactorEnabled && IsTwoFactorRequired(owner))
+}
+
+// apiKeyEffectiveUser 计算密钥实际可用的权限与班级范围
+// 权限为密钥权限与创建者当前权限的交集；班级范围为密钥范围与创建者范围的交集（密钥未设置时沿用创建者的范围）
+func apiKeyEffectiveUser(key *types.APIKey, owner *types.User) (*types.User, error) {
+	user := *owner
+	user.Permission = key.Permission & owner.Permission
+	if user.Permission == 0 {
+		return nil, ErrAPIKeyNoPermission
+	}
+
+	if len(key.ClassScopes) > 0 {
+		scopes := make([]types.Class, 0, len(key.ClassScopes))
+		for _, class := range key.ClassScopes {
+			if HasClassScope(owner, class.ID) {
+				scopes = append(scopes, class)
+			}
+		}
+		// 交集为空时不能视为全局范围
+		if len(scopes) == 0 {
+			return nil, ErrAPIKeyNoPermission
+		}
+		user.ClassScopes = scopes
+	}
+
+	return &user, nil
+}
+
+// deleteUserAPIKeys 删除用户创建的API密钥（用于删除用户）
+func deleteUserAPIKeys(tx *gorm.DB, userID int) error {
+	keyIDs := tx.Model(&types.APIKey{}).Select("id").Where("created_by_id = ?", userID)
+	if err := tx.Exec("DELETE FROM api_key_class_scopes WHERE api_key_id IN (?)", keyIDs).Error; err != nil {
+		return err
+	}
+	return tx.Where("created_by_id = ?", userID).Delete(&types.APIKey{}).Error
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+)
+
+func TestAPIKeyEffectiveUser(t *testing.T) {
+	class1 := types.Class{ID: 1}
+	class2 := types.Class{ID: 2}
+	class3 := types.Class{ID: 3}
+	studentAndRegistration := utils.PermissionStudentAndClassManagement | utils.PermissionRegistrationManagement
+
+	tests := []struct {
+		name           string
+		keyPermission  int
+		keyScopes      []types.Class
+		ownerPerm      int
+		ownerScopes    []types.Class
+		wantPermission int
+		wantScopeIDs   []int
+		wantErr        error
+	}{
+		{
+			name:           "密钥权限不超过创建者权限",
+			keyPermission:  studentAndRegistration,
+			ownerPerm:      utils.GetAllPermissions(),
+			wantPermission: studentAndRegistration,
+			wantScopeIDs:   []int{},
+		},
+		{
+			name:           "创建者权限降低后密钥权限随之降低",
+			keyPermission:  studentAndRegistration,
+			ownerPerm:      utils.PermissionStudentAndClassManagement,
+			wantPermission: utils.PermissionStudentAndClassManagement,
+			wantScopeIDs:   []int{},
+		},
+		{
+			name:          "权限交集为空",
+			keyPermission: utils.PermissionRegistrationManagement,
+			ownerPerm:     utils.PermissionStudentAndClassManagement,
+			wantErr:       ErrAPIKeyNoPermission,
+		},
+		{
+			name:           "密钥未设置班级范围时沿用创建者的范围",
+			keyPermission:  utils.PermissionStudentAndClassManagement,
+			ownerPerm:      utils.PermissionStudentAndClassManagement,
+			ownerScopes:    []types.Class{class1, class2},
+			wantPermission: utils.PermissionStudentAndClassManagement,
+			wantScopeIDs:   []int{1, 2},
+		},
+		{
+			name:           "全局创建者使用密钥的班级范围",
+			keyPermission:  utils.PermissionStudentAndClassManagement,
+			keyScopes:      []types.Class{class3},
+			ownerPerm:      utils.PermissionStudentAndClassManagement,
+			wantPermission: utils.PermissionStudentAndClassManagement,
+			wantScopeIDs:   []int{3},
+		},
+		{
+			name:           "班级范围取交集",
+			keyPermission:  utils.PermissionStudentAndClassManagement,
+			keyScopes:      []types.Class{class2, class3},
+			ownerPerm:      utils.PermissionStudentAndClassManagement,
+			ownerScopes:    []types.Class{class1, class2},
+			wantPermission: utils.PermissionStudentAndClassManagement,
+			wantScopeIDs:   []int{2},
+		},
+		{
+			name:          "班级范围交集为空时不视为全局",
+			keyPermission: utils.PermissionStudentAndClassManagement,
+			keyScopes:     []types.Class{class3},
+			ownerPerm:     utils.PermissionStudentAndClassManagement,
+			ownerScopes:   []types.Class{class1},
+			wantErr:       ErrAPIKeyNoPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &types.APIKey{Permission: tt.keyPermission, ClassScopes: tt.keyScopes}
+			owner := &types.User{ID: 1, Permission: tt.ownerPerm, ClassScopes: tt.ownerScopes}
+
+			user, err := apiKeyEffectiveUser(key, owner)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("apiKeyEffectiveUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if user.Permission != tt.wantPermission {
+				t.Errorf("apiKeyEffectiveUser() permission = %d, want %d", user.Permission, tt.wantPermission)
+			}
+			if got := GetClassScopeIDs(user); !reflect.DeepEqual(got, tt.wantScopeIDs) {
+				t.Errorf("apiKeyEffectiveUser() class scopes = %v, want %v", got, tt.wantScopeIDs)
+			}
+			// 不能修改创建者本身
+			if owner.Permission != tt.ownerPerm || len(owner.ClassScopes) != len(tt.ownerScopes) {
+				t.Errorf("apiKeyEffectiveUser() modified owner: %+v", owner)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAuditLogNotFound = errors.New("审计日志不存在")
+)
+
+// CreateAuditLog 写入一条审计日志
+func CreateAuditLog(log *types.AuditLog) error {
+	db := database.GetDB()
+
+	return db.Create(log).Error
+}
+
+// GetAuditLogs 按条件分页查询审计日志（列表不含操作前后的数据），按时间倒序排列
+func GetAuditLogs(filter types.AuditLogFilter, page, pageSize int) ([]*types.AuditLog, int, error) {
+	db := database.GetDB()
+
+	query := db.Model(&types.AuditLog{})
+	if filter.ActorID > 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action LIKE ?", "%"+filter.Action+"%")
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Keyword != "" {
+		keyword := "%" + filter.Keyword + "%"
+		query = query.Where("actor_name LIKE ? OR path LIKE ? OR message LIKE ?", keyword, keyword, keyword)
+	}
+	if filter.Success != nil {
+		if *filter.Success {
+			query = query.Where("status_code = ?", 200)
+		} else {
+			query = query.Where("status_code <> ?", 200)
+		}
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Omit("before", "after").Order("id DESC")
+	if page > 0 && pageSize > 0 {
+		query = query.Limit(pageSize).Offset((page - 1) * pageSize)
+	}
+
+	var logs []*types.AuditLog
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, int(total), nil
+}
+
+// GetAuditLogByID 获取审计日志详情
+func GetAuditLogByID(id int) (*types.AuditLog, error) {
+	db := database.GetDB()
+
+	var log types.AuditLog
+	if err := db.First(&log, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuditLogNotFound
+		}
+		return nil, err
+	}
+
+	return &log, nil
+}
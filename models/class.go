@@ -148,6 +148,11 @@ func DeleteClass(id int) error {
 		return errors.New("班级下还有学生，无法删除")
 	}
 
-	// 删除班级
-	return db.Delete(&types.Class{}, id).Error
+	// 删除班级，同时移除其在班级沿革中的对应关系
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("class_id = ?", id).Delete(&types.ClassLineageMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&types.Class{}, id).Error
+	})
 }
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrClassLineageNotFound = errors.New("班级沿革不存在")
+)
+
+// GetAllClassLineages 获取所有班级沿革
+func GetAllClassLineages() ([]*types.ClassLineage, error) {
+	db := database.GetDB()
+
+	var lineages []*types.ClassLineage
+	if err := db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("event_id ASC")
+	}).Preload("Members.Event").Preload("Members.Class").Order("id ASC").Find(&lineages).Error; err != nil {
+		return nil, err
+	}
+
+	for _, lineage := range lineages {
+		fillClassLineageMemberNames(lineage)
+	}
+
+	return lineages, nil
+}
+
+// GetClassLineageByID 通过ID获取班级沿革
+func GetClassLineageByID(id int) (*types.ClassLineage, error) {
+	db := database.GetDB()
+
+	var lineage types.ClassLineage
+	if err := db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("event_id ASC")
+	}).Preload("Members.Event").Preload("Members.Class").First(&lineage, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClassLineageNotFound
+		}
+		return nil, err
+	}
+
+	fillClassLineageMemberNames(&lineage)
+	return &lineage, nil
+}
+
+// CreateClassLineage 创建班级沿革
+func CreateClassLineage(lineage *types.ClassLineage) error {
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := validateClassLineage(tx, lineage); err != nil {
+			return err
+		}
+
+		members := lineage.Members
+		lineage.Members = nil
+		if err := tx.Create(lineage).Error; err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			member.ID = 0
+			member.LineageID = lineage.ID
+			if err := tx.Create(member).Error; err != nil {
+				return err
+			}
+		}
+		lineage.Members = members
+
+		return nil
+	})
+}
+
+// UpdateClassLineage 更新班级沿革（成员整体替换）
+func UpdateClassLineage(lineage *types.ClassLineage) error {
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.ClassLineage{}).Where("id = ?", lineage.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrClassLineageNotFound
+		}
+
+		if err := validateClassLineage(tx, lineage); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&types.ClassLineage{}).Where("id = ?", lineage.ID).Updates(map[string]interface{}{
+			"name":        lineage.Name,
+			"description": lineage.Description,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("lineage_id = ?", lineage.ID).Delete(&types.ClassLineageMember{}).Error; err != nil {
+			return err
+		}
+
+		for _, member := range lineage.Members {
+			member.ID = 0
+			member.LineageID = lineage.ID
+			if err := tx.Create(member).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteClassLineage 删除班级沿革
+func DeleteClassLineage(id int) error {
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("lineage_id = ?", id).Delete(&types.ClassLineageMember{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&types.ClassLineage{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrClassLineageNotFound
+		}
+		return nil
+	})
+}
+
+// validateClassLineage 验证班级沿革名称与成员
+func validateClassLineage(tx *gorm.DB, lineage *types.ClassLineage) error {
+	if lineage.Name == "" {
+		return errors.New("班级沿革名称不能为空")
+	}
+
+	// 检查名称是否重复
+	var count int64
+	if err := tx.Model(&types.ClassLineage{}).Where("name = ? AND id != ?", lineage.Name, lineage.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("班级沿革名称已存在")
+	}
+
+	seenEvents := make(map[int]bool)
+	for _, member := range lineage.Members {
+		if seenEvents[member.EventID] {
+			return errors.New("同一届次只能对应一个班级")
+		}
+		seenEvents[member.EventID] = true
+
+		var event types.Event
+		if err := tx.Select("id", "name").First(&event, member.EventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("运动会届次 %d 不存在", member.EventID)
+			}
+			return err
+		}
+
+		var class types.Class
+		if err := tx.First(&class, member.ClassID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("班级 %d 不存在", member.ClassID)
+			}
+			return err
+		}
+
+		// 同一届次中的班级只能属于一个沿革
+		var existing types.ClassLineageMember
+		err := tx.Where("event_id = ? AND class_id = ? AND lineage_id != ?", member.EventID, member.ClassID, lineage.ID).First(&existing).Error
+		if err == nil {
+			return fmt.Errorf("%s 在 %s 中已属于其他班级沿革", class.Name, event.Name)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fillClassLineageMemberNames 填充成员的届次名称与班级名称
+func fillClassLineageMemberNames(lineage *types.ClassLineage) {
+	for _, member := range lineage.Members {
+		if member.Event != nil {
+			member.EventName = member.Event.Name
+		}
+		if member.Class != nil {
+			member.ClassName = member.Class.Name
+		}
+	}
+}
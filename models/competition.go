@@ -12,7 +12,7 @@ import (
 )
 
 // CreateCompetition 创建比赛项目（学生提交）
-func CreateCompetition(name, description, imagePath, unit string, gender int, rankingMode types.RankingMode, competitionType types.CompetitionType, minParticipantsPerClass, maxParticipantsPerClass, submitterID int, startTime, endTime *time.Time) error {
+func CreateCompetition(name, description, imagePath, unit string, gender int, rankingMode types.RankingMode, competitionType types.CompetitionType, minParticipantsPerClass, maxParticipantsPerClass, submitterID int, startTime, endTime *time.Time, requiresGuardianConsent bool, venueID *int) error {
 	// 获取数据库连接和验证器
 	db := database.GetDB()
 	validator := utils.NewCompetitionValidator(db)
@@ -22,6 +22,11 @@ func CreateCompetition(name, description, imagePath, unit string, gender int, ra
 		return err
 	}
 
+	// 验证场地安排
+	if err := validator.ValidateVenueBooking(0, venueID, startTime, endTime); err != nil {
+		return err
+	}
+
 	// 获取当前选中的 EventID
 	cfg := config.Get()
 	currentEventID := cfg.CurrentEventID
@@ -42,11 +47,16 @@ func CreateCompetition(name, description, imagePath, unit string, gender int, ra
 		SubmitterID:             &submitterID,
 		StartTime:               startTime,
 		EndTime:                 endTime,
+		RequiresGuardianConsent: requiresGuardianConsent,
+		VenueID:                 venueID,
 	}
 
-	// 使用事务插入比赛数据
+	// 使用事务插入比赛数据，并保存首个提交版本
 	return db.Transaction(func(tx *gorm.DB) error {
-		return tx.Create(competition).Error
+		if err := tx.Create(competition).Error; err != nil {
+			return err
+		}
+		return createCompetitionRevision(tx, competition)
 	})
 }
 
@@ -65,9 +75,14 @@ func UpdateCompetition(competition *types.Competition) error {
 		return err
 	}
 
+	// 验证场地安排
+	if err := validator.ValidateVenueBooking(competition.ID, competition.VenueID, competition.StartTime, competition.EndTime); err != nil {
+		return err
+	}
+
 	// 使用事务更新比赛数据
 	err := db.Transaction(func(tx *gorm.DB) error {
-		return tx.Model(competition).Select("name", "description", "image_path", "unit", "gender", "ranking_mode", "competition_type", "min_participants_per_class", "max_participants_per_class", "start_time", "end_time").Updates(map[string]interface{}{
+		return tx.Model(competition).Select("name", "description", "image_path", "unit", "gender", "ranking_mode", "competition_type", "min_participants_per_class", "max_participants_per_class", "start_time", "end_time", "requires_guardian_consent", "venue_id").Updates(map[string]interface{}{
 			"name":                       competition.Name,
 			"description":                competition.Description,
 			"image_path":                 competition.ImagePath,
@@ -79,6 +94,8 @@ func UpdateCompetition(competition *types.Competition) error {
 			"max_participants_per_class": competition.MaxParticipantsPerClass,
 			"start_time":                 competition.StartTime,
 			"end_time":                   competition.EndTime,
+			"requires_guardian_consent":  competition.RequiresGuardianConsent,
+			"venue_id":                   competition.VenueID,
 		}).Error
 	})
 	if err != nil {
@@ -101,41 +118,183 @@ func ApproveCompetitionByID(id, reviewerID int) error {
 
 	// 使用验证器检查比赛项目是否存在
 	validator := utils.NewCompetitionValidator(db)
-	if _, err := validator.CheckCompetitionExists(id); err != nil {
+	competition, err := validator.CheckCompetitionExists(id)
+	if err != nil {
 		return err
 	}
 
-	// 更新比赛状态
+	// 更新比赛状态并记录状态变更
 	now := time.Now()
-	return db.Model(&types.Competition{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":      types.StatusApproved,
-		"reviewed_at": now,
-		"reviewer_id": reviewerID,
-	}).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.Competition{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":        types.StatusApproved,
+			"status_reason": "",
+			"reviewed_at":   now,
+			"reviewer_id":   reviewerID,
+		}).Error; err != nil {
+			return err
+		}
+		return recordStatusChange(tx, id, competition.Status, types.StatusApproved, "", reviewerID)
+	})
 }
 
 // RejectCompetitionByID 审核拒绝比赛项目
-func RejectCompetitionByID(id, reviewerID int) error {
+// requestChanges 为 true 时项目进入"需修改"状态，提交的学生可在征集时间内修改后重新提交
+func RejectCompetitionByID(id, reviewerID int, reason string, requestChanges bool) error {
 	// 获取数据库连接
 	db := database.GetDB()
 
 	// 使用验证器检查比赛项目是否存在
 	validator := utils.NewCompetitionValidator(db)
-	if _, err := validator.CheckCompetitionExists(id); err != nil {
+	competition, err := validator.CheckCompetitionExists(id)
+	if err != nil {
 		return err
 	}
 
-	// 更新比赛状态
+	status := types.StatusRejected
+	if requestChanges {
+		if reason == "" {
+			return utils.ErrChangesReasonRequired
+		}
+		status = types.StatusChangesRequested
+	}
+
+	// 更新比赛状态并记录状态变更
 	now := time.Now()
-	return db.Model(&types.Competition{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":      types.StatusRejected,
-		"reviewed_at": now,
-		"reviewer_id": reviewerID,
-	}).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.Competition{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":        status,
+			"status_reason": reason,
+			"reviewed_at":   now,
+			"reviewer_id":   reviewerID,
+		}).Error; err != nil {
+			return err
+		}
+		return recordStatusChange(tx, id, competition.Status, status, reason, reviewerID)
+	})
+}
+
+// ResubmitCompetitionProposal 学生修改并重新提交推荐项目
+// 仅待审核或需修改的项目可以修改，提交后项目回到待审核状态并保存新的提交版本
+func ResubmitCompetitionProposal(competition *types.Competition, studentID int) error {
+	// 获取数据库连接和验证器
+	db := database.GetDB()
+	validator := utils.NewCompetitionValidator(db)
+
+	var existing types.Competition
+	if err := db.First(&existing, competition.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrCompetitionNotFound
+		}
+		return err
+	}
+
+	if existing.SubmitterID == nil || *existing.SubmitterID != studentID {
+		return utils.ErrNotProposalSubmitter
+	}
+	if !utils.IsProposalEditable(existing.Status) {
+		return utils.ErrProposalNotEditable
+	}
+
+	competition.EventID = existing.EventID
+	competition.SubmitterID = existing.SubmitterID
+	if !utils.IsRankingModeValid(competition.RankingMode) {
+		competition.RankingMode = types.RankingHigherFirst // 默认为分数高的排名靠前
+	}
+	if err := validator.ValidateProposalResubmission(competition); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.Competition{}).Where("id = ?", competition.ID).Updates(map[string]interface{}{
+			"name":                       competition.Name,
+			"description":                competition.Description,
+			"image_path":                 competition.ImagePath,
+			"unit":                       competition.Unit,
+			"gender":                     competition.Gender,
+			"ranking_mode":               competition.RankingMode,
+			"competition_type":           competition.CompetitionType,
+			"min_participants_per_class": competition.MinParticipantsPerClass,
+			"max_participants_per_class": competition.MaxParticipantsPerClass,
+			"start_time":                 competition.StartTime,
+			"end_time":                   competition.EndTime,
+			"status":                     types.StatusPendingApproval,
+		}).Error; err != nil {
+			return err
+		}
+
+		if existing.Status != types.StatusPendingApproval {
+			if err := recordStatusChange(tx, competition.ID, existing.Status, types.StatusPendingApproval, "学生修改后重新提交", 0); err != nil {
+				return err
+			}
+		}
+
+		return createCompetitionRevision(tx, competition)
+	})
+}
+
+// createCompetitionRevision 保存推荐项目的提交版本
+func createCompetitionRevision(tx *gorm.DB, competition *types.Competition) error {
+	var count int64
+	if err := tx.Model(&types.CompetitionRevision{}).Where("competition_id = ?", competition.ID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	revision := &types.CompetitionRevision{
+		CompetitionID:           competition.ID,
+		Revision:                int(count) + 1,
+		Name:                    competition.Name,
+		Description:             competition.Description,
+		ImagePath:               competition.ImagePath,
+		RankingMode:             competition.RankingMode,
+		Unit:                    competition.Unit,
+		Gender:                  competition.Gender,
+		CompetitionType:         competition.CompetitionType,
+		MinParticipantsPerClass: competition.MinParticipantsPerClass,
+		MaxParticipantsPerClass: competition.MaxParticipantsPerClass,
+		StartTime:               competition.StartTime,
+		EndTime:                 competition.EndTime,
+		SubmitterID:             competition.SubmitterID,
+	}
+	return tx.Create(revision).Error
+}
+
+// GetCompetitionRevisions 获取推荐项目的提交版本记录（按版本号升序）
+func GetCompetitionRevisions(competitionID int) ([]*types.CompetitionRevision, error) {
+	db := database.GetDB()
+
+	var revisions []*types.CompetitionRevision
+	if err := db.Where("competition_id = ?", competitionID).Order("revision ASC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetStudentProposals 获取学生在当前届次提交或共同署名的推荐项目（包含所有状态）
+func GetStudentProposals(studentID int) ([]*types.Competition, error) {
+	db := database.GetDB()
+
+	var competitions []*types.Competition
+	if err := db.Preload("Reviewer").
+		Where("event_id = ? AND (submitter_id = ? OR id IN (?))", config.Get().CurrentEventID, studentID,
+			db.Model(&types.ProposalCredit{}).Select("competition_id").Where("student_id = ?", studentID)).
+		Order("id DESC").
+		Find(&competitions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, comp := range competitions {
+		if comp.Reviewer != nil {
+			comp.ReviewerName = comp.Reviewer.FullName
+		}
+	}
+
+	return competitions, nil
 }
 
 // AdminCreateCompetition 管理员创建比赛项目（不受时间限制）
-func AdminCreateCompetition(name, description, imagePath, unit string, gender int, rankingMode types.RankingMode, competitionType types.CompetitionType, minParticipantsPerClass, maxParticipantsPerClass, submitterID int, startTime, endTime *time.Time) error {
+func AdminCreateCompetition(name, description, imagePath, unit string, gender int, rankingMode types.RankingMode, competitionType types.CompetitionType, minParticipantsPerClass, maxParticipantsPerClass, submitterID int, startTime, endTime *time.Time, requiresGuardianConsent bool, venueID *int) error {
 	// 获取数据库连接和验证器
 	db := database.GetDB()
 	validator := utils.NewCompetitionValidator(db)
@@ -145,6 +304,11 @@ func AdminCreateCompetition(name, description, imagePath, unit string, gender in
 		return err
 	}
 
+	// 验证场地安排
+	if err := validator.ValidateVenueBooking(0, venueID, startTime, endTime); err != nil {
+		return err
+	}
+
 	// 获取当前选中的 EventID
 	cfg := config.Get()
 	currentEventID := cfg.CurrentEventID
@@ -167,6 +331,8 @@ func AdminCreateCompetition(name, description, imagePath, unit string, gender in
 		ReviewerID:              &submitterID,
 		StartTime:               startTime,
 		EndTime:                 endTime,
+		RequiresGuardianConsent: requiresGuardianConsent,
+		VenueID:                 venueID,
 	}
 
 	// 使用事务插入比赛数据
@@ -175,22 +341,25 @@ func AdminCreateCompetition(name, description, imagePath, unit string, gender in
 	})
 }
 
-// GetCompetitionByID 通过ID获取比赛项目
+// GetCompetitionByID 通过ID获取当前届次的比赛项目
 func GetCompetitionByID(id int) (*types.Competition, error) {
+	return GetEventCompetitionByID(config.Get().CurrentEventID, id)
+}
+
+// GetEventCompetitionByID 通过ID获取指定届次的比赛项目
+func GetEventCompetitionByID(eventID, id int) (*types.Competition, error) {
 	// 获取数据库连接
 	db := database.GetDB()
 
-	// 获取当前选中的 EventID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	// 查询比赛项目，包含关联数据
 	var comp types.Competition
 	err := db.Preload("Submitter.Class").
 		Preload("Reviewer").
 		Preload("ScoreSubmitter").
 		Preload("ScoreReviewer").
-		Where("event_id = ?", currentEventID).
+		Preload("Venue").
+		Preload("Credits.Student").
+		Where("event_id = ?", eventID).
 		First(&comp, id).Error
 
 	if err != nil {
@@ -218,6 +387,8 @@ func GetCompetitionByID(id int) (*types.Competition, error) {
 		comp.ScoreReviewerName = comp.ScoreReviewer.FullName
 	}
 
+	fillProposalCreditNames(comp.Credits)
+
 	// 获取报名数量
 	var registrationCount int64
 	if err := db.Model(&types.Registration{}).Where("competition_id = ?", id).Count(&registrationCount).Error; err != nil {
@@ -228,22 +399,20 @@ func GetCompetitionByID(id int) (*types.Competition, error) {
 	return &comp, nil
 }
 
-// GetAllCompetitions 获取所有比赛项目，支持分页和状态筛选
-func GetAllCompetitions(page, pageSize int, statuses []types.CompetitionStatus, gender int, sortBy string) ([]*types.Competition, int, error) {
+// GetAllCompetitions 获取指定届次的所有比赛项目，支持分页和状态筛选
+// competitionIDs 不为 nil 时仅返回其中的比赛项目（用于裁判账号）
+func GetAllCompetitions(eventID, page, pageSize int, statuses []types.CompetitionStatus, gender int, sortBy string, competitionIDs *[]int) ([]*types.Competition, int, error) {
 	// 获取数据库连接
 	db := database.GetDB()
 
-	// 获取当前选中的 EventID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	// 构建基础查询
 	query := db.Model(&types.Competition{}).
-		Where("event_id = ?", currentEventID).
+		Where("event_id = ?", eventID).
 		Preload("Submitter.Class").
 		Preload("Reviewer").
 		Preload("ScoreSubmitter").
-		Preload("ScoreReviewer")
+		Preload("ScoreReviewer").
+		Preload("Venue")
 
 	// 添加过滤条件
 	if gender > 0 {
@@ -254,15 +423,22 @@ func GetAllCompetitions(page, pageSize int, statuses []types.CompetitionStatus,
 		query = query.Where("status IN ?", statuses)
 	}
 
+	if competitionIDs != nil {
+		query = query.Where("id IN ?", *competitionIDs)
+	}
+
 	// 获取总数
 	var total int64
-	countQuery := db.Model(&types.Competition{}).Where("event_id = ?", currentEventID)
+	countQuery := db.Model(&types.Competition{}).Where("event_id = ?", eventID)
 	if gender > 0 {
 		countQuery = countQuery.Where("gender = ? OR gender = 3", gender)
 	}
 	if len(statuses) > 0 {
 		countQuery = countQuery.Where("status IN ?", statuses)
 	}
+	if competitionIDs != nil {
+		countQuery = countQuery.Where("id IN ?", *competitionIDs)
+	}
 	if err := countQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -346,22 +522,51 @@ func DeleteCompetition(id int) error {
 			return err
 		}
 
+		// 删除相关的家长确认记录
+		if err := tx.Where("competition_id = ?", id).Delete(&types.RegistrationConsent{}).Error; err != nil {
+			return err
+		}
+
+		// 删除相关的状态变更记录
+		if err := tx.Where("competition_id = ?", id).Delete(&types.CompetitionStatusLog{}).Error; err != nil {
+			return err
+		}
+
+		// 删除相关的日程草稿
+		if err := tx.Where("competition_id = ?", id).Delete(&types.ScheduleEntry{}).Error; err != nil {
+			return err
+		}
+
+		// 删除相关的提交版本记录
+		if err := tx.Where("competition_id = ?", id).Delete(&types.CompetitionRevision{}).Error; err != nil {
+			return err
+		}
+
+		// 删除指派的裁判
+		if err := tx.Where("competition_id = ?", id).Delete(&types.CompetitionOfficial{}).Error; err != nil {
+			return err
+		}
+
+		// 删除相关的共同署名记录，并清除被合并项目的合并去向
+		if err := tx.Where("competition_id = ?", id).Delete(&types.ProposalCredit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&types.Competition{}).Where("merged_into_id = ?", id).Update("merged_into_id", nil).Error; err != nil {
+			return err
+		}
+
 		// 删除比赛项目
 		return tx.Delete(&types.Competition{}, id).Error
 	})
 }
 
 // 获取最新完成的比赛
-func getLatestCompletedCompetition() (*types.Competition, error) {
+func getLatestCompletedCompetition(eventID int) (*types.Competition, error) {
 	// 获取数据库连接
 	db := database.GetDB()
 
-	// 获取当前选中的 EventID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	var comp types.Competition
-	err := db.Where("status = ? AND event_id = ?", types.StatusCompleted, currentEventID).
+	err := db.Where("status = ? AND event_id = ?", types.StatusCompleted, eventID).
 		Order("score_reviewed_at DESC").
 		First(&comp).Error
 
@@ -372,5 +577,5 @@ func getLatestCompletedCompetition() (*types.Competition, error) {
 		return nil, err
 	}
 
-	return GetCompetitionByID(comp.ID)
+	return GetEventCompetitionByID(eventID, comp.ID)
 }
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidOfficialRole        = errors.New("无效的裁判角色")
+	ErrOfficialUserNotFound       = errors.New("指派的用户不存在")
+	ErrOfficialPermissionMismatch = errors.New("指派的用户没有该裁判角色所需的权限")
+	ErrNotCompetitionOfficial     = errors.New("您未被指派负责该比赛项目")
+)
+
+// officialRolePermission 裁判角色所需的权限
+func officialRolePermission(role types.OfficialRole) (int, bool) {
+	switch role {
+	case types.OfficialRoleScorer, types.OfficialRoleStarter:
+		return utils.PermissionScoreInput, true
+	case types.OfficialRoleReviewer:
+		return utils.PermissionScoreReview, true
+	default:
+		return 0, false
+	}
+}
+
+// GetCompetitionOfficials 获取比赛项目指派的裁判
+func GetCompetitionOfficials(competitionID int) ([]*types.CompetitionOfficial, error) {
+	db := database.GetDB()
+
+	var officials []*types.CompetitionOfficial
+	if err := db.Preload("User").
+		Where("competition_id = ?", competitionID).
+		Order("role ASC, id ASC").
+		Find(&officials).Error; err != nil {
+		return nil, err
+	}
+
+	for _, official := range officials {
+		if official.User != nil {
+			official.UserName = official.User.FullName
+		}
+	}
+
+	return officials, nil
+}
+
+// SetCompetitionOfficials 替换比赛项目指派的裁判
+func SetCompetitionOfficials(competitionID int, officials []*types.CompetitionOfficial) error {
+	db := database.GetDB()
+
+	if _, err := utils.NewCompetitionValidator(db).CheckCompetitionExists(competitionID); err != nil {
+		return err
+	}
+
+	// 验证角色与用户权限，并去除重复的指派
+	type officialKey struct {
+		userID int
+		role   types.OfficialRole
+	}
+	seen := make(map[officialKey]bool, len(officials))
+	assignments := make([]*types.CompetitionOfficial, 0, len(officials))
+	for _, official := range officials {
+		permission, ok := officialRolePermission(official.Role)
+		if !ok {
+			return ErrInvalidOfficialRole
+		}
+
+		var user types.User
+		if err := db.Select("id", "permission").First(&user, official.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOfficialUserNotFound
+			}
+			return err
+		}
+		if !utils.HasPermission(user.Permission, permission) {
+			return ErrOfficialPermissionMismatch
+		}
+
+		key := officialKey{official.UserID, official.Role}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		assignments = append(assignments, &types.CompetitionOfficial{
+			CompetitionID: competitionID,
+			UserID:        official.UserID,
+			Role:          official.Role,
+		})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("competition_id = ?", competitionID).Delete(&types.CompetitionOfficial{}).Error; err != nil {
+			return err
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+		return tx.Create(&assignments).Error
+	})
+}
+
+// GetOfficialCompetitionScope 获取用户可处理的比赛项目ID范围
+// 非裁判账号返回 nil，表示不限制；裁判账号返回以指定角色被指派的比赛项目ID
+func GetOfficialCompetitionScope(user *types.User, roles ...types.OfficialRole) (*[]int, error) {
+	if !user.CompetitionScoped {
+		return nil, nil
+	}
+
+	db := database.GetDB()
+
+	competitionIDs := []int{}
+	if err := db.Model(&types.CompetitionOfficial{}).
+		Where("user_id = ? AND role IN ?", user.ID, roles).
+		Distinct().
+		Pluck("competition_id", &competitionIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return &competitionIDs, nil
+}
+
+// CanOfficiateCompetition 检查用户是否可以以指定角色处理比赛项目
+func CanOfficiateCompetition(user *types.User, competitionID int, roles ...types.OfficialRole) (bool, error) {
+	if !user.CompetitionScoped {
+		return true, nil
+	}
+
+	db := database.GetDB()
+
+	var count int64
+	if err := db.Model(&types.CompetitionOfficial{}).
+		Where("user_id = ? AND competition_id = ? AND role IN ?", user.ID, competitionID, roles).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+// recordStatusChange 记录比赛状态变更
+// changedBy: 操作人ID，为0表示系统操作
+func recordStatusChange(tx *gorm.DB, competitionID int, from, to types.CompetitionStatus, reason string, changedBy int) error {
+	return tx.Create(newStatusLog(competitionID, from, to, reason, changedBy)).Error
+}
+
+// newStatusLog 构建比赛状态变更记录，状态变更同时调整比赛时间时由调用方补充时间后保存
+func newStatusLog(competitionID int, from, to types.CompetitionStatus, reason string, changedBy int) *types.CompetitionStatusLog {
+	log := &types.CompetitionStatusLog{
+		CompetitionID: competitionID,
+		FromStatus:    from,
+		ToStatus:      to,
+		Reason:        reason,
+	}
+	if changedBy > 0 {
+		log.ChangedByID = &changedBy
+	}
+	return log
+}
+
+// ChangeCompetitionStatus 手动变更比赛状态（进行中、延期、取消、恢复）
+// startTime, endTime: 新的比赛时间，为nil表示不修改
+// 取消比赛不会删除报名记录，以便留档
+func ChangeCompetitionStatus(id int, to types.CompetitionStatus, reason string, startTime, endTime *time.Time, changedBy int) error {
+	db := database.GetDB()
+
+	if (to == types.StatusPostponed || to == types.StatusCancelled) && reason == "" {
+		return utils.ErrStatusReasonRequired
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var competition types.Competition
+		if err := tx.Where("event_id = ?", config.Get().CurrentEventID).First(&competition, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrCompetitionNotFound
+			}
+			return err
+		}
+
+		if !utils.IsStatusTransitionAllowed(competition.Status, to) {
+			return utils.ErrInvalidStatusTransition
+		}
+
+		updates := map[string]interface{}{
+			"status":        to,
+			"status_reason": reason,
+		}
+
+		log := newStatusLog(id, competition.Status, to, reason, changedBy)
+
+		// 更新比赛时间
+		if startTime != nil || endTime != nil {
+			newStart, newEnd := competition.StartTime, competition.EndTime
+			if startTime != nil {
+				newStart = startTime
+			}
+			if endTime != nil {
+				newEnd = endTime
+			}
+			if err := utils.ValidateCompetitionTime(newStart, newEnd); err != nil {
+				return err
+			}
+			if err := utils.NewCompetitionValidator(tx).ValidateVenueBooking(id, competition.VenueID, newStart, newEnd); err != nil {
+				return err
+			}
+
+			updates["start_time"] = newStart
+			updates["end_time"] = newEnd
+			log.OldStartTime = competition.StartTime
+			log.OldEndTime = competition.EndTime
+			log.NewStartTime = newStart
+			log.NewEndTime = newEnd
+		}
+
+		if err := tx.Model(&types.Competition{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(log).Error
+	})
+}
+
+// GetCompetitionStatusLogs 获取比赛状态变更记录
+func GetCompetitionStatusLogs(competitionID int) ([]*types.CompetitionStatusLog, error) {
+	db := database.GetDB()
+
+	var logs []*types.CompetitionStatusLog
+	if err := db.Preload("ChangedBy").Where("competition_id = ?", competitionID).Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	for _, log := range logs {
+		if log.ChangedBy != nil {
+			log.ChangedByName = log.ChangedBy.FullName
+		}
+	}
+	return logs, nil
+}
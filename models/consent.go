@@ -0,0 +1,304 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+// 家长确认相关的错误定义
+var (
+	ErrConsentNotFound       = errors.New("家长确认记录不存在")
+	ErrConsentAlreadyHandled = errors.New("该报名已处理，无需重复操作")
+	ErrConsentExpired        = errors.New("确认链接已过期")
+)
+
+// createRegistrationConsent 创建等待家长确认的报名记录
+func createRegistrationConsent(tx *gorm.DB, studentID, competitionID int) (*types.RegistrationConsent, error) {
+	// 生成确认令牌
+	token, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算过期时间
+	expireHours := config.Get().Consent.ExpireHours
+	if expireHours <= 0 {
+		expireHours = 72
+	}
+
+	consent := &types.RegistrationConsent{
+		StudentID:     studentID,
+		CompetitionID: competitionID,
+		Token:         token,
+		Status:        types.ConsentPending,
+		ExpiresAt:     time.Now().Add(time.Duration(expireHours) * time.Hour),
+	}
+	if err := tx.Create(consent).Error; err != nil {
+		return nil, err
+	}
+
+	return consent, nil
+}
+
+// fillConsentNames 填充确认记录的学生、班级和比赛名称
+func fillConsentNames(consents []*types.RegistrationConsent) {
+	for _, consent := range consents {
+		if consent.Student != nil {
+			consent.StudentName = consent.Student.FullName
+			consent.ClassName = consent.Student.Class.Name
+		}
+		if consent.Competition.ID > 0 {
+			consent.CompetitionName = consent.Competition.Name
+		}
+	}
+}
+
+// GetConsentByID 通过ID获取家长确认记录
+func GetConsentByID(id int) (*types.RegistrationConsent, error) {
+	db := database.GetDB()
+
+	var consent types.RegistrationConsent
+	if err := db.Preload("Student.Class").Preload("Competition").First(&consent, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConsentNotFound
+		}
+		return nil, err
+	}
+
+	fillConsentNames([]*types.RegistrationConsent{&consent})
+	return &consent, nil
+}
+
+// GetConsentByToken 通过确认令牌获取家长确认记录
+func GetConsentByToken(token string) (*types.RegistrationConsent, error) {
+	db := database.GetDB()
+
+	var consent types.RegistrationConsent
+	if err := db.Preload("Student.Class").Preload("Competition").Where("token = ?", token).First(&consent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConsentNotFound
+		}
+		return nil, err
+	}
+
+	fillConsentNames([]*types.RegistrationConsent{&consent})
+	return &consent, nil
+}
+
+// GetConsentsByStudentID 获取学生在当前运动会的家长确认记录
+func GetConsentsByStudentID(studentID int) ([]*types.RegistrationConsent, error) {
+	db := database.GetDB()
+
+	var consents []*types.RegistrationConsent
+	err := db.Preload("Student.Class").Preload("Competition").
+		Joins("JOIN competitions ON competitions.id = registration_consents.competition_id").
+		Where("registration_consents.student_id = ? AND competitions.event_id = ?", studentID, config.Get().CurrentEventID).
+		Order("registration_consents.created_at DESC").
+		Find(&consents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	fillConsentNames(consents)
+	return consents, nil
+}
+
+// GetConsentsByStudentIDs 获取多个学生在当前运动会的家长确认记录（家长端使用）
+func GetConsentsByStudentIDs(studentIDs []int) ([]*types.RegistrationConsent, error) {
+	db := database.GetDB()
+
+	consents := []*types.RegistrationConsent{}
+	if len(studentIDs) == 0 {
+		return consents, nil
+	}
+	err := db.Preload("Student.Class").Preload("Competition").
+		Joins("JOIN competitions ON competitions.id = registration_consents.competition_id").
+		Where("registration_consents.student_id IN ? AND competitions.event_id = ?", studentIDs, config.Get().CurrentEventID).
+		Order("registration_consents.created_at DESC").
+		Find(&consents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	fillConsentNames(consents)
+	return consents, nil
+}
+
+// GetConsents 获取当前运动会的家长确认记录（支持班级scope和状态筛选）
+// scopeClassIDs: 可选的班级ID列表，如果为nil，则返回所有班级的记录
+func GetConsents(status types.ConsentStatus, scopeClassIDs *[]int) ([]*types.RegistrationConsent, error) {
+	db := database.GetDB()
+
+	query := db.Preload("Student.Class").Preload("Competition").
+		Joins("JOIN competitions ON competitions.id = registration_consents.competition_id").
+		Where("competitions.event_id = ?", config.Get().CurrentEventID)
+
+	if status != "" {
+		query = query.Where("registration_consents.status = ?", status)
+	}
+
+	// 如果提供了scopeClassIDs，应用班级scope过滤
+	if scopeClassIDs != nil {
+		if len(*scopeClassIDs) == 0 {
+			return []*types.RegistrationConsent{}, nil
+		}
+		query = query.Joins("JOIN students ON students.id = registration_consents.student_id").
+			Where("students.class_id IN ?", *scopeClassIDs)
+	}
+
+	var consents []*types.RegistrationConsent
+	if err := query.Order("registration_consents.created_at DESC").Find(&consents).Error; err != nil {
+		return nil, err
+	}
+
+	fillConsentNames(consents)
+	return consents, nil
+}
+
+// ApproveConsent 同意报名，生成正式的报名记录
+// respondedBy: 处理人，格式见 types.RegistrationConsent.RespondedBy
+// 确认期间参赛资格和报名人数可能已发生变化，同意时按学生报名的规则重新校验
+// 报名时间内提交的报名在报名截止后仍可在确认有效期内同意
+func ApproveConsent(id int, respondedBy string) error {
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		consent, err := respondConsent(tx, id, types.ConsentApproved, respondedBy)
+		if err != nil {
+			return err
+		}
+		if time.Now().After(consent.ExpiresAt) {
+			return ErrConsentExpired
+		}
+
+		// 确认记录已不再处于待确认状态，不会被视为重复报名
+		validator := utils.NewRegistrationValidator(tx)
+		if err := validator.ValidateConsentApproval(consent.StudentID, consent.CompetitionID, consent.CreatedAt); err != nil {
+			return err
+		}
+
+		// 获取学生班级
+		var student types.Student
+		if err := tx.Select("id", "class_id").First(&student, consent.StudentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrStudentNotFound
+			}
+			return err
+		}
+
+		// 学生直接报名超出每班人数上限时仅提示，家长确认时无人能看到提示，因此不允许超出
+		if err := validator.CheckClassParticipantLimit(student.ClassID, consent.CompetitionID); err != nil {
+			return err
+		}
+
+		// 创建正式报名记录
+		registration := &types.Registration{
+			StudentID:     &student.ID,
+			ClassID:       &student.ClassID,
+			CompetitionID: consent.CompetitionID,
+		}
+		return tx.Create(registration).Error
+	})
+}
+
+// RejectConsent 拒绝报名
+// respondedBy: 处理人，格式见 types.RegistrationConsent.RespondedBy
+func RejectConsent(id int, respondedBy string) error {
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		consent, err := respondConsent(tx, id, types.ConsentRejected, respondedBy)
+		if err != nil {
+			return err
+		}
+		if time.Now().After(consent.ExpiresAt) {
+			return ErrConsentExpired
+		}
+		return nil
+	})
+}
+
+// respondConsent 将待确认记录更新为已处理状态并返回更新前的记录
+// 仅更新仍处于待确认状态的记录，防止同一记录被并发处理两次
+func respondConsent(tx *gorm.DB, id int, status types.ConsentStatus, respondedBy string) (*types.RegistrationConsent, error) {
+	var consent types.RegistrationConsent
+	if err := tx.First(&consent, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConsentNotFound
+		}
+		return nil, err
+	}
+	if consent.Status != types.ConsentPending {
+		return nil, ErrConsentAlreadyHandled
+	}
+
+	result := tx.Model(&types.RegistrationConsent{}).
+		Where("id = ? AND status = ?", id, types.ConsentPending).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"responded_by": respondedBy,
+			"responded_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrConsentAlreadyHandled
+	}
+
+	return &consent, nil
+}
+
+// cancelPendingConsent 取消等待家长确认的报名（学生取消报名时使用）
+func cancelPendingConsent(tx *gorm.DB, studentID, competitionID int) (int64, error) {
+	result := tx.Where("student_id = ? AND competition_id = ? AND status = ?", studentID, competitionID, types.ConsentPending).
+		Delete(&types.RegistrationConsent{})
+	return result.RowsAffected, result.Error
+}
+
+// ExpireOverdueConsents 将已过期的待确认记录标记为过期
+func ExpireOverdueConsents() (int64, error) {
+	db := database.GetDB()
+
+	result := db.Model(&types.RegistrationConsent{}).
+		Where("status = ? AND expires_at < ?", types.ConsentPending, time.Now()).
+		Update("status", types.ConsentExpired)
+	return result.RowsAffected, result.Error
+}
+
+// GetConsentsDueForReminder 获取需要再次提醒家长的待确认记录
+func GetConsentsDueForReminder(interval time.Duration, maxReminders int) ([]*types.RegistrationConsent, error) {
+	db := database.GetDB()
+
+	var consents []*types.RegistrationConsent
+	err := db.Preload("Student.Class").Preload("Competition").
+		Where("status = ? AND expires_at > ? AND reminder_count < ? AND (last_notified_at IS NULL OR last_notified_at < ?)",
+			types.ConsentPending, time.Now(), maxReminders, time.Now().Add(-interval)).
+		Find(&consents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	fillConsentNames(consents)
+	return consents, nil
+}
+
+// MarkConsentNotified 记录已通知家长
+// isReminder: 是否为提醒（首次通知不计入提醒次数）
+func MarkConsentNotified(id int, isReminder bool) error {
+	db := database.GetDB()
+
+	updates := map[string]interface{}{
+		"last_notified_at": time.Now(),
+	}
+	if isReminder {
+		updates["reminder_count"] = gorm.Expr("reminder_count + 1")
+	}
+	return db.Model(&types.RegistrationConsent{}).Where("id = ?", id).Updates(updates).Error
+}
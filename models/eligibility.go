@@ -0,0 +1,146 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+// CreateEligibility 为学生添加参赛资格限制记录
+func CreateEligibility(eligibility *types.StudentEligibility) error {
+	db := database.GetDB()
+
+	if !utils.IsEligibilityTypeValid(eligibility.Type) {
+		return utils.ErrInvalidEligibilityType
+	}
+	if err := utils.ValidateCompetitionTime(eligibility.StartDate, eligibility.EndDate); err != nil {
+		return err
+	}
+	if eligibility.Categories == "" {
+		eligibility.Categories = types.EligibilityCategoryAll
+	}
+	if err := utils.ValidateEligibilityCategories(eligibility.Categories); err != nil {
+		return err
+	}
+
+	return db.Create(eligibility).Error
+}
+
+// GetEligibilityByID 通过ID获取参赛资格限制记录
+func GetEligibilityByID(id int) (*types.StudentEligibility, error) {
+	db := database.GetDB()
+
+	var eligibility types.StudentEligibility
+	if err := db.First(&eligibility, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("参赛资格限制记录不存在")
+		}
+		return nil, err
+	}
+
+	return &eligibility, nil
+}
+
+// GetEligibilitiesByStudentID 获取学生的所有参赛资格限制记录
+func GetEligibilitiesByStudentID(studentID int) ([]*types.StudentEligibility, error) {
+	db := database.GetDB()
+
+	var eligibilities []*types.StudentEligibility
+	if err := db.Where("student_id = ?", studentID).Order("created_at DESC").Find(&eligibilities).Error; err != nil {
+		return nil, err
+	}
+
+	return eligibilities, nil
+}
+
+// UpdateEligibility 更新参赛资格限制记录
+func UpdateEligibility(eligibility *types.StudentEligibility) error {
+	db := database.GetDB()
+
+	if !utils.IsEligibilityTypeValid(eligibility.Type) {
+		return utils.ErrInvalidEligibilityType
+	}
+	if err := utils.ValidateCompetitionTime(eligibility.StartDate, eligibility.EndDate); err != nil {
+		return err
+	}
+	if eligibility.Categories == "" {
+		eligibility.Categories = types.EligibilityCategoryAll
+	}
+	if err := utils.ValidateEligibilityCategories(eligibility.Categories); err != nil {
+		return err
+	}
+
+	return db.Model(eligibility).Select("type", "start_date", "end_date", "categories", "note").Updates(map[string]interface{}{
+		"type":       eligibility.Type,
+		"start_date": eligibility.StartDate,
+		"end_date":   eligibility.EndDate,
+		"categories": eligibility.Categories,
+		"note":       eligibility.Note,
+	}).Error
+}
+
+// DeleteEligibility 删除参赛资格限制记录
+func DeleteEligibility(id int) error {
+	db := database.GetDB()
+
+	result := db.Delete(&types.StudentEligibility{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("参赛资格限制记录不存在")
+	}
+
+	return nil
+}
+
+// copyEligibilityCategories 复制比赛项目后，将仍有效的限制记录中指向原项目的类别同时指向复制出的新项目
+// idMap: 原项目ID到新项目ID的映射
+func copyEligibilityCategories(tx *gorm.DB, idMap map[int]int) error {
+	if len(idMap) == 0 {
+		return nil
+	}
+
+	var eligibilities []*types.StudentEligibility
+	if err := tx.Where("end_date IS NULL OR end_date >= ?", time.Now()).Find(&eligibilities).Error; err != nil {
+		return err
+	}
+
+	for _, eligibility := range eligibilities {
+		categories := utils.SplitEligibilityCategories(eligibility.Categories)
+		existing := make(map[string]bool, len(categories))
+		for _, category := range categories {
+			existing[category] = true
+		}
+
+		changed := false
+		for _, category := range categories {
+			id, err := strconv.Atoi(category)
+			if err != nil {
+				continue
+			}
+			newID, ok := idMap[id]
+			if !ok || existing[strconv.Itoa(newID)] {
+				continue
+			}
+			categories = append(categories, strconv.Itoa(newID))
+			existing[strconv.Itoa(newID)] = true
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		if err := tx.Model(eligibility).Update("categories", strings.Join(categories, ",")).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
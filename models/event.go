@@ -2,12 +2,20 @@ package models
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/database"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"gorm.io/gorm"
 )
 
+// uploadDir 上传文件的保存目录
+const uploadDir = "./data/uploads"
+
 // CreateEvent 创建运动会届次
 func CreateEvent(name string) (*types.Event, error) {
 	db := database.GetDB()
@@ -21,8 +29,15 @@ func CreateEvent(name string) (*types.Event, error) {
 		return nil, errors.New("运动会届次名称已存在")
 	}
 
+	// 新届次沿用当前届次的设置，管理员可再按需调整
+	settings, err := utils.LoadEventSettings(db, config.Get().CurrentEventID)
+	if err != nil {
+		return nil, err
+	}
+
 	event := &types.Event{
-		Name: name,
+		Name:     name,
+		Settings: *settings,
 	}
 
 	if err := db.Create(event).Error; err != nil {
@@ -76,6 +91,11 @@ func DeleteEvent(id int, currentEventID int) error {
 		return errors.New("该运动会届次下存在比赛项目，无法删除")
 	}
 
+	// 移除班级沿革中该届次的对应关系
+	if err := db.Where("event_id = ?", id).Delete(&types.ClassLineageMember{}).Error; err != nil {
+		return err
+	}
+
 	// 删除 Event
 	result := db.Delete(&types.Event{}, id)
 	if result.Error != nil {
@@ -115,3 +135,211 @@ func GetAllEvents() ([]*types.Event, error) {
 
 	return events, nil
 }
+
+// CloneCompetitionsFromEvent 将往届运动会的比赛项目复制到当前运动会（状态为审核通过）
+// competitionIDs: 要复制的比赛ID，为空时复制往届所有已完成的比赛
+// targetStartDate: 新的比赛开始日期，往届最早的比赛日期将平移到该日期，其余比赛按相同天数平移；为nil时不设置比赛时间
+// 报名、成绩、投票记录不会被复制；与当前运动会已有项目同名的比赛将被跳过
+// 图片复制为新文件，避免与往届比赛共用同一文件；场地仅在新的比赛时间可以使用时保留，否则需重新安排
+// 按比赛项目ID设置的参赛资格限制会同时适用于复制出的新项目
+func CloneCompetitionsFromEvent(sourceEventID int, competitionIDs []int, targetStartDate *time.Time, operatorID int) ([]*types.Competition, []string, error) {
+	db := database.GetDB()
+	currentEventID := config.Get().CurrentEventID
+
+	if sourceEventID == currentEventID {
+		return nil, nil, errors.New("不能从当前运动会届次复制比赛项目")
+	}
+	if _, err := GetEventByID(sourceEventID); err != nil {
+		return nil, nil, err
+	}
+
+	// 获取要复制的比赛项目
+	var sources []*types.Competition
+	query := db.Where("event_id = ?", sourceEventID)
+	if len(competitionIDs) > 0 {
+		query = query.Where("id IN ?", competitionIDs)
+	} else {
+		query = query.Where("status = ?", types.StatusCompleted)
+	}
+	if err := query.Order("start_time ASC, id ASC").Find(&sources).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(sources) == 0 {
+		return nil, nil, errors.New("没有可复制的比赛项目")
+	}
+
+	// 计算时间平移量：往届最早的比赛日期对齐到新的开始日期
+	var offset time.Duration
+	if targetStartDate != nil {
+		var earliest *time.Time
+		for _, source := range sources {
+			if source.StartTime != nil && (earliest == nil || source.StartTime.Before(*earliest)) {
+				earliest = source.StartTime
+			}
+		}
+		if earliest != nil {
+			earliestDate := time.Date(earliest.Year(), earliest.Month(), earliest.Day(), 0, 0, 0, 0, earliest.Location())
+			targetDate := time.Date(targetStartDate.Year(), targetStartDate.Month(), targetStartDate.Day(), 0, 0, 0, 0, earliest.Location())
+			offset = targetDate.Sub(earliestDate)
+		}
+	}
+
+	shiftTime := func(t *time.Time) *time.Time {
+		if t == nil || targetStartDate == nil {
+			return nil
+		}
+		shifted := t.Add(offset)
+		return &shifted
+	}
+
+	cloned := []*types.Competition{}
+	skipped := []string{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		idMap := make(map[int]int, len(sources))
+		for _, source := range sources {
+			// 跳过当前运动会已存在的同名项目
+			var count int64
+			if err := tx.Model(&types.Competition{}).Where("name = ? AND event_id = ?", source.Name, currentEventID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				skipped = append(skipped, source.Name)
+				continue
+			}
+
+			competition := &types.Competition{
+				EventID:                 currentEventID,
+				Name:                    source.Name,
+				Description:             source.Description,
+				Unit:                    source.Unit,
+				Gender:                  source.Gender,
+				RankingMode:             source.RankingMode,
+				CompetitionType:         source.CompetitionType,
+				MinParticipantsPerClass: source.MinParticipantsPerClass,
+				MaxParticipantsPerClass: source.MaxParticipantsPerClass,
+				RequiresGuardianConsent: source.RequiresGuardianConsent,
+				Status:                  types.StatusApproved,
+				ReviewedAt:              &now,
+				ReviewerID:              &operatorID,
+				StartTime:               shiftTime(source.StartTime),
+				EndTime:                 shiftTime(source.EndTime),
+			}
+
+			// 场地在新的比赛时间被占用或不开放时不保留
+			if source.VenueID != nil {
+				err := utils.NewCompetitionValidator(tx).ValidateVenueBooking(0, source.VenueID, competition.StartTime, competition.EndTime)
+				if err == nil {
+					competition.VenueID = source.VenueID
+				} else if !errors.Is(err, utils.ErrVenueNotFound) && !errors.Is(err, utils.ErrVenueClosed) && !errors.Is(err, utils.ErrVenueDoubleBooked) {
+					return err
+				}
+			}
+
+			if err := tx.Create(competition).Error; err != nil {
+				return err
+			}
+
+			// 复制图片文件，往届比赛的图片文件丢失时不设置图片
+			if source.ImagePath != "" {
+				fileName, err := utils.CopyImage(source.ImagePath, uploadDir, "competition_clone", competition.ID)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				if fileName != "" {
+					competition.ImagePath = filepath.Join("/uploads", fileName)
+					if err := tx.Model(competition).Update("image_path", competition.ImagePath).Error; err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := recordStatusChange(tx, competition.ID, "", types.StatusApproved, "从往届运动会复制", operatorID); err != nil {
+				return err
+			}
+			cloned = append(cloned, competition)
+			idMap[source.ID] = competition.ID
+		}
+
+		// 按项目ID设置的参赛资格限制同样适用于复制出的项目
+		return copyEligibilityCategories(tx, idMap)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cloned, skipped, nil
+}
+
+// GetCompetitionsByEventID 获取指定运动会届次的比赛项目（用于选择要复制的项目）
+func GetCompetitionsByEventID(eventID int) ([]*types.Competition, error) {
+	db := database.GetDB()
+
+	var competitions []*types.Competition
+	if err := db.Where("event_id = ?", eventID).Order("start_time ASC, id ASC").Find(&competitions).Error; err != nil {
+		return nil, err
+	}
+
+	return competitions, nil
+}
+
+// GetEventSettings 获取运动会届次设置
+func GetEventSettings(eventID int) (*types.EventSettings, error) {
+	return utils.LoadEventSettings(database.GetDB(), eventID)
+}
+
+// GetEventSettingsByCompetitionID 获取比赛所属运动会届次的设置
+func GetEventSettingsByCompetitionID(competitionID int) (*types.EventSettings, error) {
+	db := database.GetDB()
+
+	var competition types.Competition
+	if err := db.Select("id", "event_id").First(&competition, competitionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrCompetitionNotFound
+		}
+		return nil, err
+	}
+
+	return utils.LoadEventSettings(db, competition.EventID)
+}
+
+// UpdateEventSettings 更新运动会届次设置，并重新计算该届次所有比赛的得分
+func UpdateEventSettings(eventID int, settings *types.EventSettings) error {
+	db := database.GetDB()
+
+	if _, err := GetEventByID(eventID); err != nil {
+		return err
+	}
+
+	if err := utils.ValidateVotingRules(&settings.VotingRules); err != nil {
+		return err
+	}
+
+	// 设置与得分在同一事务中更新，重新计算失败时设置同样不生效
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.Event{ID: eventID}).Select(
+			"submission_start_time", "submission_end_time",
+			"voting_start_time", "voting_end_time",
+			"registration_start_time", "registration_end_time",
+			"max_registrations_per_person",
+			"max_upvotes_per_student", "disable_downvotes",
+			"shortlist_mode", "shortlist_top_k", "shortlist_threshold",
+			"team_points_mapping", "individual_points_mapping",
+		).Updates(&types.Event{Settings: *settings}).Error; err != nil {
+			return err
+		}
+
+		// 仅重新计算该届次的比赛得分，其他届次不受影响
+		var competitionIDs []int
+		if err := tx.Model(&types.Competition{}).Where("event_id = ?", eventID).Pluck("id", &competitionIDs).Error; err != nil {
+			return err
+		}
+		for _, competitionID := range competitionIDs {
+			if err := recalculatePoints(tx, competitionID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,235 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountLocked         = errors.New("登录失败次数过多，账号已被临时锁定")
+	ErrIPLocked              = errors.New("该IP登录失败次数过多，已被临时禁止登录")
+	ErrLoginTooFrequent      = errors.New("登录尝试过于频繁")
+	ErrLoginThrottleNotFound = errors.New("登录限制记录不存在")
+)
+
+// loginThrottlePolicy 登录限制策略
+type loginThrottlePolicy struct {
+	FreeAttempts int           // 无需等待的失败次数，超过后每次失败需等待的时间翻倍
+	LockAttempts int           // 达到该失败次数后锁定
+	LockDuration time.Duration // 锁定时长
+}
+
+// loginThrottlePolicies 账号与IP的登录限制策略
+// 同一IP下可能有整个学校的学生（共用出口），IP的限制较账号宽松
+var loginThrottlePolicies = map[string]loginThrottlePolicy{
+	types.LoginThrottleUsername: {FreeAttempts: 3, LockAttempts: 10, LockDuration: 15 * time.Minute},
+	types.LoginThrottleIP:       {FreeAttempts: 20, LockAttempts: 100, LockDuration: 15 * time.Minute},
+}
+
+const (
+	// loginFailureWindow 距最近一次失败超过该时间后，失败次数重新计算
+	loginFailureWindow = 15 * time.Minute
+	// maxLoginDelay 两次登录尝试之间的最长等待时间
+	maxLoginDelay = time.Minute
+)
+
+// loginDelay 计算连续失败后下次尝试前需等待的时间
+func loginDelay(policy loginThrottlePolicy, failures int) time.Duration {
+	if failures < policy.FreeAttempts {
+		return 0
+	}
+	// 逐次翻倍并在达到上限后停止，避免失败次数较多时计算溢出
+	delay := time.Second
+	for i := policy.FreeAttempts; i < failures && delay < maxLoginDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxLoginDelay {
+		return maxLoginDelay
+	}
+	return delay
+}
+
+// isStaleThrottle 判断失败记录是否已过期（不在锁定中，且距最近失败已超过统计窗口）
+func isStaleThrottle(throttle *types.LoginThrottle, now time.Time) bool {
+	if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+		return false
+	}
+	return now.Sub(throttle.LastFailedAt) > loginFailureWindow
+}
+
+// fillThrottleState 计算失败记录当前是否锁定及距可再次尝试的秒数
+func fillThrottleState(throttle *types.LoginThrottle, now time.Time) {
+	throttle.Locked = false
+	throttle.RetryAfter = 0
+
+	var until time.Time
+	if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+		throttle.Locked = true
+		until = *throttle.LockedUntil
+	} else if !isStaleThrottle(throttle, now) {
+		until = throttle.LastFailedAt.Add(loginDelay(loginThrottlePolicies[throttle.Kind], throttle.Failures))
+	}
+
+	if now.Before(until) {
+		throttle.RetryAfter = int(math.Ceil(until.Sub(now).Seconds()))
+	}
+}
+
+// getLoginThrottle 获取账号或IP的失败记录，不存在时返回 nil
+func getLoginThrottle(db *gorm.DB, kind, value string) (*types.LoginThrottle, error) {
+	var throttle types.LoginThrottle
+	if err := db.Where("kind = ? AND value = ?", kind, value).First(&throttle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// CheckLoginThrottle 检查账号与IP当前是否允许尝试登录
+// 不允许时返回需等待的秒数及原因
+func CheckLoginThrottle(username, ip string) (int, error) {
+	db := database.GetDB()
+	now := time.Now()
+
+	checks := []struct {
+		kind      string
+		value     string
+		lockedErr error
+	}{
+		{types.LoginThrottleUsername, username, ErrAccountLocked},
+		{types.LoginThrottleIP, ip, ErrIPLocked},
+	}
+	for _, check := range checks {
+		throttle, err := getLoginThrottle(db, check.kind, check.value)
+		if err != nil {
+			return 0, err
+		}
+		if throttle == nil {
+			continue
+		}
+
+		fillThrottleState(throttle, now)
+		if throttle.Locked {
+			return throttle.RetryAfter, check.lockedErr
+		}
+		if throttle.RetryAfter > 0 {
+			return throttle.RetryAfter, ErrLoginTooFrequent
+		}
+	}
+
+	return 0, nil
+}
+
+// GetAccountLock 检查账号是否处于锁定状态，锁定时返回剩余秒数及 ErrAccountLocked
+func GetAccountLock(username string) (int, error) {
+	db := database.GetDB()
+
+	throttle, err := getLoginThrottle(db, types.LoginThrottleUsername, username)
+	if err != nil || throttle == nil {
+		return 0, err
+	}
+
+	fillThrottleState(throttle, time.Now())
+	if throttle.Locked {
+		return throttle.RetryAfter, ErrAccountLocked
+	}
+
+	return 0, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，失败次数达到上限时锁定账号或IP
+// 账号不存在时同样记录，避免通过响应差异判断账号是否存在
+func RecordLoginFailure(username, ip string) error {
+	db := database.GetDB()
+	now := time.Now()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// 清理已过期的失败记录
+		if err := tx.Where("(locked_until IS NULL OR locked_until < ?) AND last_failed_at < ?", now, now.Add(-loginFailureWindow)).
+			Delete(&types.LoginThrottle{}).Error; err != nil {
+			return err
+		}
+
+		for kind, value := range map[string]string{
+			types.LoginThrottleUsername: username,
+			types.LoginThrottleIP:       ip,
+		} {
+			throttle := types.LoginThrottle{Kind: kind, Value: value}
+			if err := tx.Where("kind = ? AND value = ?", kind, value).FirstOrInit(&throttle).Error; err != nil {
+				return err
+			}
+
+			// 锁定结束后重新计算失败次数
+			if throttle.LockedUntil != nil && !now.Before(*throttle.LockedUntil) {
+				throttle.Failures = 0
+				throttle.LockedUntil = nil
+			}
+
+			policy := loginThrottlePolicies[kind]
+			throttle.Failures++
+			throttle.LastFailedAt = now
+			if throttle.Failures >= policy.LockAttempts {
+				lockedUntil := now.Add(policy.LockDuration)
+				throttle.LockedUntil = &lockedUntil
+			}
+
+			if err := tx.Save(&throttle).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ResetLoginFailures 登录成功后清除账号的失败记录（IP的失败记录保留，避免攻击者用自己的账号重置计数）
+func ResetLoginFailures(username string) error {
+	db := database.GetDB()
+
+	return db.Where("kind = ? AND value = ?", types.LoginThrottleUsername, username).
+		Delete(&types.LoginThrottle{}).Error
+}
+
+// GetLoginThrottles 获取当前仍有效的登录失败记录，lockedOnly 为 true 时仅返回锁定中的记录
+func GetLoginThrottles(lockedOnly bool) ([]*types.LoginThrottle, error) {
+	db := database.GetDB()
+	now := time.Now()
+
+	query := db.Where("(locked_until > ? OR last_failed_at >= ?)", now, now.Add(-loginFailureWindow))
+	if lockedOnly {
+		query = query.Where("locked_until > ?", now)
+	}
+
+	var throttles []*types.LoginThrottle
+	if err := query.Order("last_failed_at DESC").Find(&throttles).Error; err != nil {
+		return nil, err
+	}
+
+	for _, throttle := range throttles {
+		fillThrottleState(throttle, now)
+	}
+
+	return throttles, nil
+}
+
+// ClearLoginThrottle 清除登录失败记录（解除锁定）
+func ClearLoginThrottle(id int) error {
+	db := database.GetDB()
+
+	result := db.Delete(&types.LoginThrottle{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLoginThrottleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+)
+
+func TestLoginDelay(t *testing.T) {
+	username := loginThrottlePolicies[types.LoginThrottleUsername]
+	ip := loginThrottlePolicies[types.LoginThrottleIP]
+
+	tests := []struct {
+		name     string
+		policy   loginThrottlePolicy
+		failures int
+		want     time.Duration
+	}{
+		{"账号未失败", username, 0, 0},
+		{"账号失败次数未超过免等待次数", username, 2, 0},
+		{"账号刚超过免等待次数", username, 3, time.Second},
+		{"账号每次失败等待时间翻倍", username, 4, 2 * time.Second},
+		{"账号连续失败", username, 8, 32 * time.Second},
+		{"账号等待时间不超过上限", username, 9, maxLoginDelay},
+		{"IP失败次数未超过免等待次数", ip, 19, 0},
+		{"IP刚超过免等待次数", ip, 20, time.Second},
+		{"IP失败次数较多时不溢出", ip, 99, maxLoginDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loginDelay(tt.policy, tt.failures); got != tt.want {
+				t.Errorf("loginDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillThrottleState(t *testing.T) {
+	now := time.Now()
+	lockedUntil := now.Add(10 * time.Minute)
+	lockExpired := now.Add(-time.Minute)
+
+	tests := []struct {
+		name           string
+		throttle       types.LoginThrottle
+		wantLocked     bool
+		wantRetryAfter int
+	}{
+		{
+			name:     "未超过免等待次数",
+			throttle: types.LoginThrottle{Kind: types.LoginThrottleUsername, Failures: 2, LastFailedAt: now},
+		},
+		{
+			name:           "超过免等待次数后需等待",
+			throttle:       types.LoginThrottle{Kind: types.LoginThrottleUsername, Failures: 5, LastFailedAt: now},
+			wantRetryAfter: 4,
+		},
+		{
+			name:           "等待时间从最近一次失败起算",
+			throttle:       types.LoginThrottle{Kind: types.LoginThrottleUsername, Failures: 5, LastFailedAt: now.Add(-3 * time.Second)},
+			wantRetryAfter: 1,
+		},
+		{
+			name:     "等待时间已过",
+			throttle: types.LoginThrottle{Kind: types.LoginThrottleUsername, Failures: 5, LastFailedAt: now.Add(-5 * time.Second)},
+		},
+		{
+			name:           "锁定中",
+			throttle:       types.LoginThrottle{Kind: types.LoginThrottleUsername, Failures: 10, LastFailedAt: now, LockedUntil: &lockedUntil},
+			wantLocked:     true,
+			wantRetryAfter: 600,
+		},
+		{
+			name:     "锁定结束且超过统计窗口",
+			throttle: types.LoginThrottle{Kind: types.LoginThrottleUsername, Failures: 10, LastFailedAt: now.Add(-loginFailureWindow - time.Minute), LockedUntil: &lockExpired},
+		},
+		{
+			name:           "IP失败次数较多时等待上限",
+			throttle:       types.LoginThrottle{Kind: types.LoginThrottleIP, Failures: 90, LastFailedAt: now},
+			wantRetryAfter: int(maxLoginDelay / time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle := tt.throttle
+			fillThrottleState(&throttle, now)
+			if throttle.Locked != tt.wantLocked || throttle.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("fillThrottleState() locked = %v, retryAfter = %d, want %v, %d",
+					throttle.Locked, throttle.RetryAfter, tt.wantLocked, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestRecordLoginFailure(t *testing.T) {
+	clearTestTables(t, &types.LoginThrottle{})
+
+	tests := []struct {
+		name       string
+		username   string
+		ip         string
+		failures   int
+		reset      bool
+		wantErr    error
+		wantLocked bool
+	}{
+		{"未超过免等待次数", "throttle-a", "10.0.0.1", 2, false, nil, false},
+		{"超过免等待次数后需等待", "throttle-b", "10.0.0.2", 3, false, ErrLoginTooFrequent, false},
+		{"达到上限后锁定账号", "throttle-c", "10.0.0.3", 10, false, ErrAccountLocked, true},
+		{"登录成功后清除账号的失败记录", "throttle-d", "10.0.0.4", 2, true, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < tt.failures; i++ {
+				if err := RecordLoginFailure(tt.username, tt.ip); err != nil {
+					t.Fatalf("RecordLoginFailure() error = %v", err)
+				}
+			}
+			if tt.reset {
+				if err := ResetLoginFailures(tt.username); err != nil {
+					t.Fatalf("ResetLoginFailures() error = %v", err)
+				}
+			}
+
+			retryAfter, err := CheckLoginThrottle(tt.username, tt.ip)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckLoginThrottle() error = %v, want %v", err, tt.wantErr)
+			}
+			if (err != nil) != (retryAfter > 0) {
+				t.Errorf("CheckLoginThrottle() retryAfter = %d, error = %v", retryAfter, err)
+			}
+
+			// 锁定只针对账号，并可由 GetAccountLock 查询
+			lockRetryAfter, err := GetAccountLock(tt.username)
+			if locked := errors.Is(err, ErrAccountLocked); locked != tt.wantLocked {
+				t.Errorf("GetAccountLock() error = %v, want locked = %v", err, tt.wantLocked)
+			}
+			if tt.wantLocked && lockRetryAfter != int(loginThrottlePolicies[types.LoginThrottleUsername].LockDuration/time.Second) {
+				t.Errorf("GetAccountLock() retryAfter = %d", lockRetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"gorm.io/gorm"
+)
+
+// TestMain 使用内存 SQLite 数据库运行 models 包的测试
+// 在临时目录中运行，避免初始化时生成的 config.json 写入源码目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "models-test")
+	if err != nil {
+		log.Fatalf("创建临时目录失败: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		log.Fatalf("切换到临时目录失败: %v", err)
+	}
+
+	if err := config.Load(); err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	config.Get().Database.Path = ":memory:"
+	if err := database.Initialize(); err != nil {
+		log.Fatalf("初始化数据库失败: %v", err)
+	}
+
+	code := m.Run()
+
+	_ = database.Close()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// clearTestTables 清空测试用到的表，使测试可重复运行（如 go test -count=2）
+func clearTestTables(t *testing.T, tables ...interface{}) {
+	t.Helper()
+
+	db := database.GetDB().Session(&gorm.Session{AllowGlobalUpdate: true})
+	for _, table := range tables {
+		if err := db.Delete(table).Error; err != nil {
+			t.Fatalf("清空测试数据失败: %v", err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOIDCStateInvalid = errors.New("登录请求无效或已过期，请重新登录")
+)
+
+// CreateOIDCState 保存统一身份认证授权请求，同时清理已过期的请求
+func CreateOIDCState(state, nonce, codeVerifier string, ttl time.Duration) error {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", now).Delete(&types.OIDCState{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&types.OIDCState{
+			State:        state,
+			Nonce:        nonce,
+			CodeVerifier: codeVerifier,
+			ExpiresAt:    now.Add(ttl),
+		}).Error
+	})
+}
+
+// ConsumeOIDCState 取出并删除授权请求，每个 state 只能使用一次
+func ConsumeOIDCState(state string) (*types.OIDCState, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var oidcState types.OIDCState
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state = ?", state).First(&oidcState).Error; err != nil {
+			return err
+		}
+		// 条件删除，防止同一 state 被并发使用两次
+		result := tx.Where("id = ?", oidcState.ID).Delete(&types.OIDCState{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOIDCStateInvalid
+		}
+		return nil, err
+	}
+
+	if time.Now().After(oidcState.ExpiresAt) {
+		return nil, ErrOIDCStateInvalid
+	}
+
+	return &oidcState, nil
+}
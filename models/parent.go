@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrParentNotFound = errors.New("家长不存在")
+	ErrNotParentChild = errors.New("只能查看自己孩子的信息")
+)
+
+// GetOrCreateParent 获取家长账号，首次登录时创建，并记录登录时间与钉钉中的最新姓名
+func GetOrCreateParent(dingTalkID, name string) (*types.Parent, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	now := time.Now()
+	parent := &types.Parent{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("ding_talk_id = ?", dingTalkID).First(parent).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			parent = &types.Parent{DingTalkID: dingTalkID, Name: name, LastLoginAt: &now}
+			return tx.Create(parent).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"last_login_at": now}
+		if name != "" {
+			updates["name"] = name
+		}
+		return tx.Model(parent).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parent, nil
+}
+
+// GetParentByID 通过ID获取家长
+func GetParentByID(id int) (*types.Parent, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var parent types.Parent
+	if err := db.First(&parent, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrParentNotFound
+		}
+		return nil, err
+	}
+
+	return &parent, nil
+}
+
+// GetParentChildren 获取家长关联的学生（按家长学生关系，通过钉钉ID匹配学生）
+func GetParentChildren(parentDingTalkID string) ([]*types.ParentChild, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var rows []*types.ParentChild
+	err := db.Raw(`
+        SELECT s.id AS student_id, s.full_name, s.class_id, c.name AS class_name, psr.relation
+        FROM parent_student_relations psr
+        JOIN students s ON psr.student_id = s.ding_talk_id
+        LEFT JOIN classes c ON c.id = s.class_id
+        WHERE psr.parent_id = ? AND s.ding_talk_id NOT IN ('', '0')
+        ORDER BY s.id
+    `, parentDingTalkID).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一家长与学生可能有多条关系记录，每个学生只保留一条
+	children := make([]*types.ParentChild, 0, len(rows))
+	seen := make(map[int]bool)
+	for _, row := range rows {
+		if seen[row.StudentID] {
+			continue
+		}
+		seen[row.StudentID] = true
+		children = append(children, row)
+	}
+
+	return children, nil
+}
+
+// GetParentChildIDs 获取家长关联的学生ID列表
+func GetParentChildIDs(parentDingTalkID string) ([]int, error) {
+	children, err := GetParentChildren(parentDingTalkID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(children))
+	for i, child := range children {
+		ids[i] = child.StudentID
+	}
+	return ids, nil
+}
+
+// IsParentOfStudent 判断学生是否为家长关联的学生
+func IsParentOfStudent(parentDingTalkID string, studentID int) (bool, error) {
+	ids, err := GetParentChildIDs(parentDingTalkID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range ids {
+		if id == studentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
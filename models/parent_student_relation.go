@@ -27,7 +27,7 @@ func GetStudentsByParentID(parentID string) ([]*ParentStudentRelation, error) {
 	err := db.Raw(`
         SELECT psr.id, psr.parent_id, psr.student_id, psr.relation, s.full_name as student_name
         FROM parent_student_relations psr
-        JOIN students s ON psr.student_id = s.dingtalk_id
+        JOIN students s ON psr.student_id = s.ding_talk_id
         WHERE psr.parent_id = ?
     `, parentID).Scan(&relations).Error
 
@@ -80,7 +80,7 @@ func GetParentsByStudentID(studentID int) ([]string, error) {
 
 	// 首先获取学生的钉钉ID
 	var student types.Student
-	if err := db.Select("dingtalk_id").First(&student, studentID).Error; err != nil {
+	if err := db.Select("ding_talk_id").First(&student, studentID).Error; err != nil {
 		return nil, err
 	}
 
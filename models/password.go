@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOldPasswordIncorrect = errors.New("原密码错误")
+	ErrPasswordNotSupported = errors.New("该账号不使用密码登录")
+)
+
+// ChangePassword 用户修改自己的密码
+// 验证原密码后更新密码并清除需修改密码标记，同时撤销该用户在其他设备上的会话
+func ChangePassword(userID int, role string, oldPassword, newPassword string, currentSessionID int) error {
+	if err := utils.ValidateNewPassword(oldPassword, newPassword); err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+
+	// 根据会话所属用户类型选择对应的表
+	var model interface{}
+	var currentHash string
+	switch role {
+	case types.SessionRoleAdmin:
+		user := &types.User{}
+		if err := db.Select("id", "password").First(user, userID).Error; err != nil {
+			return err
+		}
+		model, currentHash = user, user.Password
+	case types.SessionRoleStudent:
+		student := &types.Student{}
+		if err := db.Select("id", "password").First(student, userID).Error; err != nil {
+			return err
+		}
+		model, currentHash = student, student.Password
+	default:
+		// 家长通过钉钉登录，没有密码
+		return ErrPasswordNotSupported
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(currentHash), []byte(oldPassword)); err != nil {
+		return ErrOldPasswordIncorrect
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(model).Updates(map[string]interface{}{
+			"password":             string(hashedPassword),
+			"must_change_password": false,
+		}).Error; err != nil {
+			return err
+		}
+		_, err := revokeOtherSessions(tx, userID, role, currentSessionID)
+		return err
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/database"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"gorm.io/gorm"
 )
 
@@ -15,12 +16,15 @@ import (
 // 在CalculateRankingByCompetitionID后调用
 // 只有已审核的比赛（StatusCompleted）才会将得分写入Points表
 func RecalculatePointsByCompetitionID(competitionID int) error {
-	db := database.GetDB()
+	return recalculatePoints(database.GetDB(), competitionID)
+}
 
+// recalculatePoints 重新计算比赛得分，db 为事务时在该事务中完成
+func recalculatePoints(db *gorm.DB, competitionID int) error {
 	return db.Transaction(func(tx *gorm.DB) error {
 		// 获取比赛信息
 		var competition types.Competition
-		if err := tx.Select("id", "competition_type", "status").First(&competition, competitionID).Error; err != nil {
+		if err := tx.Select("id", "competition_type", "status", "event_id").First(&competition, competitionID).Error; err != nil {
 			return err
 		}
 
@@ -40,15 +44,18 @@ func RecalculatePointsByCompetitionID(competitionID int) error {
 			return nil // 没有成绩，无需计算得分
 		}
 
-		// 获取配置
-		cfg := config.Get()
+		// 获取比赛所属届次的得分映射
+		settings, err := utils.LoadEventSettings(tx, competition.EventID)
+		if err != nil {
+			return err
+		}
 		var pointsMapping map[string]float64
 
 		// 根据比赛类型选择得分映射
 		if competition.CompetitionType == types.TypeTeam {
-			pointsMapping = cfg.Scoring.TeamPointsMapping
+			pointsMapping = settings.TeamPointsMapping
 		} else {
-			pointsMapping = cfg.Scoring.IndividualPointsMapping
+			pointsMapping = settings.IndividualPointsMapping
 		}
 
 		// 为每个成绩记录创建得分
@@ -215,14 +222,10 @@ func AddCustomPointsToClass(classID int, points float64, reason string, createdB
 	return db.Create(point).Error
 }
 
-// GetClassPointsSummary 获取班级得分汇总（按总分排名）
-func GetClassPointsSummary() ([]types.ClassPointsSummary, error) {
+// GetClassPointsSummary 获取指定届次的班级得分汇总（按总分排名）
+func GetClassPointsSummary(eventID int) ([]types.ClassPointsSummary, error) {
 	db := database.GetDB()
 
-	// 获取当前运动会ID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	// 查询所有班级的得分汇总
 	var results []types.ClassPointsSummary
 	err := db.Raw(`
@@ -239,7 +242,7 @@ func GetClassPointsSummary() ([]types.ClassPointsSummary, error) {
 		)
 		GROUP BY c.id, c.name
 		ORDER BY total_points DESC
-	`, types.PointTypeRanking, types.PointTypeCustom, currentEventID, -currentEventID).Scan(&results).Error
+	`, types.PointTypeRanking, types.PointTypeCustom, eventID, -eventID).Scan(&results).Error
 
 	if err != nil {
 		return nil, err
@@ -258,14 +261,10 @@ func GetClassPointsSummary() ([]types.ClassPointsSummary, error) {
 	return results, nil
 }
 
-// GetStudentPointsSummary 获取学生得分汇总（按总分排名）
-func GetStudentPointsSummary() ([]types.StudentPointsSummary, error) {
+// GetStudentPointsSummary 获取指定届次的学生得分汇总（按总分排名）
+func GetStudentPointsSummary(eventID int) ([]types.StudentPointsSummary, error) {
 	db := database.GetDB()
 
-	// 获取当前运动会ID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	// 查询所有学生的得分汇总（只计算ranking类型的得分）
 	var results []types.StudentPointsSummary
 	err := db.Raw(`
@@ -284,7 +283,7 @@ func GetStudentPointsSummary() ([]types.StudentPointsSummary, error) {
 		GROUP BY s.id, s.full_name, s.class_id, c.name
 		HAVING total_points > 0
 		ORDER BY total_points DESC
-	`, types.PointTypeRanking, currentEventID).Scan(&results).Error
+	`, types.PointTypeRanking, eventID).Scan(&results).Error
 
 	if err != nil {
 		return nil, err
@@ -303,18 +302,14 @@ func GetStudentPointsSummary() ([]types.StudentPointsSummary, error) {
 	return results, nil
 }
 
-// GetClassPointDetails 获取班级得分明细
-func GetClassPointDetails(classID int) ([]types.PointDetail, error) {
+// GetClassPointDetails 获取班级在指定届次的得分明细
+func GetClassPointDetails(eventID, classID int) ([]types.PointDetail, error) {
 	db := database.GetDB()
 
-	// 获取当前运动会ID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	var points []types.Points
 	err := db.Preload("Competition").Preload("Creator").
 		Where("class_id = ? AND (competition_id IN (SELECT id FROM competitions WHERE event_id = ?) OR competition_id = ?)",
-			classID, currentEventID, -currentEventID).
+			classID, eventID, -eventID).
 		Order("created_at DESC").
 		Find(&points).Error
 
@@ -354,18 +349,14 @@ func GetClassPointDetails(classID int) ([]types.PointDetail, error) {
 	return details, nil
 }
 
-// GetStudentPointDetails 获取学生得分明细
-func GetStudentPointDetails(studentID int) ([]types.PointDetail, error) {
+// GetStudentPointDetails 获取学生在指定届次的得分明细
+func GetStudentPointDetails(eventID, studentID int) ([]types.PointDetail, error) {
 	db := database.GetDB()
 
-	// 获取当前运动会ID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	var points []types.Points
 	err := db.Preload("Competition").
 		Where("student_id = ? AND competition_id IN (SELECT id FROM competitions WHERE event_id = ?)",
-			studentID, currentEventID).
+			studentID, eventID).
 		Order("created_at DESC").
 		Find(&points).Error
 
@@ -392,9 +383,9 @@ func GetStudentPointDetails(studentID int) ([]types.PointDetail, error) {
 	return details, nil
 }
 
-// GetTopClasses 获取前N名班级
-func GetTopClasses(limit int) ([]types.ClassPointsSummary, error) {
-	summaries, err := GetClassPointsSummary()
+// GetTopClasses 获取指定届次的前N名班级
+func GetTopClasses(eventID, limit int) ([]types.ClassPointsSummary, error) {
+	summaries, err := GetClassPointsSummary(eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -409,9 +400,9 @@ func GetTopClasses(limit int) ([]types.ClassPointsSummary, error) {
 	return result, nil
 }
 
-// GetTopStudents 获取前N名学生
-func GetTopStudents(limit int) ([]types.StudentPointsSummary, error) {
-	summaries, err := GetStudentPointsSummary()
+// GetTopStudents 获取指定届次的前N名学生
+func GetTopStudents(eventID, limit int) ([]types.StudentPointsSummary, error) {
+	summaries, err := GetStudentPointsSummary(eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -431,11 +422,8 @@ func DeleteCustomPoint(pointID int) error {
 	db := database.GetDB()
 
 	// 检查是否为自定义得分
-	var point types.Points
-	if err := db.First(&point, pointID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("得分记录不存在")
-		}
+	point, err := GetPointByID(pointID)
+	if err != nil {
 		return err
 	}
 
@@ -443,12 +431,27 @@ func DeleteCustomPoint(pointID int) error {
 		return errors.New("只能删除自定义得分记录")
 	}
 
-	return db.Delete(&point).Error
+	return db.Delete(point).Error
+}
+
+// GetPointByID 获取得分记录
+func GetPointByID(pointID int) (*types.Points, error) {
+	db := database.GetDB()
+
+	var point types.Points
+	if err := db.First(&point, pointID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("得分记录不存在")
+		}
+		return nil, err
+	}
+
+	return &point, nil
 }
 
-// GetClassPointsSummaryByID 获取指定班级的得分汇总
-func GetClassPointsSummaryByID(classID int) (*types.ClassPointsSummary, error) {
-	summaries, err := GetClassPointsSummary()
+// GetClassPointsSummaryByID 获取指定班级在指定届次的得分汇总
+func GetClassPointsSummaryByID(eventID, classID int) (*types.ClassPointsSummary, error) {
+	summaries, err := GetClassPointsSummary(eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -462,9 +465,9 @@ func GetClassPointsSummaryByID(classID int) (*types.ClassPointsSummary, error) {
 	return nil, fmt.Errorf("班级 %d 未找到任何得分记录", classID)
 }
 
-// GetStudentPointsSummaryByID 获取指定学生的得分汇总
-func GetStudentPointsSummaryByID(studentID int) (*types.StudentPointsSummary, error) {
-	summaries, err := GetStudentPointsSummary()
+// GetStudentPointsSummaryByID 获取指定学生在指定届次的得分汇总
+func GetStudentPointsSummaryByID(eventID, studentID int) (*types.StudentPointsSummary, error) {
+	summaries, err := GetStudentPointsSummary(eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+)
+
+// recordHistoryRow 同名个人赛的历届成绩
+type recordHistoryRow struct {
+	CompetitionID int
+	EventID       int
+	StudentID     int
+	Score         float64
+	RankingMode   types.RankingMode
+	Unit          string
+}
+
+// isBetterScore 按排名方式判断成绩 a 是否优于成绩 b
+func isBetterScore(mode types.RankingMode, a, b float64) bool {
+	if mode == types.RankingLowerFirst {
+		return a < b
+	}
+	return a > b
+}
+
+// GetStudentProfile 获取学生跨届次参赛档案（历届名次、得分、个人最好成绩与创造的纪录）
+func GetStudentProfile(studentID int) (*types.StudentProfile, error) {
+	db := database.GetDB()
+
+	student, err := GetStudentByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &types.StudentProfile{
+		Student:       student,
+		Events:        make([]*types.StudentEventProfile, 0),
+		PersonalBests: make([]*types.StudentPersonalBest, 0),
+		Records:       make([]*types.StudentRecord, 0),
+	}
+
+	// 查询学生报名过的届次
+	var events []*types.Event
+	if err := db.Where("id IN (?)", db.Table("registrations r").
+		Select("c.event_id").
+		Joins("JOIN competitions c ON c.id = r.competition_id").
+		Where("r.student_id = ?", studentID)).
+		Order("id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	eventNames := make(map[int]string, len(events))
+	bests := make(map[string]*types.StudentPersonalBest)
+	bestNames := make([]string, 0)
+	for _, event := range events {
+		eventNames[event.ID] = event.Name
+
+		eventProfile := &types.StudentEventProfile{
+			EventID:   event.ID,
+			EventName: event.Name,
+			Results:   make([]*types.StudentCompetitionResult, 0),
+		}
+
+		var registrationCount int64
+		if err := db.Table("registrations r").
+			Joins("JOIN competitions c ON c.id = r.competition_id").
+			Where("r.student_id = ? AND c.event_id = ?", studentID, event.ID).
+			Count(&registrationCount).Error; err != nil {
+			return nil, err
+		}
+		eventProfile.Registrations = int(registrationCount)
+
+		scores, err := GetScoresByStudentID(event.ID, studentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, score := range scores {
+			eventProfile.Results = append(eventProfile.Results, &types.StudentCompetitionResult{
+				CompetitionID:   score.CompetitionID,
+				CompetitionName: score.Competition.Name,
+				CompetitionType: score.Competition.CompetitionType,
+				Unit:            score.Competition.Unit,
+				Score:           score.Score,
+				Ranking:         score.Ranking,
+				Point:           score.Point,
+			})
+
+			// 个人最好成绩只统计个人赛
+			if score.Competition.CompetitionType != types.TypeIndividual {
+				continue
+			}
+			best, ok := bests[score.Competition.Name]
+			if !ok {
+				best = &types.StudentPersonalBest{CompetitionName: score.Competition.Name}
+				bests[score.Competition.Name] = best
+				bestNames = append(bestNames, score.Competition.Name)
+			} else if !isBetterScore(score.Competition.RankingMode, score.Score, best.Score) {
+				continue
+			}
+			best.Unit = score.Competition.Unit
+			best.RankingMode = score.Competition.RankingMode
+			best.Score = score.Score
+			best.CompetitionID = score.CompetitionID
+			best.EventID = event.ID
+			best.EventName = event.Name
+		}
+
+		// 该届次的得分与排名
+		if summary, err := GetStudentPointsSummaryByID(event.ID, studentID); err == nil {
+			eventProfile.TotalPoints = summary.TotalPoints
+			eventProfile.Rank = summary.Rank
+			profile.TotalPoints += summary.TotalPoints
+		}
+
+		profile.Events = append(profile.Events, eventProfile)
+	}
+
+	for _, name := range bestNames {
+		profile.PersonalBests = append(profile.PersonalBests, bests[name])
+
+		records, err := getStudentRecords(studentID, name, eventNames)
+		if err != nil {
+			return nil, err
+		}
+		profile.Records = append(profile.Records, records...)
+	}
+
+	return profile, nil
+}
+
+// getStudentRecords 获取学生在同名个人赛中创造的纪录
+// 按届次先后比较，某届次的最好成绩优于此前所有届次的最好成绩即为创造纪录（首次举办的届次同样计入）
+func getStudentRecords(studentID int, competitionName string, eventNames map[int]string) ([]*types.StudentRecord, error) {
+	db := database.GetDB()
+
+	var rows []recordHistoryRow
+	if err := db.Table("scores s").
+		Select("s.competition_id, c.event_id, s.student_id, s.score, c.ranking_mode, c.unit").
+		Joins("JOIN competitions c ON c.id = s.competition_id").
+		Where("c.name = ? AND c.competition_type = ? AND c.status = ? AND s.student_id IS NOT NULL",
+			competitionName, types.TypeIndividual, types.StatusCompleted).
+		Order("c.event_id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]*types.StudentRecord, 0)
+	var recordScore float64
+	hasRecord := false
+	for i := 0; i < len(rows); {
+		// 取出同一届次的所有成绩
+		j := i
+		eventBest := rows[i]
+		for j < len(rows) && rows[j].EventID == rows[i].EventID {
+			if isBetterScore(rows[j].RankingMode, rows[j].Score, eventBest.Score) {
+				eventBest = rows[j]
+			}
+			j++
+		}
+
+		if !hasRecord || isBetterScore(eventBest.RankingMode, eventBest.Score, recordScore) {
+			recordScore = eventBest.Score
+			hasRecord = true
+			for _, row := range rows[i:j] {
+				if row.StudentID == studentID && row.Score == eventBest.Score {
+					records = append(records, &types.StudentRecord{
+						CompetitionName: competitionName,
+						Unit:            row.Unit,
+						Score:           row.Score,
+						CompetitionID:   row.CompetitionID,
+						EventID:         row.EventID,
+						EventName:       eventNames[row.EventID],
+					})
+					break
+				}
+			}
+		}
+		i = j
+	}
+
+	// 纪录是否仍然有效
+	for _, record := range records {
+		record.Standing = record.Score == recordScore
+	}
+
+	return records, nil
+}
@@ -0,0 +1,192 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMergeSourceRequired = errors.New("请选择要合并的推荐项目")
+	ErrMergeIntoSelf       = errors.New("不能将项目合并到自身")
+)
+
+// FindDuplicateProposals 在当前届次的比赛项目中查找与名称疑似重复的项目（已拒绝和已合并的项目除外）
+// excludeID: 排除的比赛ID（如正在修改的项目自身），为0时不排除
+func FindDuplicateProposals(name string, excludeID int) ([]*types.DuplicateCandidate, error) {
+	db := database.GetDB()
+
+	var competitions []*types.Competition
+	if err := db.Select("id", "name", "status", "vote_count").
+		Where("event_id = ? AND id != ? AND status != ? AND merged_into_id IS NULL", config.Get().CurrentEventID, excludeID, types.StatusRejected).
+		Find(&competitions).Error; err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*types.DuplicateCandidate, 0)
+	for _, comp := range competitions {
+		similarity := utils.CompetitionNameSimilarity(name, comp.Name)
+		if similarity < utils.DuplicateNameThreshold {
+			continue
+		}
+		candidates = append(candidates, &types.DuplicateCandidate{
+			CompetitionID: comp.ID,
+			Name:          comp.Name,
+			Status:        comp.Status,
+			VoteCount:     comp.VoteCount,
+			Similarity:    similarity,
+		})
+	}
+
+	// 相似度高的排在前面
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+
+	return candidates, nil
+}
+
+// MergeCompetitions 将重复的推荐项目合并到目标项目
+// 被合并项目的投票转移到目标项目（同一学生对两个项目都投过票时保留其对目标项目的投票），
+// 提交者作为共同署名记录到目标项目，被合并项目标记为已拒绝并记录合并去向
+func MergeCompetitions(targetID int, sourceIDs []int, operatorID int) error {
+	if len(sourceIDs) == 0 {
+		return ErrMergeSourceRequired
+	}
+
+	db := database.GetDB()
+	currentEventID := config.Get().CurrentEventID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var target types.Competition
+		if err := tx.Where("event_id = ?", currentEventID).First(&target, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrCompetitionNotFound
+			}
+			return err
+		}
+		if target.Status == types.StatusRejected || target.MergedIntoID != nil {
+			return errors.New("目标项目已被拒绝或合并，无法作为合并目标")
+		}
+
+		for _, sourceID := range sourceIDs {
+			if sourceID == targetID {
+				return ErrMergeIntoSelf
+			}
+
+			var source types.Competition
+			if err := tx.Where("event_id = ?", currentEventID).First(&source, sourceID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("比赛项目 %d 不存在", sourceID)
+				}
+				return err
+			}
+			// 只有仍在审核阶段的推荐项目可以被合并，避免影响已有报名和成绩
+			if !utils.IsProposalEditable(source.Status) || source.MergedIntoID != nil {
+				return fmt.Errorf("项目「%s」当前状态不允许合并", source.Name)
+			}
+
+			if err := mergeCompetitionVotes(tx, source.ID, target.ID); err != nil {
+				return err
+			}
+
+			if err := mergeCompetitionCredits(tx, &source, &target); err != nil {
+				return err
+			}
+
+			reason := fmt.Sprintf("已合并到项目「%s」", target.Name)
+			if err := tx.Model(&types.Competition{}).Where("id = ?", source.ID).Updates(map[string]interface{}{
+				"status":         types.StatusRejected,
+				"status_reason":  reason,
+				"merged_into_id": target.ID,
+				"vote_count":     0,
+				"reviewer_id":    operatorID,
+			}).Error; err != nil {
+				return err
+			}
+			if err := recordStatusChange(tx, source.ID, source.Status, types.StatusRejected, reason, operatorID); err != nil {
+				return err
+			}
+		}
+
+		// 重新计算目标项目的投票数
+		var voteCount int64
+		if err := tx.Model(&types.Vote{}).Where("competition_id = ?", target.ID).
+			Select("COALESCE(SUM(vote_type), 0)").Scan(&voteCount).Error; err != nil {
+			return err
+		}
+		return tx.Model(&types.Competition{}).Where("id = ?", target.ID).Update("vote_count", voteCount).Error
+	})
+}
+
+// mergeCompetitionVotes 将被合并项目的投票转移到目标项目
+func mergeCompetitionVotes(tx *gorm.DB, sourceID, targetID int) error {
+	// 已对目标项目投票的学生，保留其对目标项目的投票
+	if err := tx.Where("competition_id = ? AND student_id IN (?)", sourceID,
+		tx.Model(&types.Vote{}).Select("student_id").Where("competition_id = ?", targetID)).
+		Delete(&types.Vote{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&types.Vote{}).Where("competition_id = ?", sourceID).Update("competition_id", targetID).Error
+}
+
+// mergeCompetitionCredits 将被合并项目的提交者（及其已有的共同署名）记录到目标项目
+func mergeCompetitionCredits(tx *gorm.DB, source, target *types.Competition) error {
+	studentIDs := make([]int, 0)
+	if source.SubmitterID != nil {
+		studentIDs = append(studentIDs, *source.SubmitterID)
+	}
+
+	var sourceCredits []*types.ProposalCredit
+	if err := tx.Where("competition_id = ?", source.ID).Find(&sourceCredits).Error; err != nil {
+		return err
+	}
+	for _, credit := range sourceCredits {
+		studentIDs = append(studentIDs, credit.StudentID)
+	}
+	if err := tx.Where("competition_id = ?", source.ID).Delete(&types.ProposalCredit{}).Error; err != nil {
+		return err
+	}
+
+	for _, studentID := range studentIDs {
+		// 目标项目的提交者本人无需重复署名
+		if target.SubmitterID != nil && *target.SubmitterID == studentID {
+			continue
+		}
+
+		var count int64
+		if err := tx.Model(&types.ProposalCredit{}).Where("competition_id = ? AND student_id = ?", target.ID, studentID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := tx.Create(&types.ProposalCredit{
+			CompetitionID:  target.ID,
+			StudentID:      studentID,
+			MergedFromID:   source.ID,
+			MergedFromName: source.Name,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fillProposalCreditNames 填充共同署名学生的姓名
+func fillProposalCreditNames(credits []*types.ProposalCredit) {
+	for _, credit := range credits {
+		if credit.Student != nil {
+			credit.StudentName = credit.Student.FullName
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/database"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
 	"github.com/SHXZ-OSS/sports-meeting-system/utils"
@@ -11,14 +12,24 @@ import (
 )
 
 // RegisterForCompetitionForStudent 学生报名比赛（个人赛和团体赛都以学生为单位）
-func RegisterForCompetitionForStudent(studentID *int, classID *int, competitionID int, user *types.User) error {
+// 如果比赛需要家长确认，不会直接创建报名记录，而是返回等待家长确认的记录
+func RegisterForCompetitionForStudent(studentID *int, classID *int, competitionID int, user *types.User) (*types.RegistrationConsent, error) {
 	// 获取数据库连接和验证器
 	db := database.GetDB()
 	validator := utils.NewRegistrationValidator(db)
 
 	// 使用验证器验证报名请求
 	if err := validator.ValidateRegistration(studentID, classID, competitionID, user); err != nil {
-		return err
+		return nil, err
+	}
+
+	// 需要家长确认的比赛，先创建确认记录
+	var competition types.Competition
+	if err := db.Select("requires_guardian_consent").First(&competition, competitionID).Error; err != nil {
+		return nil, err
+	}
+	if competition.RequiresGuardianConsent {
+		return createRegistrationConsent(db, *studentID, competitionID)
 	}
 
 	// 如果没有提供classID，从学生信息中获取
@@ -35,7 +46,7 @@ func RegisterForCompetitionForStudent(studentID *int, classID *int, competitionI
 		ClassID:       classID,
 		CompetitionID: competitionID,
 	}
-	return db.Create(registration).Error
+	return nil, db.Create(registration).Error
 }
 
 // UnregisterFromCompetition 取消报名
@@ -57,7 +68,14 @@ func UnregisterFromCompetition(studentID *int, classID *int, competitionID int,
 	}
 
 	if result.RowsAffected == 0 {
-		return utils.ErrNotRegistered
+		// 没有正式报名记录时，尝试取消等待家长确认的报名
+		cancelled, err := cancelPendingConsent(db, *studentID, competitionID)
+		if err != nil {
+			return err
+		}
+		if cancelled == 0 {
+			return utils.ErrNotRegistered
+		}
 	}
 
 	return nil
@@ -111,7 +129,7 @@ func GetStudentRegistrationsByStudentID(studentID int) ([]*types.Competition, er
 
 	// 查询学生报名的比赛
 	var registrations []*types.Registration
-	err := db.Preload("Competition.Submitter").Preload("Competition.Reviewer").Where("student_id = ?", studentID).Order("created_at DESC").Find(&registrations).Error
+	err := db.Preload("Competition.Submitter").Preload("Competition.Reviewer").Preload("Competition.Venue").Where("student_id = ?", studentID).Order("created_at DESC").Find(&registrations).Error
 	if err != nil {
 		return nil, err
 	}
@@ -236,6 +254,9 @@ func GetCompetitionChecklist(scopeClassIDs *[]int) ([]map[string]any, error) {
 	// 添加时间冲突检测结果
 	results = append(results, timeConflictIssues...)
 
+	// 添加参赛资格检测结果
+	results = append(results, checkRegistrationEligibility(db, scopeClassIDs)...)
+
 	return results, nil
 }
 
@@ -319,6 +340,60 @@ func checkStudentTimeConflicts(db *gorm.DB, scopeClassIDs *[]int) []map[string]a
 	return issues
 }
 
+// checkRegistrationEligibility 检查已有报名中是否存在不符合参赛资格的学生
+// 为保护学生隐私，仅提示不符合资格，不返回限制类型和备注
+func checkRegistrationEligibility(db *gorm.DB, scopeClassIDs *[]int) []map[string]any {
+	var issues []map[string]any
+
+	// 获取所有参赛资格限制记录
+	var eligibilities []types.StudentEligibility
+	if err := db.Find(&eligibilities).Error; err != nil || len(eligibilities) == 0 {
+		return issues
+	}
+
+	// 按学生分组
+	studentEligibilities := make(map[int][]*types.StudentEligibility)
+	for i := range eligibilities {
+		studentEligibilities[eligibilities[i].StudentID] = append(studentEligibilities[eligibilities[i].StudentID], &eligibilities[i])
+	}
+
+	studentIDs := make([]int, 0, len(studentEligibilities))
+	for studentID := range studentEligibilities {
+		studentIDs = append(studentIDs, studentID)
+	}
+
+	// 获取这些学生在当前运动会的报名记录
+	var registrations []types.Registration
+	query := db.Preload("Student.Class").Preload("Competition").
+		Joins("JOIN competitions ON competitions.id = registrations.competition_id").
+		Where("registrations.student_id IN ? AND competitions.event_id = ?", studentIDs, config.Get().CurrentEventID)
+	if scopeClassIDs != nil && len(*scopeClassIDs) > 0 {
+		query = query.Where("registrations.class_id IN ?", *scopeClassIDs)
+	}
+	if err := query.Find(&registrations).Error; err != nil {
+		return issues
+	}
+
+	for _, reg := range registrations {
+		if reg.StudentID == nil || reg.Student == nil {
+			continue
+		}
+		for _, eligibility := range studentEligibilities[*reg.StudentID] {
+			if utils.IsEligibilityApplicable(eligibility, &reg.Competition) {
+				issues = append(issues, map[string]any{
+					"competition_id":   reg.Competition.ID,
+					"competition_name": reg.Competition.Name,
+					"status":           "error",
+					"message":          fmt.Sprintf("学生 %s %s 当前不符合该项目的参赛资格", reg.Student.Class.Name, reg.Student.FullName),
+				})
+				break
+			}
+		}
+	}
+
+	return issues
+}
+
 // timesOverlap 检查两个时间段是否有重叠
 func timesOverlap(start1, end1, start2, end2 *time.Time) bool {
 	// 时间段1: [start1, end1]
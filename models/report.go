@@ -0,0 +1,283 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"gorm.io/gorm"
+)
+
+// classEventStats 班级在某一届次的参赛统计
+type classEventStats struct {
+	ClassID       int
+	Participants  int
+	Registrations int
+	FirstPlaces   int
+}
+
+// getReportEvents 获取参与对比的运动会届次，eventIDs为空时返回所有届次（按ID升序）
+func getReportEvents(eventIDs []int) ([]*types.Event, error) {
+	db := database.GetDB()
+
+	if len(eventIDs) == 0 {
+		var events []*types.Event
+		if err := db.Order("id ASC").Find(&events).Error; err != nil {
+			return nil, err
+		}
+		return events, nil
+	}
+
+	events := make([]*types.Event, 0, len(eventIDs))
+	for _, id := range eventIDs {
+		var event types.Event
+		if err := db.First(&event, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("运动会届次 %d 不存在", id)
+			}
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+// getClassEventStats 获取指定届次各班级的参赛人数、报名人次与第一名次数
+func getClassEventStats(eventID int) (map[int]*classEventStats, error) {
+	db := database.GetDB()
+
+	// 报名记录保存了报名时的班级，学生升级换班后仍按原班级统计
+	var registrationRows []struct {
+		ClassID       int
+		Participants  int
+		Registrations int
+	}
+	if err := db.Raw(`
+		SELECT
+			COALESCE(r.class_id, s.class_id) as class_id,
+			COUNT(DISTINCT r.student_id) as participants,
+			COUNT(*) as registrations
+		FROM registrations r
+		JOIN competitions c ON c.id = r.competition_id
+		LEFT JOIN students s ON s.id = r.student_id
+		WHERE c.event_id = ?
+		GROUP BY COALESCE(r.class_id, s.class_id)
+	`, eventID).Scan(&registrationRows).Error; err != nil {
+		return nil, err
+	}
+
+	// 第一名次数按班级得分记录统计，得分记录保存了获得名次时的班级
+	var firstPlaceRows []struct {
+		ClassID     int
+		FirstPlaces int
+	}
+	if err := db.Raw(`
+		SELECT p.class_id as class_id, COUNT(*) as first_places
+		FROM points p
+		JOIN competitions c ON c.id = p.competition_id
+		WHERE c.event_id = ? AND p.class_id IS NOT NULL AND p.point_type = ? AND p.ranking = 1
+		GROUP BY p.class_id
+	`, eventID, types.PointTypeRanking).Scan(&firstPlaceRows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make(map[int]*classEventStats)
+	get := func(classID int) *classEventStats {
+		if s, ok := stats[classID]; ok {
+			return s
+		}
+		s := &classEventStats{ClassID: classID}
+		stats[classID] = s
+		return s
+	}
+	for _, row := range registrationRows {
+		s := get(row.ClassID)
+		s.Participants = row.Participants
+		s.Registrations = row.Registrations
+	}
+	for _, row := range firstPlaceRows {
+		get(row.ClassID).FirstPlaces = row.FirstPlaces
+	}
+
+	return stats, nil
+}
+
+// GetClassLineageReports 获取班级沿革跨届次对比报表
+// eventIDs: 参与对比的届次，为空时对比所有届次；lineageID: 指定沿革，为0时返回所有沿革
+func GetClassLineageReports(eventIDs []int, lineageID int) ([]*types.ClassLineageReport, error) {
+	events, err := getReportEvents(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var lineages []*types.ClassLineage
+	if lineageID > 0 {
+		lineage, err := GetClassLineageByID(lineageID)
+		if err != nil {
+			return nil, err
+		}
+		lineages = []*types.ClassLineage{lineage}
+	} else {
+		lineages, err = GetAllClassLineages()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 每个届次的班级得分汇总与参赛统计只计算一次
+	summaries := make(map[int]map[int]types.ClassPointsSummary)
+	stats := make(map[int]map[int]*classEventStats)
+	for _, event := range events {
+		classSummaries, err := GetClassPointsSummary(event.ID)
+		if err != nil {
+			return nil, err
+		}
+		byClass := make(map[int]types.ClassPointsSummary, len(classSummaries))
+		for _, summary := range classSummaries {
+			byClass[summary.ClassID] = summary
+		}
+		summaries[event.ID] = byClass
+
+		eventStats, err := getClassEventStats(event.ID)
+		if err != nil {
+			return nil, err
+		}
+		stats[event.ID] = eventStats
+	}
+
+	reports := make([]*types.ClassLineageReport, 0, len(lineages))
+	for _, lineage := range lineages {
+		membersByEvent := make(map[int]*types.ClassLineageMember, len(lineage.Members))
+		for _, member := range lineage.Members {
+			membersByEvent[member.EventID] = member
+		}
+
+		report := &types.ClassLineageReport{
+			LineageID:   lineage.ID,
+			LineageName: lineage.Name,
+			Events:      make([]*types.ClassLineageEventReport, 0, len(events)),
+		}
+		for _, event := range events {
+			member, ok := membersByEvent[event.ID]
+			if !ok {
+				continue
+			}
+
+			eventReport := &types.ClassLineageEventReport{
+				EventID:   event.ID,
+				EventName: event.Name,
+				ClassID:   member.ClassID,
+				ClassName: member.ClassName,
+			}
+			if summary, ok := summaries[event.ID][member.ClassID]; ok {
+				eventReport.TotalPoints = summary.TotalPoints
+				eventReport.RankingPoints = summary.RankingPoints
+				eventReport.CustomPoints = summary.CustomPoints
+				eventReport.Rank = summary.Rank
+			}
+			if s, ok := stats[event.ID][member.ClassID]; ok {
+				eventReport.Participants = s.Participants
+				eventReport.Registrations = s.Registrations
+				eventReport.FirstPlaces = s.FirstPlaces
+			}
+			report.Events = append(report.Events, eventReport)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// GetCompetitionReports 获取同名比赛项目跨届次对比报表
+// eventIDs: 参与对比的届次，为空时对比所有届次；name: 指定比赛名称，为空时返回所有比赛
+// 仍处于征集审核阶段（待审核、已拒绝、需修改）的比赛不参与对比
+func GetCompetitionReports(eventIDs []int, name string) ([]*types.CompetitionReport, error) {
+	db := database.GetDB()
+
+	events, err := getReportEvents(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*types.CompetitionReport, 0)
+	reportsByName := make(map[string]*types.CompetitionReport)
+	for _, event := range events {
+		query := db.Where("event_id = ? AND status NOT IN ?", event.ID,
+			[]types.CompetitionStatus{types.StatusPendingApproval, types.StatusRejected, types.StatusChangesRequested})
+		if name != "" {
+			query = query.Where("name = ?", name)
+		}
+
+		var competitions []*types.Competition
+		if err := query.Order("name ASC").Find(&competitions).Error; err != nil {
+			return nil, err
+		}
+
+		for _, comp := range competitions {
+			eventReport, err := buildCompetitionEventReport(event, comp)
+			if err != nil {
+				return nil, err
+			}
+
+			report, ok := reportsByName[comp.Name]
+			if !ok {
+				report = &types.CompetitionReport{
+					CompetitionName: comp.Name,
+					Events:          make([]*types.CompetitionEventReport, 0, len(events)),
+				}
+				reportsByName[comp.Name] = report
+				reports = append(reports, report)
+			}
+			report.Events = append(report.Events, eventReport)
+		}
+	}
+
+	return reports, nil
+}
+
+// buildCompetitionEventReport 统计比赛项目在所属届次的报名人数与第一名成绩
+func buildCompetitionEventReport(event *types.Event, comp *types.Competition) (*types.CompetitionEventReport, error) {
+	db := database.GetDB()
+
+	report := &types.CompetitionEventReport{
+		EventID:         event.ID,
+		EventName:       event.Name,
+		CompetitionID:   comp.ID,
+		Status:          comp.Status,
+		CompetitionType: comp.CompetitionType,
+		RankingMode:     comp.RankingMode,
+		Unit:            comp.Unit,
+		Winners:         []string{},
+	}
+
+	var registrationCount int64
+	if err := db.Model(&types.Registration{}).Where("competition_id = ?", comp.ID).Count(&registrationCount).Error; err != nil {
+		return nil, err
+	}
+	report.Participants = int(registrationCount)
+
+	// 仅已完成（成绩已审核）的比赛给出第一名成绩
+	if comp.Status != types.StatusCompleted {
+		return report, nil
+	}
+
+	var winners []*types.Score
+	if err := db.Preload("Student").Preload("Class").
+		Where("competition_id = ? AND ranking = 1", comp.ID).
+		Find(&winners).Error; err != nil {
+		return nil, err
+	}
+
+	for _, score := range winners {
+		winningScore := score.Score
+		report.WinningScore = &winningScore
+		if score.Student != nil {
+			report.Winners = append(report.Winners, score.Student.FullName)
+		} else if score.Class != nil {
+			report.Winners = append(report.Winners, score.Class.Name)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+// 日程相关的错误定义
+var (
+	ErrScheduleEntryNotFound = errors.New("日程条目不存在")
+	ErrScheduleDraftEmpty    = errors.New("当前没有日程草稿")
+)
+
+// GetSchedulableCompetitions 获取当前运动会中可排程的比赛项目（审核通过和已延期的项目）
+func GetSchedulableCompetitions() ([]*types.Competition, error) {
+	db := database.GetDB()
+
+	var competitions []*types.Competition
+	err := db.Where("event_id = ? AND status IN ?", config.Get().CurrentEventID, []types.CompetitionStatus{types.StatusApproved, types.StatusPostponed}).
+		Order("id ASC").
+		Find(&competitions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return competitions, nil
+}
+
+// GetRegistrationsByCompetitionIDs 获取指定比赛项目的所有报名记录
+func GetRegistrationsByCompetitionIDs(competitionIDs []int) ([]*types.Registration, error) {
+	db := database.GetDB()
+
+	var registrations []*types.Registration
+	if len(competitionIDs) == 0 {
+		return registrations, nil
+	}
+	if err := db.Where("competition_id IN ?", competitionIDs).Find(&registrations).Error; err != nil {
+		return nil, err
+	}
+
+	return registrations, nil
+}
+
+// GetScheduleDraft 获取当前运动会的日程草稿
+func GetScheduleDraft() ([]*types.ScheduleEntry, error) {
+	db := database.GetDB()
+
+	var entries []*types.ScheduleEntry
+	err := db.Preload("Competition").Preload("Venue").
+		Where("event_id = ?", config.Get().CurrentEventID).
+		Order("start_time ASC, id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		entry.CompetitionName = entry.Competition.Name
+		if entry.Venue != nil {
+			entry.VenueName = entry.Venue.Name
+		}
+	}
+	return entries, nil
+}
+
+// SaveScheduleDraft 保存日程草稿（覆盖当前运动会已有的草稿）
+func SaveScheduleDraft(entries []*types.ScheduleEntry) error {
+	db := database.GetDB()
+	currentEventID := config.Get().CurrentEventID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", currentEventID).Delete(&types.ScheduleEntry{}).Error; err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			entry.ID = 0
+			entry.EventID = currentEventID
+			if err := tx.Omit("Competition", "Venue").Create(entry).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateScheduleEntry 手动调整日程草稿条目
+func UpdateScheduleEntry(id int, venueID *int, startTime, endTime time.Time) error {
+	db := database.GetDB()
+
+	if !startTime.Before(endTime) {
+		return errors.New("开始时间必须早于结束时间")
+	}
+
+	// 检查场地开放时间
+	if venueID != nil {
+		venue, err := GetVenueByID(*venueID)
+		if err != nil {
+			return err
+		}
+		if !utils.IsWithinVenueHours(venue, startTime, endTime) {
+			return utils.ErrVenueClosed
+		}
+	}
+
+	result := db.Model(&types.ScheduleEntry{}).
+		Where("id = ? AND event_id = ?", id, config.Get().CurrentEventID).
+		Updates(map[string]interface{}{
+			"venue_id":   venueID,
+			"start_time": startTime,
+			"end_time":   endTime,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrScheduleEntryNotFound
+	}
+	return nil
+}
+
+// DeleteScheduleDraft 放弃当前运动会的日程草稿
+func DeleteScheduleDraft() error {
+	db := database.GetDB()
+	return db.Where("event_id = ?", config.Get().CurrentEventID).Delete(&types.ScheduleEntry{}).Error
+}
+
+// PublishScheduleDraft 发布日程草稿，将时间写入比赛项目并清除草稿
+// 场地不在开放时间内或与其他项目重叠时整体回滚，不发布任何项目
+func PublishScheduleDraft() (int, error) {
+	db := database.GetDB()
+	currentEventID := config.Get().CurrentEventID
+
+	var count int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var entries []types.ScheduleEntry
+		if err := tx.Preload("Competition").Where("event_id = ?", currentEventID).Find(&entries).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return ErrScheduleDraftEmpty
+		}
+
+		for _, entry := range entries {
+			if err := tx.Model(&types.Competition{}).Where("id = ?", entry.CompetitionID).Updates(map[string]interface{}{
+				"start_time": entry.StartTime,
+				"end_time":   entry.EndTime,
+				"venue_id":   entry.VenueID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 全部写入后再检查场地开放时间与占用情况，草稿中的项目之间及与其他项目之间均不能重叠
+		validator := utils.NewCompetitionValidator(tx)
+		for _, entry := range entries {
+			if err := validator.ValidateVenueBooking(entry.CompetitionID, entry.VenueID, &entry.StartTime, &entry.EndTime); err != nil {
+				return fmt.Errorf("项目「%s」%w", entry.Competition.Name, err)
+			}
+		}
+		count = len(entries)
+
+		return tx.Where("event_id = ?", currentEventID).Delete(&types.ScheduleEntry{}).Error
+	})
+
+	return count, err
+}
@@ -3,14 +3,38 @@ package models
 import (
 	"errors"
 
-	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/database"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"gorm.io/gorm"
 )
 
+var ErrScoreOutOfClassScope = errors.New("只能处理所管理班级的成绩")
+
+// scoreClassScopeCondition 班级scope内成绩的查询条件：个人赛按学生报名时的班级，团体赛按成绩所属班级
+const scoreClassScopeCondition = "(scores.class_id IN ? OR EXISTS (SELECT 1 FROM registrations r WHERE r.competition_id = scores.competition_id AND r.student_id = scores.student_id AND r.class_id IN ?))"
+
+// applyScoreClassScope 将成绩查询限定在班级scope内，scopeClassIDs 为 nil 时不限制
+func applyScoreClassScope(query *gorm.DB, scopeClassIDs *[]int) *gorm.DB {
+	if scopeClassIDs == nil {
+		return query
+	}
+	return query.Where(scoreClassScopeCondition, *scopeClassIDs, *scopeClassIDs)
+}
+
+// containsClassID 判断班级ID是否在列表中
+func containsClassID(classIDs []int, classID int) bool {
+	for _, id := range classIDs {
+		if id == classID {
+			return true
+		}
+	}
+	return false
+}
+
 // CreateOrUpdateScores 批量提交比赛成绩
-func CreateOrUpdateScores(competitionID int, scores []types.StudentScore, submitterID int) error {
+// scopeClassIDs 不为 nil 时只能提交scope内班级的成绩，且只替换这些班级的原有成绩，其他班级的成绩保持不变
+func CreateOrUpdateScores(competitionID int, scores []types.StudentScore, submitterID int, scopeClassIDs *[]int) error {
 	// 获取数据库连接
 	db := database.GetDB()
 
@@ -23,14 +47,14 @@ func CreateOrUpdateScores(competitionID int, scores []types.StudentScore, submit
 		return err
 	}
 
-	if competition.Status == types.StatusRejected || competition.Status == types.StatusPendingApproval {
-		return errors.New("该项目当前状态不允许录入成绩")
+	if !utils.IsCompetitionStatusValidForScoreInput(competition.Status) {
+		return utils.ErrInvalidStatusForScoreInput
 	}
 
 	// 使用事务处理成绩录入
 	err := db.Transaction(func(tx *gorm.DB) error {
-		// 删除该比赛的所有现有成绩
-		if err := tx.Where("competition_id = ?", competitionID).Delete(&types.Score{}).Error; err != nil {
+		// 删除该比赛的现有成绩（限定班级scope时只删除scope内的成绩）
+		if err := applyScoreClassScope(tx.Where("competition_id = ?", competitionID), scopeClassIDs).Delete(&types.Score{}).Error; err != nil {
 			return err
 		}
 
@@ -48,12 +72,17 @@ func CreateOrUpdateScores(competitionID int, scores []types.StudentScore, submit
 				if studentScore.StudentID == nil {
 					continue
 				}
-				var regCount int64
-				if err := tx.Model(&types.Registration{}).Where("student_id = ? AND competition_id = ?", *studentScore.StudentID, competitionID).Count(&regCount).Error; err != nil {
+				var registration types.Registration
+				err := tx.Select("class_id").Where("student_id = ? AND competition_id = ?", *studentScore.StudentID, competitionID).First(&registration).Error
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				if err != nil {
 					return err
 				}
-				if regCount == 0 {
-					continue
+				// 限定班级scope时，学生报名时所在班级须在scope内
+				if scopeClassIDs != nil && (registration.ClassID == nil || !containsClassID(*scopeClassIDs, *registration.ClassID)) {
+					return ErrScoreOutOfClassScope
 				}
 
 				// 插入成绩记录
@@ -72,6 +101,9 @@ func CreateOrUpdateScores(competitionID int, scores []types.StudentScore, submit
 				if studentScore.ClassID == nil {
 					continue
 				}
+				if scopeClassIDs != nil && !containsClassID(*scopeClassIDs, *studentScore.ClassID) {
+					return ErrScoreOutOfClassScope
+				}
 
 				// 检查该班级是否有学生报名该比赛
 				var regCount int64
@@ -106,11 +138,17 @@ func CreateOrUpdateScores(competitionID int, scores []types.StudentScore, submit
 		}
 
 		// 更新比赛状态为等待成绩审核，并记录成绩提交人
-		return tx.Model(&types.Competition{}).Where("id = ?", competitionID).Updates(map[string]interface{}{
+		if err := tx.Model(&types.Competition{}).Where("id = ?", competitionID).Updates(map[string]interface{}{
 			"status":             types.StatusPendingScoreReview,
 			"score_submitter_id": submitterID,
 			"score_created_at":   gorm.Expr("CURRENT_TIMESTAMP"),
-		}).Error
+		}).Error; err != nil {
+			return err
+		}
+		if competition.Status == types.StatusPendingScoreReview {
+			return nil
+		}
+		return recordStatusChange(tx, competitionID, competition.Status, types.StatusPendingScoreReview, "", submitterID)
 	})
 	if err != nil {
 		return err
@@ -176,8 +214,8 @@ func CalculateRankingByCompetitionID(competitionID int) error {
 	})
 }
 
-// GetScoresByCompetitionID 获取比赛的所有成绩
-func GetScoresByCompetitionID(competitionID int) ([]*types.Score, error) {
+// GetScoresByCompetitionID 获取比赛的所有成绩，scopeClassIDs 不为 nil 时只返回scope内班级的成绩
+func GetScoresByCompetitionID(competitionID int, scopeClassIDs *[]int) ([]*types.Score, error) {
 	// 获取数据库连接
 	db := database.GetDB()
 
@@ -197,7 +235,8 @@ func GetScoresByCompetitionID(competitionID int) ([]*types.Score, error) {
 
 	// 执行查询，包含关联数据
 	var scores []*types.Score
-	err := db.Preload("Competition").Preload("Student.Class").Preload("Class").Where("competition_id = ?", competitionID).Order(order).Find(&scores).Error
+	query := db.Preload("Competition").Preload("Student.Class").Preload("Class").Where("competition_id = ?", competitionID)
+	err := applyScoreClassScope(query, scopeClassIDs).Order(order).Find(&scores).Error
 	if err != nil {
 		return nil, err
 	}
@@ -224,15 +263,11 @@ func GetScoresByCompetitionID(competitionID int) ([]*types.Score, error) {
 	return scores, nil
 }
 
-// GetScoresByStudentID 获取学生的所有成绩（包括个人赛和该学生参加的团体赛）
-func GetScoresByStudentID(studentID int) ([]*types.Score, error) {
+// GetScoresByStudentID 获取学生在指定届次的所有成绩（包括个人赛和该学生参加的团体赛）
+func GetScoresByStudentID(eventID, studentID int) ([]*types.Score, error) {
 	// 获取数据库连接
 	db := database.GetDB()
 
-	// 获取当前选中的 EventID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	// 先获取学生信息（需要知道班级ID）
 	var student types.Student
 	if err := db.Select("id", "class_id", "full_name").First(&student, studentID).Error; err != nil {
@@ -243,36 +278,23 @@ func GetScoresByStudentID(studentID int) ([]*types.Score, error) {
 	var individualScores []*types.Score
 	err := db.Preload("Competition").Preload("Student.Class").
 		Joins("JOIN competitions ON competitions.id = scores.competition_id").
-		Where("scores.student_id = ? AND competitions.event_id = ? AND competitions.status = ?", studentID, currentEventID, types.StatusCompleted).
+		Where("scores.student_id = ? AND competitions.event_id = ? AND competitions.status = ?", studentID, eventID, types.StatusCompleted).
 		Find(&individualScores).Error
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. 查询团体赛成绩（class_id = student.ClassID 且该学生确实报名了该比赛）
+	// 2. 查询团体赛成绩（该学生报名的团体比赛中其所在班级的成绩）
+	// 班级以报名时记录的为准，学生升级换班后仍能查到往届的团体赛成绩
 	var teamScores []*types.Score
-	if student.ClassID > 0 {
-		// 查询该学生报名的所有团体比赛ID
-		var registeredTeamCompetitionIDs []int
-		if err := db.Table("registrations r").
-			Select("DISTINCT c.id").
-			Joins("JOIN competitions c ON c.id = r.competition_id").
-			Where("r.student_id = ? AND c.competition_type = ? AND c.event_id = ?",
-				studentID, types.TypeTeam, currentEventID).
-			Pluck("c.id", &registeredTeamCompetitionIDs).Error; err != nil {
-			return nil, err
-		}
-
-		if len(registeredTeamCompetitionIDs) > 0 {
-			// 查询这些团体比赛的班级成绩，只返回已审核的成绩
-			err = db.Preload("Competition").Preload("Class").
-				Joins("JOIN competitions ON competitions.id = scores.competition_id").
-				Where("scores.class_id = ? AND scores.competition_id IN ? AND competitions.status = ?", student.ClassID, registeredTeamCompetitionIDs, types.StatusCompleted).
-				Find(&teamScores).Error
-			if err != nil {
-				return nil, err
-			}
-		}
+	err = db.Preload("Competition").Preload("Class").
+		Joins("JOIN competitions ON competitions.id = scores.competition_id").
+		Joins("JOIN registrations r ON r.competition_id = scores.competition_id AND r.student_id = ?", studentID).
+		Where("scores.class_id = COALESCE(r.class_id, ?) AND competitions.competition_type = ? AND competitions.event_id = ? AND competitions.status = ?",
+			student.ClassID, types.TypeTeam, eventID, types.StatusCompleted).
+		Find(&teamScores).Error
+	if err != nil {
+		return nil, err
 	}
 
 	// 合并个人赛和团体赛成绩
@@ -318,11 +340,14 @@ func ReviewCompetitionScoresByID(competitionID int, reviewerID int) error {
 		}
 
 		// 更新比赛状态为已完成，并记录审核人
-		return tx.Model(&types.Competition{}).Where("id = ?", competitionID).Updates(map[string]interface{}{
+		if err := tx.Model(&types.Competition{}).Where("id = ?", competitionID).Updates(map[string]interface{}{
 			"status":            types.StatusCompleted,
 			"score_reviewer_id": reviewerID,
 			"score_reviewed_at": gorm.Expr("CURRENT_TIMESTAMP"),
-		}).Error
+		}).Error; err != nil {
+			return err
+		}
+		return recordStatusChange(tx, competitionID, types.StatusPendingScoreReview, types.StatusCompleted, "", reviewerID)
 	})
 	if err != nil {
 		return err
@@ -338,30 +363,78 @@ func ReviewCompetitionScoresByID(competitionID int, reviewerID int) error {
 }
 
 // DeleteCompetitionScoresByID 删除比赛的所有成绩记录
-func DeleteCompetitionScoresByID(competitionID int) error {
+// operatorID: 操作人ID，用于记录状态变更
+// scopeClassIDs: 不为 nil 时只删除scope内班级的成绩，其他班级仍有成绩时比赛回到等待成绩审核
+func DeleteCompetitionScoresByID(competitionID, operatorID int, scopeClassIDs *[]int) error {
 	// 获取数据库连接
 	db := database.GetDB()
 
 	// 使用事务删除成绩记录
+	keepScores := false
 	err := db.Transaction(func(tx *gorm.DB) error {
-		// 删除成绩记录
-		if err := tx.Where("competition_id = ?", competitionID).Delete(&types.Score{}).Error; err != nil {
+		var competition types.Competition
+		if err := tx.Select("status").First(&competition, competitionID).Error; err != nil {
 			return err
 		}
 
+		// 删除成绩记录
+		result := applyScoreClassScope(tx.Where("competition_id = ?", competitionID), scopeClassIDs).Delete(&types.Score{})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 限定班级scope时，比赛中须有scope内班级的成绩
+		if scopeClassIDs != nil && result.RowsAffected == 0 {
+			return ErrScoreOutOfClassScope
+		}
+
+		// 只删除了部分班级的成绩时，其余成绩需重新审核
+		if scopeClassIDs != nil {
+			var remaining int64
+			if err := tx.Model(&types.Score{}).Where("competition_id = ?", competitionID).Count(&remaining).Error; err != nil {
+				return err
+			}
+			if remaining > 0 {
+				keepScores = true
+				if err := tx.Model(&types.Competition{}).Where("id = ?", competitionID).Updates(map[string]interface{}{
+					"status":            types.StatusPendingScoreReview,
+					"score_reviewer_id": nil,
+					"score_reviewed_at": nil,
+				}).Error; err != nil {
+					return err
+				}
+				if competition.Status == types.StatusPendingScoreReview {
+					return nil
+				}
+				return recordStatusChange(tx, competitionID, competition.Status, types.StatusPendingScoreReview, "删除班级成绩", operatorID)
+			}
+		}
+
 		// 更新比赛状态回到待上传
-		return tx.Model(&types.Competition{}).Where("id = ?", competitionID).Updates(map[string]interface{}{
+		if err := tx.Model(&types.Competition{}).Where("id = ?", competitionID).Updates(map[string]interface{}{
 			"status":             types.StatusApproved,
 			"score_submitter_id": nil,
 			"score_reviewer_id":  nil,
 			"score_reviewed_at":  nil,
 			"score_created_at":   nil,
-		}).Error
+		}).Error; err != nil {
+			return err
+		}
+		if competition.Status == types.StatusApproved {
+			return nil
+		}
+		return recordStatusChange(tx, competitionID, competition.Status, types.StatusApproved, "删除成绩", operatorID)
 	})
 	if err != nil {
 		return err
 	}
 
+	// 仍有其他班级的成绩时，重新计算排名
+	if keepScores {
+		if err := CalculateRankingByCompetitionID(competitionID); err != nil {
+			return err
+		}
+	}
+
 	// 删除成绩后，清空该比赛的排名得分
 	return RecalculatePointsByCompetitionID(competitionID)
 }
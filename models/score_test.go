@@ -0,0 +1,228 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+)
+
+// scoreTestData 班级成绩测试数据：两个班级各一名报名学生
+type scoreTestData struct {
+	competitionID int
+	classA        int
+	classB        int
+	studentA      int
+	studentB      int
+}
+
+// newScoreTestData 创建比赛、两个班级与各班一名报名学生
+func newScoreTestData(t *testing.T, competitionType types.CompetitionType) *scoreTestData {
+	t.Helper()
+	clearTestTables(t, &types.Points{}, &types.CompetitionStatusLog{}, &types.Score{}, &types.Registration{},
+		&types.Competition{}, &types.Student{}, &types.Class{})
+	db := database.GetDB()
+
+	data := &scoreTestData{}
+	for i, name := range []string{"高一(1)班", "高一(2)班"} {
+		class := types.Class{Name: name}
+		if err := db.Create(&class).Error; err != nil {
+			t.Fatalf("创建班级失败: %v", err)
+		}
+		student := types.Student{Username: name, Password: "-", FullName: name + "学生", Gender: 1, ClassID: class.ID}
+		if err := db.Create(&student).Error; err != nil {
+			t.Fatalf("创建学生失败: %v", err)
+		}
+		if i == 0 {
+			data.classA, data.studentA = class.ID, student.ID
+		} else {
+			data.classB, data.studentB = class.ID, student.ID
+		}
+	}
+
+	competition := types.Competition{Name: "趣味接力", Status: types.StatusApproved, CompetitionType: competitionType}
+	if err := db.Create(&competition).Error; err != nil {
+		t.Fatalf("创建比赛失败: %v", err)
+	}
+	data.competitionID = competition.ID
+
+	for _, ids := range [][2]int{{data.studentA, data.classA}, {data.studentB, data.classB}} {
+		studentID, classID := ids[0], ids[1]
+		registration := types.Registration{StudentID: &studentID, ClassID: &classID, CompetitionID: competition.ID}
+		if err := db.Create(&registration).Error; err != nil {
+			t.Fatalf("创建报名失败: %v", err)
+		}
+	}
+	return data
+}
+
+// studentScore 构建个人赛成绩
+func studentScore(studentID int, score float64) types.StudentScore {
+	return types.StudentScore{StudentID: &studentID, Score: score}
+}
+
+// classScore 构建团体赛成绩
+func classScore(classID int, score float64) types.StudentScore {
+	return types.StudentScore{ClassID: &classID, Score: score}
+}
+
+// scoreRankings 按学生ID（个人赛）或班级ID（团体赛）返回比赛的成绩与排名
+func scoreRankings(t *testing.T, competitionID int) map[int][2]float64 {
+	t.Helper()
+
+	var scores []types.Score
+	if err := database.GetDB().Where("competition_id = ?", competitionID).Find(&scores).Error; err != nil {
+		t.Fatalf("查询成绩失败: %v", err)
+	}
+	result := make(map[int][2]float64)
+	for _, score := range scores {
+		if score.StudentID != nil {
+			result[*score.StudentID] = [2]float64{score.Score, float64(score.Ranking)}
+		} else if score.ClassID != nil {
+			result[*score.ClassID] = [2]float64{score.Score, float64(score.Ranking)}
+		}
+	}
+	return result
+}
+
+func TestCreateOrUpdateScoresClassScope(t *testing.T) {
+	data := newScoreTestData(t, types.TypeIndividual)
+	scopeA := []int{data.classA}
+
+	if err := CreateOrUpdateScores(data.competitionID, []types.StudentScore{
+		studentScore(data.studentA, 10), studentScore(data.studentB, 12),
+	}, 1, nil); err != nil {
+		t.Fatalf("全局用户提交成绩失败: %v", err)
+	}
+
+	// 只替换本班成绩，其他班级的成绩保留并重新排名
+	if err := CreateOrUpdateScores(data.competitionID, []types.StudentScore{studentScore(data.studentA, 15)}, 2, &scopeA); err != nil {
+		t.Fatalf("提交本班成绩失败: %v", err)
+	}
+	want := map[int][2]float64{data.studentA: {15, 1}, data.studentB: {12, 2}}
+	if got := scoreRankings(t, data.competitionID); len(got) != 2 || got[data.studentA] != want[data.studentA] || got[data.studentB] != want[data.studentB] {
+		t.Fatalf("成绩与排名 = %v，应为 %v", got, want)
+	}
+
+	// 包含其他班级的学生时整批拒绝，原有成绩不变
+	for _, scores := range [][]types.StudentScore{
+		{studentScore(data.studentB, 20)},
+		{studentScore(data.studentA, 30), studentScore(data.studentB, 20)},
+	} {
+		if err := CreateOrUpdateScores(data.competitionID, scores, 2, &scopeA); !errors.Is(err, ErrScoreOutOfClassScope) {
+			t.Fatalf("提交其他班级成绩的错误 = %v，应为 %v", err, ErrScoreOutOfClassScope)
+		}
+	}
+	if got := scoreRankings(t, data.competitionID); len(got) != 2 || got[data.studentA] != want[data.studentA] || got[data.studentB] != want[data.studentB] {
+		t.Fatalf("拒绝提交后成绩与排名 = %v，应保持 %v", got, want)
+	}
+
+	// 只能查看本班成绩
+	scores, err := GetScoresByCompetitionID(data.competitionID, &scopeA)
+	if err != nil {
+		t.Fatalf("GetScoresByCompetitionID() error = %v", err)
+	}
+	if len(scores) != 1 || scores[0].StudentID == nil || *scores[0].StudentID != data.studentA {
+		t.Errorf("限定班级查看的成绩 = %+v，应只有本班学生 %d", scores, data.studentA)
+	}
+}
+
+func TestCreateOrUpdateTeamScoresClassScope(t *testing.T) {
+	data := newScoreTestData(t, types.TypeTeam)
+	scopeA := []int{data.classA}
+	scopeB := []int{data.classB}
+
+	if err := CreateOrUpdateScores(data.competitionID, []types.StudentScore{
+		classScore(data.classA, 5), classScore(data.classB, 8),
+	}, 1, nil); err != nil {
+		t.Fatalf("全局用户提交成绩失败: %v", err)
+	}
+
+	if err := CreateOrUpdateScores(data.competitionID, []types.StudentScore{classScore(data.classB, 10)}, 2, &scopeA); !errors.Is(err, ErrScoreOutOfClassScope) {
+		t.Fatalf("提交其他班级成绩的错误 = %v，应为 %v", err, ErrScoreOutOfClassScope)
+	}
+
+	if err := CreateOrUpdateScores(data.competitionID, []types.StudentScore{classScore(data.classA, 9)}, 2, &scopeA); err != nil {
+		t.Fatalf("提交本班成绩失败: %v", err)
+	}
+	want := map[int][2]float64{data.classA: {9, 1}, data.classB: {8, 2}}
+	if got := scoreRankings(t, data.competitionID); len(got) != 2 || got[data.classA] != want[data.classA] || got[data.classB] != want[data.classB] {
+		t.Fatalf("成绩与排名 = %v，应为 %v", got, want)
+	}
+
+	scores, err := GetScoresByCompetitionID(data.competitionID, &scopeB)
+	if err != nil {
+		t.Fatalf("GetScoresByCompetitionID() error = %v", err)
+	}
+	if len(scores) != 1 || scores[0].ClassID == nil || *scores[0].ClassID != data.classB {
+		t.Errorf("限定班级查看的成绩 = %+v，应只有本班 %d", scores, data.classB)
+	}
+}
+
+func TestDeleteCompetitionScoresClassScope(t *testing.T) {
+	data := newScoreTestData(t, types.TypeIndividual)
+	db := database.GetDB()
+
+	if err := CreateOrUpdateScores(data.competitionID, []types.StudentScore{
+		studentScore(data.studentA, 10), studentScore(data.studentB, 12),
+	}, 1, nil); err != nil {
+		t.Fatalf("提交成绩失败: %v", err)
+	}
+	if err := ReviewCompetitionScoresByID(data.competitionID, 1); err != nil {
+		t.Fatalf("审核成绩失败: %v", err)
+	}
+	var points int64
+	db.Model(&types.Points{}).Where("competition_id = ?", data.competitionID).Count(&points)
+	if points != 4 {
+		t.Fatalf("审核后的得分记录 = %d，应为 4（两名学生及其班级各一条）", points)
+	}
+
+	// scope 内没有成绩时拒绝删除
+	scopeNone := []int{data.classA + data.classB}
+	if err := DeleteCompetitionScoresByID(data.competitionID, 2, &scopeNone); !errors.Is(err, ErrScoreOutOfClassScope) {
+		t.Fatalf("删除 scope 外成绩的错误 = %v，应为 %v", err, ErrScoreOutOfClassScope)
+	}
+
+	// 删除本班成绩后，其他班级的成绩重新排名并回到等待成绩审核
+	scopeB := []int{data.classB}
+	if err := DeleteCompetitionScoresByID(data.competitionID, 2, &scopeB); err != nil {
+		t.Fatalf("删除本班成绩失败: %v", err)
+	}
+	want := map[int][2]float64{data.studentA: {10, 1}}
+	if got := scoreRankings(t, data.competitionID); len(got) != 1 || got[data.studentA] != want[data.studentA] {
+		t.Fatalf("删除后的成绩与排名 = %v，应为 %v", got, want)
+	}
+
+	var competition types.Competition
+	if err := db.First(&competition, data.competitionID).Error; err != nil {
+		t.Fatalf("查询比赛失败: %v", err)
+	}
+	if competition.Status != types.StatusPendingScoreReview || competition.ScoreReviewerID != nil || competition.ScoreReviewedAt != nil {
+		t.Errorf("比赛状态 = %s（审核人 %v），应回到等待成绩审核并清除审核信息", competition.Status, competition.ScoreReviewerID)
+	}
+	db.Model(&types.Points{}).Where("competition_id = ?", data.competitionID).Count(&points)
+	if points != 0 {
+		t.Errorf("回到等待成绩审核后的得分记录 = %d，应为 0", points)
+	}
+	var logCount int64
+	db.Model(&types.CompetitionStatusLog{}).Where("competition_id = ? AND from_status = ? AND to_status = ?",
+		data.competitionID, types.StatusCompleted, types.StatusPendingScoreReview).Count(&logCount)
+	if logCount != 1 {
+		t.Errorf("状态变更记录 = %d，应为 1", logCount)
+	}
+
+	// 全局用户删除全部成绩后回到待上传
+	if err := DeleteCompetitionScoresByID(data.competitionID, 1, nil); err != nil {
+		t.Fatalf("删除全部成绩失败: %v", err)
+	}
+	if got := scoreRankings(t, data.competitionID); len(got) != 0 {
+		t.Errorf("删除全部成绩后仍有成绩 %v", got)
+	}
+	if err := db.First(&competition, data.competitionID).Error; err != nil {
+		t.Fatalf("查询比赛失败: %v", err)
+	}
+	if competition.Status != types.StatusApproved {
+		t.Errorf("比赛状态 = %s，应为 %s", competition.Status, types.StatusApproved)
+	}
+}
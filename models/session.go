@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("会话不存在")
+	ErrSessionInvalid      = errors.New("登录已失效，请重新登录")
+	ErrRefreshTokenInvalid = errors.New("无效的刷新令牌")
+	ErrRefreshTokenReused  = errors.New("刷新令牌已被使用，为保护账号安全已退出该登录，请重新登录")
+)
+
+// sessionTouchInterval 更新会话最近使用时间的最小间隔，避免每个请求都写数据库
+const sessionTouchInterval = time.Minute
+
+// CreateSession 创建登录会话及首个刷新令牌，并清理该用户已过期的会话
+func CreateSession(session *types.Session, refreshTokenHash string) error {
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&types.Session{}).Select("id").
+			Where("user_id = ? AND role = ? AND expires_at < ?", session.UserID, session.Role, time.Now())
+		if err := deleteSessions(tx, expired); err != nil {
+			return err
+		}
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Create(&types.RefreshToken{SessionID: session.ID, TokenHash: refreshTokenHash}).Error
+	})
+}
+
+// GetSessionByRefreshToken 通过刷新令牌获取会话（不校验令牌是否已使用，由 RotateRefreshToken 处理）
+func GetSessionByRefreshToken(tokenHash string) (*types.Session, error) {
+	db := database.GetDB()
+
+	var session types.Session
+	err := db.Joins("JOIN refresh_tokens ON refresh_tokens.session_id = sessions.id").
+		Where("refresh_tokens.token_hash = ?", tokenHash).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// RotateRefreshToken 使用刷新令牌换取新的刷新令牌
+// 已使用过的刷新令牌再次出现时视为被盗用，撤销整个会话
+func RotateRefreshToken(oldTokenHash, newTokenHash string) (*types.Session, error) {
+	db := database.GetDB()
+
+	var session types.Session
+	reused := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var refreshToken types.RefreshToken
+		if err := tx.Where("token_hash = ?", oldTokenHash).First(&refreshToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+
+		if err := tx.First(&session, refreshToken.SessionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+
+		now := time.Now()
+		if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+			return ErrSessionInvalid
+		}
+
+		// 重复使用：撤销会话，并在事务提交后返回错误
+		if refreshToken.UsedAt != nil {
+			reused = true
+			return tx.Model(&session).Update("revoked_at", now).Error
+		}
+
+		if err := tx.Model(&refreshToken).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&session).UpdateColumn("last_seen_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&types.RefreshToken{SessionID: session.ID, TokenHash: newTokenHash}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+
+	return &session, nil
+}
+
+// deleteSessions 删除会话及其刷新令牌，sessionIDs 为会话ID子查询
+func deleteSessions(tx *gorm.DB, sessionIDs *gorm.DB) error {
+	if err := tx.Where("session_id IN (?)", sessionIDs).Delete(&types.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN (?)", sessionIDs).Delete(&types.Session{}).Error
+}
+
+// deleteUserSessions 删除用户的所有会话（用于删除用户或学生）
+func deleteUserSessions(tx *gorm.DB, userID int, role string) error {
+	return deleteSessions(tx, tx.Model(&types.Session{}).Select("id").Where("user_id = ? AND role = ?", userID, role))
+}
+
+// ValidateSession 验证令牌对应的会话是否有效，并更新最近使用时间
+func ValidateSession(tokenID string, userID int, role string) (*types.Session, error) {
+	db := database.GetDB()
+
+	var session types.Session
+	if err := db.Where("token_id = ?", tokenID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.UserID != userID || session.Role != role || session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return nil, ErrSessionInvalid
+	}
+
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := db.Model(&session).UpdateColumn("last_seen_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &session, nil
+}
+
+// GetActiveSessions 获取用户所有未撤销且未过期的会话
+func GetActiveSessions(userID int, role string) ([]*types.Session, error) {
+	db := database.GetDB()
+
+	var sessions []*types.Session
+	if err := db.Where("user_id = ? AND role = ? AND revoked_at IS NULL AND expires_at > ?", userID, role, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的指定会话
+func RevokeSession(sessionID, userID int, role string) error {
+	db := database.GetDB()
+
+	result := db.Model(&types.Session{}).
+		Where("id = ? AND user_id = ? AND role = ? AND revoked_at IS NULL", sessionID, userID, role).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeUserSessions 撤销用户的所有会话，返回撤销的会话数量
+func RevokeUserSessions(userID int, role string) (int64, error) {
+	return revokeUserSessions(database.GetDB(), userID, role)
+}
+
+// revokeUserSessions 在指定连接（可为事务）中撤销用户的所有会话
+func revokeUserSessions(db *gorm.DB, userID int, role string) (int64, error) {
+	result := db.Model(&types.Session{}).
+		Where("user_id = ? AND role = ? AND revoked_at IS NULL", userID, role).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// revokeOtherSessions 撤销用户除指定会话外的所有会话（如修改密码后退出其他设备）
+func revokeOtherSessions(db *gorm.DB, userID int, role string, keepSessionID int) (int64, error) {
+	result := db.Model(&types.Session{}).
+		Where("user_id = ? AND role = ? AND id <> ? AND revoked_at IS NULL", userID, role, keepSessionID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
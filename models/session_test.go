@@ -0,0 +1,268 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+)
+
+// newTestSession 创建测试用会话，返回会话与首个刷新令牌
+func newTestSession(t *testing.T, userID int, role string, expiresAt time.Time) (*types.Session, string) {
+	t.Helper()
+
+	tokenID, err := utils.GenerateURLSafeToken(16)
+	if err != nil {
+		t.Fatalf("生成令牌ID失败: %v", err)
+	}
+	refreshToken, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		t.Fatalf("生成刷新令牌失败: %v", err)
+	}
+
+	session := &types.Session{
+		TokenID:    tokenID,
+		UserID:     userID,
+		Role:       role,
+		Method:     types.LoginMethodPassword,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	if err := CreateSession(session, utils.HashToken(refreshToken)); err != nil {
+		t.Fatalf("创建会话失败: %v", err)
+	}
+	return session, refreshToken
+}
+
+func TestValidateSession(t *testing.T) {
+	clearTestTables(t, &types.RefreshToken{}, &types.Session{})
+
+	active, _ := newTestSession(t, 1001, types.SessionRoleAdmin, time.Now().Add(time.Hour))
+	expired, _ := newTestSession(t, 1001, types.SessionRoleAdmin, time.Now().Add(-time.Minute))
+	revoked, _ := newTestSession(t, 1001, types.SessionRoleAdmin, time.Now().Add(time.Hour))
+	if err := RevokeSession(revoked.ID, 1001, types.SessionRoleAdmin); err != nil {
+		t.Fatalf("撤销会话失败: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		tokenID string
+		userID  int
+		role    string
+		wantErr error
+	}{
+		{"有效会话", active.TokenID, 1001, types.SessionRoleAdmin, nil},
+		{"令牌ID不存在", "missing", 1001, types.SessionRoleAdmin, ErrSessionInvalid},
+		{"用户不一致", active.TokenID, 1002, types.SessionRoleAdmin, ErrSessionInvalid},
+		{"角色不一致", active.TokenID, 1001, types.SessionRoleStudent, ErrSessionInvalid},
+		{"会话已过期", expired.TokenID, 1001, types.SessionRoleAdmin, ErrSessionInvalid},
+		{"会话已撤销", revoked.TokenID, 1001, types.SessionRoleAdmin, ErrSessionInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateSession(tt.tokenID, tt.userID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateSession() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	clearTestTables(t, &types.RefreshToken{}, &types.Session{})
+
+	tests := []struct {
+		name        string
+		ownerID     int
+		ownerRole   string
+		revokeID    int
+		revokeRole  string
+		revokeTwice bool
+		wantErr     error
+		wantRevoked bool
+	}{
+		{"撤销自己的会话", 1011, types.SessionRoleAdmin, 1011, types.SessionRoleAdmin, false, nil, true},
+		{"不能撤销其他用户的会话", 1012, types.SessionRoleAdmin, 1013, types.SessionRoleAdmin, false, ErrSessionNotFound, false},
+		{"ID相同但角色不同的会话不能撤销", 1014, types.SessionRoleStudent, 1014, types.SessionRoleAdmin, false, ErrSessionNotFound, false},
+		{"已撤销的会话不能再次撤销", 1015, types.SessionRoleAdmin, 1015, types.SessionRoleAdmin, true, ErrSessionNotFound, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, _ := newTestSession(t, tt.ownerID, tt.ownerRole, time.Now().Add(time.Hour))
+			if tt.revokeTwice {
+				if err := RevokeSession(session.ID, tt.revokeID, tt.revokeRole); err != nil {
+					t.Fatalf("首次撤销会话失败: %v", err)
+				}
+			}
+
+			err := RevokeSession(session.ID, tt.revokeID, tt.revokeRole)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RevokeSession() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// 撤销后令牌立即失效，未撤销的会话保持有效
+			_, err = ValidateSession(session.TokenID, tt.ownerID, tt.ownerRole)
+			if revoked := errors.Is(err, ErrSessionInvalid); revoked != tt.wantRevoked {
+				t.Errorf("ValidateSession() error = %v, want revoked = %v", err, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	clearTestTables(t, &types.RefreshToken{}, &types.Session{})
+
+	expiresAt := time.Now().Add(time.Hour)
+	first, _ := newTestSession(t, 1021, types.SessionRoleAdmin, expiresAt)
+	second, _ := newTestSession(t, 1021, types.SessionRoleAdmin, expiresAt)
+	student, _ := newTestSession(t, 1021, types.SessionRoleStudent, expiresAt)
+
+	count, err := RevokeUserSessions(1021, types.SessionRoleAdmin)
+	if err != nil {
+		t.Fatalf("RevokeUserSessions() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("RevokeUserSessions() = %d, want 2", count)
+	}
+
+	tests := []struct {
+		name    string
+		session *types.Session
+		wantErr error
+	}{
+		{"管理员会话已撤销", first, ErrSessionInvalid},
+		{"管理员的其他会话已撤销", second, ErrSessionInvalid},
+		{"同ID学生的会话不受影响", student, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateSession(tt.session.TokenID, tt.session.UserID, tt.session.Role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateSession() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	clearTestTables(t, &types.RefreshToken{}, &types.Session{})
+
+	expiresAt := time.Now().Add(time.Hour)
+	current, _ := newTestSession(t, 1031, types.SessionRoleStudent, expiresAt)
+	other, _ := newTestSession(t, 1031, types.SessionRoleStudent, expiresAt)
+
+	count, err := revokeOtherSessions(database.GetDB(), 1031, types.SessionRoleStudent, current.ID)
+	if err != nil {
+		t.Fatalf("revokeOtherSessions() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("revokeOtherSessions() = %d, want 1", count)
+	}
+
+	tests := []struct {
+		name    string
+		session *types.Session
+		wantErr error
+	}{
+		{"保留当前会话", current, nil},
+		{"其他会话已撤销", other, ErrSessionInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateSession(tt.session.TokenID, tt.session.UserID, tt.session.Role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateSession() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		expiresAt   time.Time
+		revoke      bool
+		rotateFirst bool // 先正常刷新一次，再重复使用原令牌
+		wantErr     error
+		wantRevoked bool
+	}{
+		{"正常刷新", time.Now().Add(time.Hour), false, false, nil, false},
+		{"刷新令牌被重复使用时撤销会话", time.Now().Add(time.Hour), false, true, ErrRefreshTokenReused, true},
+		{"会话已撤销", time.Now().Add(time.Hour), true, false, ErrSessionInvalid, true},
+		{"会话已过期", time.Now().Add(-time.Minute), false, false, ErrSessionInvalid, true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := 1041 + i
+			session, refreshToken := newTestSession(t, userID, types.SessionRoleAdmin, tt.expiresAt)
+			if tt.revoke {
+				if err := RevokeSession(session.ID, userID, types.SessionRoleAdmin); err != nil {
+					t.Fatalf("撤销会话失败: %v", err)
+				}
+			}
+			if tt.rotateFirst {
+				if _, err := RotateRefreshToken(utils.HashToken(refreshToken), utils.HashToken(refreshToken+"-1")); err != nil {
+					t.Fatalf("首次刷新失败: %v", err)
+				}
+			}
+
+			rotated, err := RotateRefreshToken(utils.HashToken(refreshToken), utils.HashToken(refreshToken+"-2"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RotateRefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && rotated.ID != session.ID {
+				t.Errorf("RotateRefreshToken() session = %d, want %d", rotated.ID, session.ID)
+			}
+
+			_, err = ValidateSession(session.TokenID, userID, types.SessionRoleAdmin)
+			if revoked := errors.Is(err, ErrSessionInvalid); revoked != tt.wantRevoked {
+				t.Errorf("ValidateSession() error = %v, want revoked = %v", err, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestRotateRefreshTokenChain(t *testing.T) {
+	session, first := newTestSession(t, 1051, types.SessionRoleStudent, time.Now().Add(time.Hour))
+	second := first + "-2"
+	third := first + "-3"
+
+	if _, err := RotateRefreshToken(utils.HashToken(first), utils.HashToken(second)); err != nil {
+		t.Fatalf("第一次刷新失败: %v", err)
+	}
+	if _, err := RotateRefreshToken(utils.HashToken(second), utils.HashToken(third)); err != nil {
+		t.Fatalf("第二次刷新失败: %v", err)
+	}
+
+	// 被盗用的旧令牌再次出现后，会话被撤销，合法持有的最新令牌也随之失效
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"不存在的令牌", "unknown", ErrRefreshTokenInvalid},
+		{"重复使用第一个令牌", first, ErrRefreshTokenReused},
+		{"会话撤销后最新令牌失效", third, ErrSessionInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RotateRefreshToken(utils.HashToken(tt.token), utils.HashToken(tt.token+"-next"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RotateRefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := ValidateSession(session.TokenID, 1051, types.SessionRoleStudent); !errors.Is(err, ErrSessionInvalid) {
+		t.Errorf("ValidateSession() error = %v, want %v", err, ErrSessionInvalid)
+	}
+}
@@ -0,0 +1,296 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShortlistNotFound    = errors.New("入围报告不存在")
+	ErrShortlistNotPending  = errors.New("入围报告已确认或已作废")
+	ErrShortlistModeNotSet  = errors.New("本届运动会未设置自动入围方式")
+	ErrInvalidShortlistItem = errors.New("入围结果中包含不属于该报告的项目或无效的结果")
+)
+
+// GenerateShortlistReport 根据投票规则生成指定届次的入围报告
+// 仅在投票结束后生成，已存在的待确认报告会被作废，报告需项目管理员确认后才会生效
+func GenerateShortlistReport(eventID int) (*types.ShortlistReport, error) {
+	db := database.GetDB()
+
+	if _, err := GetEventByID(eventID); err != nil {
+		return nil, err
+	}
+
+	settings, err := utils.LoadEventSettings(db, eventID)
+	if err != nil {
+		return nil, err
+	}
+	rules := settings.VotingRules
+	if rules.ShortlistMode == types.ShortlistModeNone {
+		return nil, ErrShortlistModeNotSet
+	}
+	if !utils.IsVotingEnded(settings) {
+		return nil, utils.ErrVotingNotEnded
+	}
+
+	// 候选项目：本届待审核且未被合并的推荐项目，按得票从高到低排列
+	var competitions []types.Competition
+	if err := db.Where("event_id = ? AND status = ? AND merged_into_id IS NULL", eventID, types.StatusPendingApproval).
+		Order("vote_count DESC, id ASC").
+		Find(&competitions).Error; err != nil {
+		return nil, err
+	}
+
+	report := &types.ShortlistReport{
+		EventID:   eventID,
+		Mode:      rules.ShortlistMode,
+		TopK:      rules.ShortlistTopK,
+		Threshold: rules.ShortlistThreshold,
+		Status:    types.ShortlistStatusPending,
+		Items:     make([]*types.ShortlistItem, 0, len(competitions)),
+	}
+
+	// 计算名次（得票相同的名次相同）并确定入围结果
+	for i, competition := range competitions {
+		rank := i + 1
+		if i > 0 && competition.VoteCount == competitions[i-1].VoteCount {
+			rank = report.Items[i-1].Rank
+		}
+
+		decision := types.ShortlistDecisionReject
+		switch rules.ShortlistMode {
+		case types.ShortlistModeTopK:
+			// 与第K名票数相同的项目一并入围
+			if rank <= rules.ShortlistTopK {
+				decision = types.ShortlistDecisionApprove
+			}
+		case types.ShortlistModeThreshold:
+			if competition.VoteCount >= rules.ShortlistThreshold {
+				decision = types.ShortlistDecisionApprove
+			}
+		}
+
+		report.Items = append(report.Items, &types.ShortlistItem{
+			CompetitionID:   competition.ID,
+			CompetitionName: competition.Name,
+			VoteCount:       competition.VoteCount,
+			Rank:            rank,
+			Decision:        decision,
+		})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// 作废之前尚未确认的报告
+		if err := tx.Model(&types.ShortlistReport{}).
+			Where("event_id = ? AND status = ?", eventID, types.ShortlistStatusPending).
+			Update("status", types.ShortlistStatusDiscarded).Error; err != nil {
+			return err
+		}
+		return tx.Create(report).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GenerateDueShortlistReports 为投票已结束、设置了自动入围且尚未生成报告的届次生成入围报告
+func GenerateDueShortlistReports() (int, error) {
+	db := database.GetDB()
+
+	var events []types.Event
+	if err := db.Where("shortlist_mode <> ''").Find(&events).Error; err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for _, event := range events {
+		if !utils.IsVotingEnded(&event.Settings) {
+			continue
+		}
+
+		// 每个届次只自动生成一次，之后由项目管理员手动重新生成
+		var count int64
+		if err := db.Model(&types.ShortlistReport{}).Where("event_id = ?", event.ID).Count(&count).Error; err != nil {
+			return generated, err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if _, err := GenerateShortlistReport(event.ID); err != nil {
+			return generated, err
+		}
+		generated++
+	}
+
+	return generated, nil
+}
+
+// GetShortlistReports 获取指定届次的入围报告列表（不含项目明细）
+func GetShortlistReports(eventID int) ([]*types.ShortlistReport, error) {
+	db := database.GetDB()
+
+	var reports []*types.ShortlistReport
+	if err := db.Where("event_id = ?", eventID).Order("id DESC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	for _, report := range reports {
+		if err := fillShortlistConfirmedByName(db, report); err != nil {
+			return nil, err
+		}
+	}
+
+	return reports, nil
+}
+
+// GetShortlistReportByID 获取入围报告及项目明细
+func GetShortlistReportByID(id int) (*types.ShortlistReport, error) {
+	db := database.GetDB()
+
+	var report types.ShortlistReport
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("rank ASC, id ASC")
+	}).First(&report, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShortlistNotFound
+		}
+		return nil, err
+	}
+
+	if err := fillShortlistConfirmedByName(db, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// fillShortlistConfirmedByName 填充入围报告确认人姓名
+func fillShortlistConfirmedByName(db *gorm.DB, report *types.ShortlistReport) error {
+	if report.ConfirmedByID == nil {
+		return nil
+	}
+	var user types.User
+	if err := db.Select("id", "full_name").First(&user, *report.ConfirmedByID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	report.ConfirmedByName = user.FullName
+	return nil
+}
+
+// ConfirmShortlistReport 确认入围报告并批量审核推荐项目
+// decisions 可覆盖报告中个别项目的入围结果（键为比赛项目ID）
+// 确认时已不再待审核的项目（如已被人工审核或合并）将被跳过
+func ConfirmShortlistReport(id, operatorID int, decisions map[int]types.ShortlistDecision) (*types.ShortlistReport, error) {
+	db := database.GetDB()
+
+	report, err := GetShortlistReportByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查覆盖的入围结果
+	itemsByCompetition := make(map[int]*types.ShortlistItem, len(report.Items))
+	for _, item := range report.Items {
+		itemsByCompetition[item.CompetitionID] = item
+	}
+	for competitionID, decision := range decisions {
+		item, ok := itemsByCompetition[competitionID]
+		if !ok || (decision != types.ShortlistDecisionApprove && decision != types.ShortlistDecisionReject) {
+			return nil, ErrInvalidShortlistItem
+		}
+		item.Decision = decision
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// 仅更新仍待确认的报告，防止同一报告被并发确认两次
+		result := tx.Model(&types.ShortlistReport{}).
+			Where("id = ? AND status = ?", id, types.ShortlistStatusPending).
+			Updates(map[string]interface{}{
+				"status":          types.ShortlistStatusApplied,
+				"confirmed_at":    now,
+				"confirmed_by_id": operatorID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrShortlistNotPending
+		}
+
+		for _, item := range report.Items {
+			var competition types.Competition
+			if err := tx.Select("id", "status", "merged_into_id").First(&competition, item.CompetitionID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			item.Skipped = competition.ID == 0 || competition.Status != types.StatusPendingApproval || competition.MergedIntoID != nil
+			if err := tx.Model(item).Updates(map[string]interface{}{
+				"decision": item.Decision,
+				"skipped":  item.Skipped,
+			}).Error; err != nil {
+				return err
+			}
+			if item.Skipped {
+				continue
+			}
+
+			status := types.StatusApproved
+			reason := "投票入围"
+			if item.Decision == types.ShortlistDecisionReject {
+				status = types.StatusRejected
+				reason = "投票未入围"
+			}
+			if err := tx.Model(&types.Competition{}).Where("id = ?", item.CompetitionID).Updates(map[string]interface{}{
+				"status":        status,
+				"status_reason": reason,
+				"reviewed_at":   now,
+				"reviewer_id":   operatorID,
+			}).Error; err != nil {
+				return err
+			}
+			if err := recordStatusChange(tx, item.CompetitionID, competition.Status, status, reason, operatorID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetShortlistReportByID(id)
+}
+
+// DiscardShortlistReport 作废待确认的入围报告
+func DiscardShortlistReport(id int) error {
+	db := database.GetDB()
+
+	if _, err := GetShortlistReportByID(id); err != nil {
+		return err
+	}
+
+	// 仅作废仍待确认的报告，避免与确认操作同时进行时覆盖已生效的结果
+	result := db.Model(&types.ShortlistReport{}).
+		Where("id = ? AND status = ?", id, types.ShortlistStatusPending).
+		Update("status", types.ShortlistStatusDiscarded)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return ErrShortlistNotPending
+	}
+	return nil
+}
@@ -7,49 +7,56 @@ import (
 	"sync"
 	"time"
 
-	"github.com/SHXZ-OSS/sports-meeting-system/config"
 	"github.com/SHXZ-OSS/sports-meeting-system/database"
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
 )
 
+// statisticsCacheEntry 单个运动会届次的统计缓存
+type statisticsCacheEntry struct {
+	stats      *types.Statistics
+	hash       string
+	lastUpdate time.Time
+}
+
 var (
-	cachedStatistics     *types.Statistics
-	cachedStatisticsHash string
-	lastCacheUpdate      time.Time
+	statisticsCache      = make(map[int]*statisticsCacheEntry)
 	statisticsCacheMutex sync.RWMutex
 	cacheDuration        = 1 * time.Second
 )
 
-// getCompetitionCount 获取已完成和未完成的比赛数量
-func getCompetitionCount() (completed int, remaining int, err error) {
+// getCompetitionCount 获取指定届次已完成、未完成和已取消的比赛数量
+func getCompetitionCount(eventID int) (completed int, remaining int, cancelled int, err error) {
 	// 获取数据库连接
 	db := database.GetDB()
 
-	// 获取当前选中的 EventID
-	cfg := config.Get()
-	currentEventID := cfg.CurrentEventID
-
 	// 查询已完成的比赛数量
 	var completedCount int64
-	if err = db.Model(&types.Competition{}).Where("status = ? AND event_id = ?", types.StatusCompleted, currentEventID).Count(&completedCount).Error; err != nil {
-		return 0, 0, err
+	if err = db.Model(&types.Competition{}).Where("status = ? AND event_id = ?", types.StatusCompleted, eventID).Count(&completedCount).Error; err != nil {
+		return 0, 0, 0, err
 	}
 
-	// 查询待完成的比赛数量
+	// 查询待完成的比赛数量（包括进行中和已延期的比赛）
 	var remainingCount int64
-	if err = db.Model(&types.Competition{}).Where("(status = ? OR status = ?) AND event_id = ?", types.StatusApproved, types.StatusPendingScoreReview, currentEventID).Count(&remainingCount).Error; err != nil {
-		return 0, 0, err
+	remainingStatuses := []types.CompetitionStatus{types.StatusApproved, types.StatusInProgress, types.StatusPostponed, types.StatusPendingScoreReview}
+	if err = db.Model(&types.Competition{}).Where("status IN ? AND event_id = ?", remainingStatuses, eventID).Count(&remainingCount).Error; err != nil {
+		return 0, 0, 0, err
 	}
 
-	return int(completedCount), int(remainingCount), nil
+	// 查询已取消的比赛数量
+	var cancelledCount int64
+	if err = db.Model(&types.Competition{}).Where("status = ? AND event_id = ?", types.StatusCancelled, eventID).Count(&cancelledCount).Error; err != nil {
+		return 0, 0, 0, err
+	}
+
+	return int(completedCount), int(remainingCount), int(cancelledCount), nil
 }
 
-// GetStatistics 获取看板统计信息
-func GetStatistics() (*types.Statistics, error) {
+// GetStatistics 获取指定届次的看板统计信息
+func GetStatistics(eventID int) (*types.Statistics, error) {
 	// 尝试从缓存中读取
 	statisticsCacheMutex.RLock()
-	if cachedStatistics != nil && time.Since(lastCacheUpdate) < cacheDuration {
-		stats := cachedStatistics
+	if entry, ok := statisticsCache[eventID]; ok && time.Since(entry.lastUpdate) < cacheDuration {
+		stats := entry.stats
 		statisticsCacheMutex.RUnlock()
 		return stats, nil
 	}
@@ -60,15 +67,15 @@ func GetStatistics() (*types.Statistics, error) {
 	defer statisticsCacheMutex.Unlock()
 
 	// 双重检查，防止多个goroutine同时更新
-	if cachedStatistics != nil && time.Since(lastCacheUpdate) < cacheDuration {
-		return cachedStatistics, nil
+	if entry, ok := statisticsCache[eventID]; ok && time.Since(entry.lastUpdate) < cacheDuration {
+		return entry.stats, nil
 	}
 
 	// 初始化统计信息
 	stats := &types.Statistics{}
 
 	// 获取最新完成的比赛
-	latestComp, err := getLatestCompletedCompetition()
+	latestComp, err := getLatestCompletedCompetition(eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +83,7 @@ func GetStatistics() (*types.Statistics, error) {
 
 	// 获取最新成绩
 	if latestComp != nil {
-		latestScores, err := GetScoresByCompetitionID(latestComp.ID)
+		latestScores, err := GetScoresByCompetitionID(latestComp.ID, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -84,22 +91,23 @@ func GetStatistics() (*types.Statistics, error) {
 	}
 
 	// 获取比赛数量
-	completedCount, remainingCount, err := getCompetitionCount()
+	completedCount, remainingCount, cancelledCount, err := getCompetitionCount(eventID)
 	if err != nil {
 		return nil, err
 	}
 	stats.CompletedCompetitionCount = completedCount
 	stats.RemainingCompetitionCount = remainingCount
+	stats.CancelledCompetitionCount = cancelledCount
 
 	// 获取前10名班级
-	topClasses, err := GetTopClasses(10)
+	topClasses, err := GetTopClasses(eventID, 10)
 	if err != nil {
 		return nil, err
 	}
 	stats.TopClasses = topClasses
 
 	// 获取前10名学生
-	topStudents, err := GetTopStudents(10)
+	topStudents, err := GetTopStudents(eventID, 10)
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +120,11 @@ func GetStatistics() (*types.Statistics, error) {
 	}
 
 	// 更新缓存
-	cachedStatistics = stats
-	cachedStatisticsHash = hash
-	lastCacheUpdate = time.Now()
+	statisticsCache[eventID] = &statisticsCacheEntry{
+		stats:      stats,
+		hash:       hash,
+		lastUpdate: time.Now(),
+	}
 
 	return stats, nil
 }
@@ -132,9 +142,12 @@ func calculateStatisticsHash(stats *types.Statistics) (string, error) {
 	return fmt.Sprintf("%x", hash[:4]), nil
 }
 
-// GetStatisticsHash 获取当前缓存的统计数据哈希值
-func GetStatisticsHash() string {
+// GetStatisticsHash 获取指定届次缓存的统计数据哈希值
+func GetStatisticsHash(eventID int) string {
 	statisticsCacheMutex.RLock()
 	defer statisticsCacheMutex.RUnlock()
-	return cachedStatisticsHash
+	if entry, ok := statisticsCache[eventID]; ok {
+		return entry.hash
+	}
+	return ""
 }
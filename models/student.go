@@ -86,6 +86,8 @@ func CreateStudent(fullName string, gender int, classID int, dingTalkID string)
 		Gender:     gender,
 		ClassID:    classID,
 		DingTalkID: dingTalkID,
+		// 随机生成的初始密码，学生首次登录后须修改
+		MustChangePassword: true,
 	}
 
 	// 使用事务插入学生数据
@@ -173,6 +175,30 @@ func GetStudentByDingTalkID(dingTalkID string) (*types.Student, error) {
 	return &student, nil
 }
 
+// GetStudentByExternalID 根据外部身份ID（OIDC）获取学生
+func GetStudentByExternalID(externalID string) (*types.Student, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	// 查询学生，包含班级信息
+	var student types.Student
+	err := db.Preload("Class").Where("external_id = ?", externalID).First(&student).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("学生不存在")
+		}
+		return nil, err
+	}
+
+	// 设置班级名称
+	if student.Class.ID > 0 {
+		student.ClassName = student.Class.Name
+	}
+
+	return &student, nil
+}
+
 // GetAllStudents 获取所有学生，支持分页
 func GetAllStudents(page, pageSize int, scopeClassIDs *[]int, classID int) ([]*types.Student, int, error) {
 	// 获取数据库连接
@@ -232,7 +258,31 @@ func UpdateStudent(student *types.Student) error {
 	db := database.GetDB()
 
 	// 更新学生数据
-	return db.Select("full_name", "password", "gender", "class_id", "ding_talk_id").Where("id = ?", student.ID).Updates(student).Error
+	return db.Select("full_name", "password", "gender", "class_id", "ding_talk_id", "external_id", "must_change_password").Where("id = ?", student.ID).Updates(student).Error
+}
+
+// UpdateStudentPassword 更新学生密码（管理员重置），学生下次登录后须修改密码
+func UpdateStudentPassword(studentID int, newPassword string) error {
+	// 对新密码进行哈希处理
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// 获取数据库连接
+	db := database.GetDB()
+
+	// 更新密码，并撤销该学生已登录的会话
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.Student{}).Where("id = ?", studentID).Updates(map[string]interface{}{
+			"password":             string(hashedPassword),
+			"must_change_password": true,
+		}).Error; err != nil {
+			return err
+		}
+		_, err := revokeUserSessions(tx, studentID, types.SessionRoleStudent)
+		return err
+	})
 }
 
 // DeleteStudent 删除学生
@@ -252,6 +302,21 @@ func DeleteStudent(id int) error {
 			return err
 		}
 
+		// 删除学生的家长确认记录
+		if err := tx.Where("student_id = ?", id).Delete(&types.RegistrationConsent{}).Error; err != nil {
+			return err
+		}
+
+		// 删除学生的参赛资格限制记录
+		if err := tx.Where("student_id = ?", id).Delete(&types.StudentEligibility{}).Error; err != nil {
+			return err
+		}
+
+		// 删除学生的登录会话
+		if err := deleteUserSessions(tx, id, types.SessionRoleStudent); err != nil {
+			return err
+		}
+
 		// 删除学生
 		return tx.Delete(&types.Student{}, id).Error
 	})
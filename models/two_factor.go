@@ -0,0 +1,361 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled   = errors.New("两步验证已启用")
+	ErrTwoFactorNotSetup         = errors.New("请先生成两步验证密钥")
+	ErrTwoFactorNotEnabled       = errors.New("未启用两步验证")
+	ErrTwoFactorCodeIncorrect    = errors.New("验证码错误")
+	ErrTwoFactorRequired         = errors.New("当前账号须启用两步验证，不能关闭")
+	ErrTwoFactorChallengeInvalid = errors.New("验证已过期或错误次数过多，请重新登录")
+	ErrTwoFactorPasswordInvalid  = errors.New("密码错误")
+)
+
+const (
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// twoFactorChallengeDuration 账号密码、钉钉或统一身份认证验证通过后，提交验证码的有效期
+	twoFactorChallengeDuration = 5 * time.Minute
+	// maxTwoFactorAttempts 每次登录允许的验证码错误次数
+	maxTwoFactorAttempts = 5
+)
+
+// IsTwoFactorRequired 判断用户是否因拥有指定权限而须启用两步验证
+func IsTwoFactorRequired(user *types.User) bool {
+	return utils.HasPermission(user.Permission, config.Get().Security.TwoFactorRequiredPermissions)
+}
+
+// IsTwoFactorEnforcedMethod 判断该登录方式的会话是否须满足两步验证要求
+// 账号密码、钉钉与统一身份认证登录均须满足，避免通过其他登录方式绕过两步验证
+func IsTwoFactorEnforcedMethod(method string) bool {
+	return method == types.LoginMethodPassword || method == types.LoginMethodDingTalk || method == types.LoginMethodOIDC
+}
+
+// SetupTwoFactor 生成待确认的动态验证码密钥，需使用验证码确认后才会启用
+// 重复调用会生成新的密钥，之前生成的密钥作废
+func SetupTwoFactor(userID int) (*types.User, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取数据库连接
+	db := database.GetDB()
+	if err := db.Model(user).Updates(map[string]interface{}{
+		"totp_secret":         secret,
+		"totp_last_used_step": 0,
+	}).Error; err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = secret
+
+	return user, nil
+}
+
+// EnableTwoFactor 使用验证码确认密钥并启用两步验证，返回新生成的恢复码
+func EnableTwoFactor(userID int, code string) ([]string, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotSetup
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, user.TOTPLastUsedStep, time.Now())
+	if !ok {
+		return nil, ErrTwoFactorCodeIncorrect
+	}
+
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var codes []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"two_factor_enabled":  true,
+			"totp_last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor 关闭两步验证，需验证密码与验证码（或恢复码）
+func DisableTwoFactor(userID int, password, code string) error {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if IsTwoFactorRequired(user) {
+		return ErrTwoFactorRequired
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrTwoFactorPasswordInvalid
+	}
+
+	// 获取数据库连接
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := verifyTwoFactorCode(tx, user, code); err != nil {
+			return err
+		}
+		return clearTwoFactor(tx, user.ID)
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部作废
+func RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var codes []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := verifyTwoFactorCode(tx, user, code); err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// CountRecoveryCodes 获取剩余可用的恢复码数量
+func CountRecoveryCodes(userID int) (int64, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var count int64
+	err := db.Model(&types.TwoFactorRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// ResetTwoFactor 管理员重置用户的两步验证（用户丢失验证器且恢复码用尽时）
+func ResetTwoFactor(userID int) error {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return clearTwoFactor(tx, userID)
+	})
+}
+
+// CreateTwoFactorChallenge 账号密码、钉钉或统一身份认证验证通过后生成两步验证登录请求，同时清理已过期的请求
+func CreateTwoFactorChallenge(userID int, method string) (string, error) {
+	token, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	// 获取数据库连接
+	db := database.GetDB()
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", now).Delete(&types.TwoFactorChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&types.TwoFactorChallenge{
+			UserID:    userID,
+			TokenHash: utils.HashToken(token),
+			Method:    method,
+			ExpiresAt: now.Add(twoFactorChallengeDuration),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// GetTwoFactorChallengeUser 获取两步验证登录请求对应的用户（不校验验证码，用于登录失败限制）
+func GetTwoFactorChallengeUser(token string) (*types.User, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var challenge types.TwoFactorChallenge
+	if err := db.Where("token_hash = ?", utils.HashToken(token)).First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorChallengeInvalid
+		}
+		return nil, err
+	}
+
+	user, err := GetUserByID(challenge.UserID)
+	if err != nil {
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+	return user, nil
+}
+
+// CompleteTwoFactorChallenge 校验两步验证登录请求与验证码，成功后删除请求并返回用户与第一步使用的登录方式
+// 验证码错误次数达到上限后请求作废，需重新登录；验证码错误时同时返回用户，用于记录登录失败次数
+func CompleteTwoFactorChallenge(token, code string) (*types.User, string, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	var challenge types.TwoFactorChallenge
+	if err := db.Where("token_hash = ?", utils.HashToken(token)).First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrTwoFactorChallengeInvalid
+		}
+		return nil, "", err
+	}
+	if time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= maxTwoFactorAttempts {
+		db.Delete(&challenge)
+		return nil, "", ErrTwoFactorChallengeInvalid
+	}
+
+	user, err := GetUserByID(challenge.UserID)
+	if err != nil {
+		return nil, "", ErrTwoFactorChallengeInvalid
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := verifyTwoFactorCode(tx, user, code); err != nil {
+			return err
+		}
+		// 条件删除，防止同一请求被并发使用两次
+		result := tx.Where("id = ?", challenge.ID).Delete(&types.TwoFactorChallenge{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTwoFactorChallengeInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorCodeIncorrect) {
+			db.Model(&challenge).UpdateColumn("attempts", gorm.Expr("attempts + ?", 1))
+			return user, "", err
+		}
+		return nil, "", err
+	}
+
+	return user, challenge.Method, nil
+}
+
+// verifyTwoFactorCode 校验动态验证码或恢复码
+// 验证码按时间片只能使用一次；恢复码使用后标记为已使用
+func verifyTwoFactorCode(tx *gorm.DB, user *types.User, code string) error {
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	now := time.Now()
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, user.TOTPLastUsedStep, now); ok {
+		result := tx.Model(&types.User{}).
+			Where("id = ? AND totp_last_used_step < ?", user.ID, step).
+			Update("totp_last_used_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTwoFactorCodeIncorrect
+		}
+		return nil
+	}
+
+	// 尝试恢复码
+	normalized := utils.NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrTwoFactorCodeIncorrect
+	}
+	result := tx.Model(&types.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashToken(normalized)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTwoFactorCodeIncorrect
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 生成新的恢复码并替换用户原有的恢复码，返回恢复码明文（仅此一次）
+func replaceRecoveryCodes(tx *gorm.DB, userID int) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&types.TwoFactorRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]types.TwoFactorRecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = types.TwoFactorRecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(utils.NormalizeRecoveryCode(code)),
+		}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// clearTwoFactor 清除用户的两步验证设置、恢复码与未完成的登录请求
+func clearTwoFactor(tx *gorm.DB, userID int) error {
+	if err := tx.Model(&types.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"two_factor_enabled":  false,
+		"totp_secret":         "",
+		"totp_last_used_step": 0,
+	}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&types.TwoFactorRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&types.TwoFactorChallenge{}).Error
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+)
+
+// newTestTwoFactorUser 创建已启用两步验证的测试用户，返回用户与恢复码
+func newTestTwoFactorUser(t *testing.T, username string) (*types.User, []string) {
+	t.Helper()
+	db := database.GetDB()
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	user := &types.User{
+		Username:         username,
+		Password:         "-",
+		FullName:         username,
+		Permission:       utils.PermissionScoreInput,
+		TwoFactorEnabled: true,
+		TOTPSecret:       secret,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	codes, err := replaceRecoveryCodes(db, user.ID)
+	if err != nil {
+		t.Fatalf("生成恢复码失败: %v", err)
+	}
+	return user, codes
+}
+
+func TestVerifyTwoFactorRecoveryCode(t *testing.T) {
+	clearTestTables(t, &types.TwoFactorChallenge{}, &types.TwoFactorRecoveryCode{}, &types.User{})
+
+	user, codes := newTestTwoFactorUser(t, "two-factor-recovery")
+	db := database.GetDB()
+
+	// 按顺序执行，后面的步骤依赖前面已使用的恢复码
+	steps := []struct {
+		name       string
+		code       string
+		regenerate bool // 执行前重新生成恢复码
+		wantErr    error
+	}{
+		{"使用恢复码", codes[0], false, nil},
+		{"恢复码不能重复使用", codes[0], false, ErrTwoFactorCodeIncorrect},
+		{"忽略大小写与连字符", strings.ToLower(strings.ReplaceAll(codes[1], "-", "")), false, nil},
+		{"错误的恢复码", "AAAAA-AAAAA", false, ErrTwoFactorCodeIncorrect},
+		{"空验证码", "", false, ErrTwoFactorCodeIncorrect},
+		{"重新生成后原恢复码失效", codes[2], true, ErrTwoFactorCodeIncorrect},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.regenerate {
+				if _, err := replaceRecoveryCodes(db, user.ID); err != nil {
+					t.Fatalf("重新生成恢复码失败: %v", err)
+				}
+			}
+			if err := verifyTwoFactorCode(db, user, step.code); !errors.Is(err, step.wantErr) {
+				t.Errorf("verifyTwoFactorCode() error = %v, want %v", err, step.wantErr)
+			}
+		})
+	}
+
+	var unused int64
+	db.Model(&types.TwoFactorRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&unused)
+	if unused != int64(recoveryCodeCount) {
+		t.Errorf("unused recovery codes = %d, want %d", unused, recoveryCodeCount)
+	}
+}
+
+func TestVerifyTwoFactorCodeNotEnabled(t *testing.T) {
+	user := &types.User{ID: 1, TwoFactorEnabled: false}
+	if err := verifyTwoFactorCode(database.GetDB(), user, "123456"); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Errorf("verifyTwoFactorCode() error = %v, want %v", err, ErrTwoFactorNotEnabled)
+	}
+}
+
+func TestCompleteTwoFactorChallenge(t *testing.T) {
+	clearTestTables(t, &types.TwoFactorChallenge{}, &types.TwoFactorRecoveryCode{}, &types.User{})
+
+	user, codes := newTestTwoFactorUser(t, "two-factor-challenge")
+
+	token, err := CreateTwoFactorChallenge(user.ID, types.LoginMethodOIDC)
+	if err != nil {
+		t.Fatalf("CreateTwoFactorChallenge() error = %v", err)
+	}
+
+	// 按顺序执行：验证码错误不作废请求，验证成功后请求只能使用一次
+	steps := []struct {
+		name       string
+		code       string
+		wantUser   bool
+		wantMethod string
+		wantErr    error
+	}{
+		{"验证码错误时返回用户用于记录失败", "AAAAA-AAAAA", true, "", ErrTwoFactorCodeIncorrect},
+		{"使用恢复码完成登录并沿用登录方式", codes[0], true, types.LoginMethodOIDC, nil},
+		{"请求不能重复使用", codes[1], false, "", ErrTwoFactorChallengeInvalid},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			got, method, err := CompleteTwoFactorChallenge(token, step.code)
+			if !errors.Is(err, step.wantErr) {
+				t.Fatalf("CompleteTwoFactorChallenge() error = %v, want %v", err, step.wantErr)
+			}
+			if (got != nil) != step.wantUser || method != step.wantMethod {
+				t.Errorf("CompleteTwoFactorChallenge() = (%v, %q), want user = %v, method = %q", got, method, step.wantUser, step.wantMethod)
+			}
+		})
+	}
+}
+
+func TestCompleteTwoFactorChallengeAttempts(t *testing.T) {
+	clearTestTables(t, &types.TwoFactorChallenge{}, &types.TwoFactorRecoveryCode{}, &types.User{})
+
+	user, codes := newTestTwoFactorUser(t, "two-factor-attempts")
+
+	token, err := CreateTwoFactorChallenge(user.ID, types.LoginMethodPassword)
+	if err != nil {
+		t.Fatalf("CreateTwoFactorChallenge() error = %v", err)
+	}
+	for i := 0; i < maxTwoFactorAttempts; i++ {
+		if _, _, err := CompleteTwoFactorChallenge(token, "AAAAA-AAAAA"); !errors.Is(err, ErrTwoFactorCodeIncorrect) {
+			t.Fatalf("attempt %d error = %v, want %v", i+1, err, ErrTwoFactorCodeIncorrect)
+		}
+	}
+
+	// 错误次数达到上限后请求作废，正确的恢复码也不会被消耗
+	if _, _, err := CompleteTwoFactorChallenge(token, codes[0]); !errors.Is(err, ErrTwoFactorChallengeInvalid) {
+		t.Fatalf("CompleteTwoFactorChallenge() error = %v, want %v", err, ErrTwoFactorChallengeInvalid)
+	}
+
+	token, err = CreateTwoFactorChallenge(user.ID, types.LoginMethodPassword)
+	if err != nil {
+		t.Fatalf("CreateTwoFactorChallenge() error = %v", err)
+	}
+	if _, method, err := CompleteTwoFactorChallenge(token, codes[0]); err != nil || method != types.LoginMethodPassword {
+		t.Errorf("CompleteTwoFactorChallenge() = (%q, %v), want (%q, nil)", method, err, types.LoginMethodPassword)
+	}
+}
@@ -68,6 +68,8 @@ func CreateUser(username, password, fullName string, permission int, dingtalkId
 		FullName:   fullName,
 		Permission: permission,
 		DingTalkID: dingtalkId,
+		// 管理员设置的初始密码，用户首次登录后须修改
+		MustChangePassword: true,
 	}
 
 	// 使用事务创建用户
@@ -142,6 +144,25 @@ func GetUserByDingTalkID(dingTalkID string) (*types.User, error) {
 	return &user, nil
 }
 
+// GetUserByExternalID 根据外部身份ID（OIDC）获取用户
+func GetUserByExternalID(externalID string) (*types.User, error) {
+	// 获取数据库连接
+	db := database.GetDB()
+
+	// 查询用户，预加载ClassScopes
+	var user types.User
+	err := db.Preload("ClassScopes").Where("external_id = ?", externalID).First(&user).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 // UpdateUser 更新用户信息
 func UpdateUser(user *types.User) error {
 	// 获取数据库连接
@@ -150,7 +171,7 @@ func UpdateUser(user *types.User) error {
 	// 使用事务更新用户数据和班级scopes
 	return db.Transaction(func(tx *gorm.DB) error {
 		// 更新基本字段
-		if err := tx.Select("full_name", "permission", "ding_talk_id").Where("id = ?", user.ID).Updates(user).Error; err != nil {
+		if err := tx.Select("full_name", "permission", "ding_talk_id", "external_id", "competition_scoped").Where("id = ?", user.ID).Updates(user).Error; err != nil {
 			return err
 		}
 
@@ -184,7 +205,7 @@ func UpdateUserClassScopes(userID int, classScopeIDs []int) error {
 	return db.Model(user).Association("ClassScopes").Replace(classes)
 }
 
-// UpdatePassword 更新用户密码
+// UpdatePassword 更新用户密码（管理员重置），用户下次登录后须修改密码
 func UpdatePassword(userID int, newPassword string) error {
 	// 对新密码进行哈希处理
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -195,8 +216,17 @@ func UpdatePassword(userID int, newPassword string) error {
 	// 获取数据库连接
 	db := database.GetDB()
 
-	// 更新密码
-	return db.Model(&types.User{}).Where("id = ?", userID).Update("password", string(hashedPassword)).Error
+	// 更新密码，并撤销该用户已登录的会话
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":             string(hashedPassword),
+			"must_change_password": true,
+		}).Error; err != nil {
+			return err
+		}
+		_, err := revokeUserSessions(tx, userID, types.SessionRoleAdmin)
+		return err
+	})
 }
 
 // DeleteUser 删除用户
@@ -206,6 +236,25 @@ func DeleteUser(id int) error {
 
 	// 使用事务删除用户
 	return db.Transaction(func(tx *gorm.DB) error {
+		// 删除该用户的裁判指派
+		if err := tx.Where("user_id = ?", id).Delete(&types.CompetitionOfficial{}).Error; err != nil {
+			return err
+		}
+
+		// 删除该用户的登录会话
+		if err := deleteUserSessions(tx, id, types.SessionRoleAdmin); err != nil {
+			return err
+		}
+
+		// 删除该用户创建的API密钥
+		if err := deleteUserAPIKeys(tx, id); err != nil {
+			return err
+		}
+
+		// 删除该用户的两步验证恢复码与未完成的登录请求
+		if err := clearTwoFactor(tx, id); err != nil {
+			return err
+		}
 		return tx.Delete(&types.User{}, id).Error
 	})
 }
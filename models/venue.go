@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"gorm.io/gorm"
+)
+
+// CreateVenue 创建比赛场地
+func CreateVenue(venue *types.Venue) error {
+	db := database.GetDB()
+
+	if err := utils.ValidateVenueHours(venue.OpenTime, venue.CloseTime); err != nil {
+		return err
+	}
+	if venue.Capacity < 0 {
+		return errors.New("场地容量不能为负数")
+	}
+
+	// 检查名称是否重复
+	var count int64
+	if err := db.Model(&types.Venue{}).Where("name = ?", venue.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("场地名称已存在")
+	}
+
+	return db.Create(venue).Error
+}
+
+// GetAllVenues 获取所有比赛场地
+func GetAllVenues() ([]*types.Venue, error) {
+	db := database.GetDB()
+
+	var venues []*types.Venue
+	if err := db.Order("id ASC").Find(&venues).Error; err != nil {
+		return nil, err
+	}
+
+	return venues, nil
+}
+
+// GetVenueByID 通过ID获取比赛场地
+func GetVenueByID(id int) (*types.Venue, error) {
+	db := database.GetDB()
+
+	var venue types.Venue
+	if err := db.First(&venue, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrVenueNotFound
+		}
+		return nil, err
+	}
+
+	return &venue, nil
+}
+
+// UpdateVenue 更新比赛场地
+func UpdateVenue(venue *types.Venue) error {
+	db := database.GetDB()
+
+	if err := utils.ValidateVenueHours(venue.OpenTime, venue.CloseTime); err != nil {
+		return err
+	}
+	if venue.Capacity < 0 {
+		return errors.New("场地容量不能为负数")
+	}
+
+	// 检查新名称是否与其他场地重复
+	var count int64
+	if err := db.Model(&types.Venue{}).Where("name = ? AND id != ?", venue.Name, venue.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("场地名称已存在")
+	}
+
+	return db.Model(venue).Select("name", "description", "capacity", "open_time", "close_time").Updates(map[string]interface{}{
+		"name":        venue.Name,
+		"description": venue.Description,
+		"capacity":    venue.Capacity,
+		"open_time":   venue.OpenTime,
+		"close_time":  venue.CloseTime,
+	}).Error
+}
+
+// DeleteVenue 删除比赛场地
+func DeleteVenue(id int) error {
+	db := database.GetDB()
+
+	// 检查是否有比赛项目使用该场地
+	var count int64
+	if err := db.Model(&types.Competition{}).Where("venue_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("该场地已被比赛项目使用，无法删除")
+	}
+
+	// 清除日程草稿中的场地安排
+	if err := db.Model(&types.ScheduleEntry{}).Where("venue_id = ?", id).Update("venue_id", nil).Error; err != nil {
+		return err
+	}
+
+	result := db.Delete(&types.Venue{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return utils.ErrVenueNotFound
+	}
+	return nil
+}
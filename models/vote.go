@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
 	"gorm.io/gorm"
 )
 
@@ -36,8 +37,19 @@ func VoteCompetition(db *gorm.DB, studentID, competitionID int, voteType types.V
 		return fmt.Errorf("查询学生失败: %v", err)
 	}
 
+	// 获取比赛所属届次的投票规则
+	settings, err := utils.LoadEventSettings(db, competition.EventID)
+	if err != nil {
+		return fmt.Errorf("获取投票规则失败: %v", err)
+	}
+
 	// 使用事务处理投票逻辑
 	return db.Transaction(func(tx *gorm.DB) error {
+		// 检查投票规则（取消已有投票不受限制）
+		if err := checkVotingRules(tx, &settings.VotingRules, competition.EventID, studentID, competitionID, voteType); err != nil {
+			return err
+		}
+
 		// 查询现有投票记录
 		var existingVote types.Vote
 		err := tx.Where("student_id = ? AND competition_id = ?", studentID, competitionID).
@@ -107,6 +119,77 @@ func VoteCompetition(db *gorm.DB, studentID, competitionID int, voteType types.V
 	})
 }
 
+// checkVotingRules 检查本次投票是否符合所属届次的投票规则
+func checkVotingRules(tx *gorm.DB, rules *types.VotingRules, eventID, studentID, competitionID int, voteType types.VoteType) error {
+	// 再次投出相同类型的票视为取消投票，不受规则限制
+	var existingVote types.Vote
+	err := tx.Where("student_id = ? AND competition_id = ?", studentID, competitionID).First(&existingVote).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("查询投票记录失败: %v", err)
+	}
+	if err == nil && existingVote.VoteType == voteType {
+		return nil
+	}
+
+	if voteType == types.VoteTypeDown && rules.DisableDownvotes {
+		return utils.ErrDownvoteDisabled
+	}
+
+	if voteType == types.VoteTypeUp && rules.MaxUpvotesPerStudent > 0 {
+		upvotes, err := countStudentUpvotes(tx, eventID, studentID)
+		if err != nil {
+			return err
+		}
+		if upvotes >= int64(rules.MaxUpvotesPerStudent) {
+			return utils.ErrUpvoteLimitReached
+		}
+	}
+
+	return nil
+}
+
+// countStudentUpvotes 统计学生在指定届次已投出的赞成票数
+func countStudentUpvotes(db *gorm.DB, eventID, studentID int) (int64, error) {
+	var count int64
+	if err := db.Model(&types.Vote{}).
+		Joins("JOIN competitions ON competitions.id = votes.competition_id").
+		Where("votes.student_id = ? AND votes.vote_type = ? AND competitions.event_id = ?", studentID, types.VoteTypeUp, eventID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("统计赞成票数量失败: %v", err)
+	}
+	return count, nil
+}
+
+// GetStudentVotingQuota 获取学生在指定届次的投票规则与已使用的赞成票数
+func GetStudentVotingQuota(db *gorm.DB, eventID, studentID int) (map[string]interface{}, error) {
+	settings, err := utils.LoadEventSettings(db, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("获取投票规则失败: %v", err)
+	}
+
+	upvotes, err := countStudentUpvotes(db, eventID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 剩余赞成票数，-1 表示无限制
+	remaining := -1
+	if settings.VotingRules.MaxUpvotesPerStudent > 0 {
+		remaining = settings.VotingRules.MaxUpvotesPerStudent - int(upvotes)
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+
+	return map[string]interface{}{
+		"max_upvotes_per_student": settings.VotingRules.MaxUpvotesPerStudent,
+		"disable_downvotes":       settings.VotingRules.DisableDownvotes,
+		"used_upvotes":            upvotes,
+		"remaining_upvotes":       remaining,
+		"voting_end_time":         settings.VotingEndTime,
+	}, nil
+}
+
 // GetStudentVote 获取学生对某比赛项目的投票
 func GetStudentVote(db *gorm.DB, studentID, competitionID int) (*types.Vote, error) {
 	var vote types.Vote
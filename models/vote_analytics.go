@@ -0,0 +1,220 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+)
+
+// otherGrade 无法从班级名称识别年级时使用的分组名称
+const otherGrade = "其他"
+
+// gradeFromClassName 从班级名称中提取年级（如"高一1班"提取为"高一"）
+func gradeFromClassName(name string) string {
+	index := strings.IndexFunc(name, unicode.IsDigit)
+	if index <= 0 {
+		return otherGrade
+	}
+	return name[:index]
+}
+
+// percentage 计算百分比，保留两位小数
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
+
+// addVote 累加一组投票统计
+func addVote(tally *types.VoteTally, upvotes, downvotes int) {
+	tally.Upvotes += upvotes
+	tally.Downvotes += downvotes
+}
+
+// GetVoteAnalytics 获取指定届次推荐项目的投票分布与各班级投票参与率
+// 投票记录未保存投票时的班级，按学生当前所在班级统计
+func GetVoteAnalytics(eventID int) (*types.VoteAnalytics, error) {
+	db := database.GetDB()
+
+	// 本届比赛项目（不含已合并的重复项目），按净票数排列
+	var competitions []types.Competition
+	if err := db.Select("id", "name", "status", "vote_count").
+		Where("event_id = ? AND merged_into_id IS NULL", eventID).
+		Order("vote_count DESC, id ASC").
+		Find(&competitions).Error; err != nil {
+		return nil, err
+	}
+
+	// 班级信息
+	var classes []types.Class
+	if err := db.Order("id ASC").Find(&classes).Error; err != nil {
+		return nil, err
+	}
+	classNames := make(map[int]string, len(classes))
+	for _, class := range classes {
+		classNames[class.ID] = class.Name
+	}
+
+	// 按项目、投票学生班级与性别汇总投票
+	var voteRows []struct {
+		CompetitionID int
+		ClassID       int
+		Gender        int
+		Upvotes       int
+		Downvotes     int
+	}
+	if err := db.Raw(`
+		SELECT
+			v.competition_id,
+			s.class_id,
+			s.gender,
+			SUM(CASE WHEN v.vote_type = ? THEN 1 ELSE 0 END) as upvotes,
+			SUM(CASE WHEN v.vote_type = ? THEN 1 ELSE 0 END) as downvotes
+		FROM votes v
+		JOIN students s ON s.id = v.student_id
+		JOIN competitions c ON c.id = v.competition_id
+		WHERE c.event_id = ?
+		GROUP BY v.competition_id, s.class_id, s.gender
+	`, types.VoteTypeUp, types.VoteTypeDown, eventID).Scan(&voteRows).Error; err != nil {
+		return nil, err
+	}
+
+	analytics := &types.VoteAnalytics{
+		EventID:      eventID,
+		Competitions: make([]*types.CompetitionVoteAnalytics, 0, len(competitions)),
+		Turnout:      make([]*types.ClassVoteTurnout, 0, len(classes)),
+	}
+
+	byCompetition := make(map[int]*types.CompetitionVoteAnalytics, len(competitions))
+	for _, competition := range competitions {
+		item := &types.CompetitionVoteAnalytics{
+			CompetitionID:   competition.ID,
+			CompetitionName: competition.Name,
+			Status:          competition.Status,
+			VoteCount:       competition.VoteCount,
+			ByClass:         []*types.ClassVoteTally{},
+			ByGrade:         []*types.GradeVoteTally{},
+			ByGender:        []*types.GenderVoteTally{},
+		}
+		analytics.Competitions = append(analytics.Competitions, item)
+		byCompetition[competition.ID] = item
+	}
+
+	for _, row := range voteRows {
+		item, ok := byCompetition[row.CompetitionID]
+		if !ok {
+			continue
+		}
+		addVote(&item.VoteTally, row.Upvotes, row.Downvotes)
+
+		// 按班级
+		var classTally *types.ClassVoteTally
+		for _, existing := range item.ByClass {
+			if existing.ClassID == row.ClassID {
+				classTally = existing
+				break
+			}
+		}
+		if classTally == nil {
+			className := classNames[row.ClassID]
+			classTally = &types.ClassVoteTally{ClassID: row.ClassID, ClassName: className, Grade: gradeFromClassName(className)}
+			item.ByClass = append(item.ByClass, classTally)
+		}
+		addVote(&classTally.VoteTally, row.Upvotes, row.Downvotes)
+
+		// 按年级
+		grade := gradeFromClassName(classNames[row.ClassID])
+		var gradeTally *types.GradeVoteTally
+		for _, existing := range item.ByGrade {
+			if existing.Grade == grade {
+				gradeTally = existing
+				break
+			}
+		}
+		if gradeTally == nil {
+			gradeTally = &types.GradeVoteTally{Grade: grade}
+			item.ByGrade = append(item.ByGrade, gradeTally)
+		}
+		addVote(&gradeTally.VoteTally, row.Upvotes, row.Downvotes)
+
+		// 按性别
+		var genderTally *types.GenderVoteTally
+		for _, existing := range item.ByGender {
+			if existing.Gender == row.Gender {
+				genderTally = existing
+				break
+			}
+		}
+		if genderTally == nil {
+			genderTally = &types.GenderVoteTally{Gender: row.Gender}
+			item.ByGender = append(item.ByGender, genderTally)
+		}
+		addVote(&genderTally.VoteTally, row.Upvotes, row.Downvotes)
+	}
+
+	// 分组按ID、年级名称、性别排序，保证输出稳定
+	for _, item := range analytics.Competitions {
+		sort.Slice(item.ByClass, func(i, j int) bool { return item.ByClass[i].ClassID < item.ByClass[j].ClassID })
+		sort.Slice(item.ByGrade, func(i, j int) bool { return item.ByGrade[i].Grade < item.ByGrade[j].Grade })
+		sort.Slice(item.ByGender, func(i, j int) bool { return item.ByGender[i].Gender < item.ByGender[j].Gender })
+	}
+
+	// 各班级学生人数
+	var studentRows []struct {
+		ClassID int
+		Count   int
+	}
+	if err := db.Model(&types.Student{}).
+		Select("class_id, COUNT(*) as count").
+		Group("class_id").
+		Scan(&studentRows).Error; err != nil {
+		return nil, err
+	}
+	studentCounts := make(map[int]int, len(studentRows))
+	for _, row := range studentRows {
+		studentCounts[row.ClassID] = row.Count
+	}
+
+	// 各班级在本届至少投过一票的学生人数
+	var voterRows []struct {
+		ClassID int
+		Count   int
+	}
+	if err := db.Raw(`
+		SELECT s.class_id, COUNT(DISTINCT v.student_id) as count
+		FROM votes v
+		JOIN students s ON s.id = v.student_id
+		JOIN competitions c ON c.id = v.competition_id
+		WHERE c.event_id = ?
+		GROUP BY s.class_id
+	`, eventID).Scan(&voterRows).Error; err != nil {
+		return nil, err
+	}
+	voterCounts := make(map[int]int, len(voterRows))
+	for _, row := range voterRows {
+		voterCounts[row.ClassID] = row.Count
+	}
+
+	for _, class := range classes {
+		studentCount := studentCounts[class.ID]
+		voterCount := voterCounts[class.ID]
+		analytics.TotalStudents += studentCount
+		analytics.TotalVoters += voterCount
+		analytics.Turnout = append(analytics.Turnout, &types.ClassVoteTurnout{
+			ClassID:      class.ID,
+			ClassName:    class.Name,
+			Grade:        gradeFromClassName(class.Name),
+			StudentCount: studentCount,
+			VoterCount:   voterCount,
+			Turnout:      percentage(voterCount, studentCount),
+		})
+	}
+	analytics.OverallTurnout = percentage(analytics.TotalVoters, analytics.TotalStudents)
+
+	return analytics, nil
+}
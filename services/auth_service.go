@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/config"
@@ -17,42 +18,91 @@ type UserRole string
 const (
 	RoleAdmin   UserRole = "admin"
 	RoleStudent UserRole = "student"
+	RoleParent  UserRole = "parent" // 家长：只读查看关联学生的信息，并处理报名确认
+)
+
+const (
+	sessionDuration     = 30 * 24 * time.Hour // 登录会话（刷新令牌）最长有效期
+	accessTokenDuration = 15 * time.Minute    // 访问令牌有效期
 )
 
 // JWTClaims JWT 的自定义声明
+// 权限不写入令牌，每次请求从数据库读取，调整权限后立即生效；令牌ID（jti）对应服务端的登录会话
+// 家长令牌的 UserID 为家长账号ID，Username 为家长钉钉ID，关联的学生每次请求时按家长学生关系获取
 type JWTClaims struct {
-	UserID     int      `json:"user_id"`
-	Username   string   `json:"username"`
-	Role       UserRole `json:"role"`
-	Permission int      `json:"permission,omitempty"` // 操作员权限列表
+	UserID   int      `json:"user_id"`
+	Username string   `json:"username"`
+	Role     UserRole `json:"role"`
 	jwt.StandardClaims
 }
 
-// StudentData 学生数据结构，用于登录响应
-type StudentData struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	FullName string `json:"full_name"`
-	Token    string `json:"token"`
+// ClientInfo 登录客户端信息，记录在登录会话中
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
 }
 
-// GenerateToken 生成 JWT 令牌
-func GenerateToken(id int, username string, role UserRole, permissions int) (string, error) {
+// TokenPair 访问令牌与刷新令牌
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // 访问令牌有效期（秒）
+}
+
+// GenerateToken 创建登录会话并生成访问令牌与刷新令牌，method 为登录方式
+func GenerateToken(id int, username string, role UserRole, method string, client ClientInfo) (*TokenPair, error) {
+	now := time.Now()
+
+	// 创建服务端会话
+	tokenID, err := utils.GenerateURLSafeToken(24)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		return nil, err
+	}
+	session := &types.Session{
+		TokenID:    tokenID,
+		UserID:     id,
+		Role:       string(role),
+		Method:     method,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(sessionDuration),
+	}
+	if err := models.CreateSession(session, utils.HashToken(refreshToken)); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := signAccessToken(id, username, role, tokenID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenDuration.Seconds()),
+	}, nil
+}
+
+// signAccessToken 签发访问令牌
+func signAccessToken(id int, username string, role UserRole, tokenID string, now time.Time) (string, error) {
 	// 获取 JWT 密钥
 	cfg := config.Get()
 	jwtSecret := []byte(cfg.Security.JWTSecret)
 
-	// 设置 token 有效期为 30 天
-	expirationTime := time.Now().Add(30 * 24 * time.Hour)
-
 	// 创建声明
 	claims := &JWTClaims{
-		UserID:     id,
-		Username:   username,
-		Role:       role,
-		Permission: permissions,
+		UserID:   id,
+		Username: username,
+		Role:     role,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
+			Id:        tokenID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(accessTokenDuration).Unix(),
 		},
 	}
 
@@ -60,12 +110,75 @@ func GenerateToken(id int, username string, role UserRole, permissions int) (str
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// 签名 token
-	tokenString, err := token.SignedString(jwtSecret)
+	return token.SignedString(jwtSecret)
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌与刷新令牌（旧刷新令牌随即作废）
+// 用户名从数据库重新读取，返回会话以便调用方获取用户最新的权限信息
+func RefreshToken(refreshToken string) (*TokenPair, *types.Session, error) {
+	session, err := models.GetSessionByRefreshToken(utils.HashToken(refreshToken))
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+	username, err := getSessionUsername(session)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 账号因登录失败次数过多被锁定期间不能续期
+	if retryAfter, err := models.GetAccountLock(username); err != nil {
+		if errors.Is(err, models.ErrAccountLocked) {
+			return nil, nil, fmt.Errorf("%w，请%d秒后再试", err, retryAfter)
+		}
+		return nil, nil, err
+	}
+
+	newRefreshToken, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err = models.RotateRefreshToken(utils.HashToken(refreshToken), utils.HashToken(newRefreshToken))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := signAccessToken(session.UserID, username, UserRole(session.Role), session.TokenID, time.Now())
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return tokenString, nil
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(accessTokenDuration.Seconds()),
+	}, session, nil
+}
+
+// getSessionUsername 获取会话所属账号的用户名（家长为钉钉ID）
+func getSessionUsername(session *types.Session) (string, error) {
+	switch UserRole(session.Role) {
+	case RoleAdmin:
+		user, err := models.GetUserByID(session.UserID)
+		if err != nil {
+			return "", err
+		}
+		return user.Username, nil
+	case RoleStudent:
+		student, err := models.GetStudentByID(session.UserID)
+		if err != nil {
+			return "", err
+		}
+		return student.Username, nil
+	case RoleParent:
+		parent, err := models.GetParentByID(session.UserID)
+		if err != nil {
+			return "", err
+		}
+		return parent.DingTalkID, nil
+	default:
+		return "", models.ErrSessionInvalid
+	}
 }
 
 // ValidateToken 验证 JWT 令牌
@@ -92,107 +205,232 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 }
 
 // Login 用户登录
-func Login(username, password string) (string, *types.User, error) {
+// 启用了两步验证的用户不会立即获得令牌，而是返回两步验证登录凭据，需凭其调用 TwoFactorLogin 完成登录
+func Login(username, password string, client ClientInfo) (*TokenPair, *types.User, string, error) {
 	// 验证用户凭据
 	user, err := models.VerifyPassword(username, password)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, "", err
+	}
+
+	// 已启用两步验证，需继续验证动态验证码
+	if user.TwoFactorEnabled {
+		challengeToken, err := models.CreateTwoFactorChallenge(user.ID, types.LoginMethodPassword)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return nil, user, challengeToken, nil
 	}
 
 	// 生成 token
-	token, err := GenerateToken(user.ID, user.Username, RoleAdmin, user.Permission)
+	tokens, err := GenerateToken(user.ID, user.Username, RoleAdmin, types.LoginMethodPassword, client)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, "", err
 	}
 
-	return token, user, nil
+	return tokens, user, "", nil
+}
+
+// TwoFactorLogin 两步验证登录：校验账号密码、钉钉或统一身份认证登录时返回的凭据与动态验证码（或恢复码）
+// 验证码错误时同时返回用户，用于记录登录失败次数
+func TwoFactorLogin(challengeToken, code string, client ClientInfo) (*TokenPair, *types.User, error) {
+	user, method, err := models.CompleteTwoFactorChallenge(challengeToken, code)
+	if err != nil {
+		return nil, user, err
+	}
+
+	// 生成 token，登录方式沿用第一步验证的方式
+	tokens, err := GenerateToken(user.ID, user.Username, RoleAdmin, method, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
 }
 
 // StudentLogin 学生登录
-func StudentLogin(username, password string) (string, *types.Student, error) {
+func StudentLogin(username, password string, client ClientInfo) (*TokenPair, *types.Student, error) {
 	// 验证学生凭据
 	student, err := models.VerifyStudentPassword(username, password)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
 	// 生成 token
-	token, err := GenerateToken(student.ID, student.Username, RoleStudent, 0)
+	tokens, err := GenerateToken(student.ID, student.Username, RoleStudent, types.LoginMethodPassword, client)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
-	return token, student, nil
+	return tokens, student, nil
 }
 
 // DingTalkLogin 钉钉免登录
-func DingTalkLogin(code string) (string, interface{}, error) {
+// 管理员已启用两步验证时与账号密码登录相同，返回两步验证登录凭据，需凭其调用 TwoFactorLogin 完成登录
+func DingTalkLogin(code string, client ClientInfo) (*TokenPair, interface{}, string, error) {
 	// 获取钉钉用户信息
 	userInfo, err := utils.GetDingTalkUserInfo(code)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, "", err
 	}
 
 	if userInfo.UserID == "0" {
-		return "", nil, errors.New("获取用户信息失败")
+		return nil, nil, "", errors.New("获取用户信息失败")
 	}
 
 	// 先尝试查找学生
 	student, err := models.GetStudentByDingTalkID(userInfo.UserID)
 	if err == nil {
 		// 学生找到，生成学生 token
-		token, err := GenerateToken(student.ID, student.Username, RoleStudent, 0)
+		tokens, err := GenerateToken(student.ID, student.Username, RoleStudent, types.LoginMethodDingTalk, client)
 		if err != nil {
-			return "", nil, err
+			return nil, nil, "", err
 		}
-		return token, student, nil
+		return tokens, student, "", nil
 	}
 
 	// 如果找不到学生，尝试查找管理员
 	user, err := models.GetUserByDingTalkID(userInfo.UserID)
 	if err == nil {
+		// 已启用两步验证，需继续验证动态验证码
+		if user.TwoFactorEnabled {
+			challengeToken, err := models.CreateTwoFactorChallenge(user.ID, types.LoginMethodDingTalk)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			return nil, user, challengeToken, nil
+		}
 
 		// 生成 token
-		token, err := GenerateToken(user.ID, user.Username, RoleAdmin, user.Permission)
+		tokens, err := GenerateToken(user.ID, user.Username, RoleAdmin, types.LoginMethodDingTalk, client)
 		if err != nil {
-			return "", nil, err
+			return nil, nil, "", err
 		}
 
-		return token, user, nil
+		return tokens, user, "", nil
+	}
+
+	// 如果前面都找不到，可能是家长，按缓存的家长-学生关系查找关联的学生
+	children, err := models.GetParentChildren(userInfo.UserID)
+	if err != nil || len(children) == 0 {
+		return nil, nil, "", errors.New("未找到关联的学生或用户，请联系管理员。你的钉钉ID为：" + userInfo.UserID)
+	}
+
+	// 以家长身份登录（不能代替学生报名、投票等）
+	parent, err := models.GetOrCreateParent(userInfo.UserID, userInfo.Name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	tokens, err := GenerateToken(parent.ID, parent.DingTalkID, RoleParent, types.LoginMethodDingTalk, client)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	parent.Children = children
+
+	return tokens, parent, "", nil
+}
+
+// oidcStateDuration 统一身份认证授权请求的有效期
+const oidcStateDuration = 10 * time.Minute
+
+// OIDCAuthorize 生成统一身份认证授权地址
+// state、nonce 与 PKCE 校验码保存在服务端，回调登录时校验并取出
+func OIDCAuthorize() (string, error) {
+	if !config.Get().OIDC.Enabled {
+		return "", utils.ErrOIDCDisabled
+	}
+
+	state, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateURLSafeToken(32)
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, err := utils.GenerateURLSafeToken(48)
+	if err != nil {
+		return "", err
+	}
+
+	if err := models.CreateOIDCState(state, nonce, codeVerifier, oidcStateDuration); err != nil {
+		return "", err
+	}
+
+	return utils.GetOIDCAuthorizationURL(state, nonce, codeVerifier)
+}
+
+// OIDCLogin 统一身份认证登录
+// 与钉钉登录相同，先按学生查找，找不到再按管理员查找
+// 学生默认按外部ID匹配，仅在显式配置时按用户名匹配；用户名可由用户在身份提供方自行设置，管理员始终只按外部ID匹配
+// 管理员已启用两步验证时与账号密码登录相同，返回两步验证登录凭据，需凭其调用 TwoFactorLogin 完成登录
+func OIDCLogin(code, state string, client ClientInfo) (*TokenPair, interface{}, string, error) {
+	if !config.Get().OIDC.Enabled {
+		return nil, nil, "", utils.ErrOIDCDisabled
 	}
 
-	// 如果前面都找不到，可能是家长，尝试获取关联的学生
-	// 直接从数据库中查询缓存的家长-学生关系
-	relations, err := models.GetStudentsByParentID(userInfo.UserID)
+	// 校验并取出授权请求
+	oidcState, err := models.ConsumeOIDCState(state)
+	if err != nil {
+		return nil, nil, "", err
+	}
 
-	if err != nil || len(relations) == 0 {
-		return "", nil, errors.New("未找到关联的学生或用户，请联系管理员。你的钉钉ID为：" + userInfo.UserID)
+	// 使用授权码换取并校验身份令牌
+	userInfo, err := utils.ExchangeOIDCCode(code, oidcState.CodeVerifier, oidcState.Nonce)
+	if err != nil {
+		return nil, nil, "", err
 	}
 
-	// 获取学生的详细信息
-	var studentsData []StudentData
-	for _, relation := range relations {
-		student, err := models.GetStudentByDingTalkID(relation.StudentID)
+	// 先尝试查找学生
+	student, err := findOIDCStudent(userInfo)
+	if err == nil {
+		// 学生找到，生成学生 token
+		tokens, err := GenerateToken(student.ID, student.Username, RoleStudent, types.LoginMethodOIDC, client)
 		if err != nil {
-			continue
+			return nil, nil, "", err
 		}
+		return tokens, student, "", nil
+	}
 
-		// 为每个学生生成token
-		token, err := GenerateToken(student.ID, student.Username, RoleStudent, 0)
-		if err != nil {
-			continue
+	// 如果找不到学生，按外部ID查找管理员
+	if userInfo.ExternalID != "" {
+		user, err := models.GetUserByExternalID(userInfo.ExternalID)
+		if err == nil {
+			// 已启用两步验证，需继续验证动态验证码
+			if user.TwoFactorEnabled {
+				challengeToken, err := models.CreateTwoFactorChallenge(user.ID, types.LoginMethodOIDC)
+				if err != nil {
+					return nil, nil, "", err
+				}
+				return nil, user, challengeToken, nil
+			}
+
+			// 生成 token
+			tokens, err := GenerateToken(user.ID, user.Username, RoleAdmin, types.LoginMethodOIDC, client)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			return tokens, user, "", nil
 		}
+	}
 
-		studentsData = append(studentsData, StudentData{
-			ID:       student.ID,
-			Username: student.Username,
-			FullName: student.FullName,
-			Token:    token,
-		})
+	account := userInfo.ExternalID
+	if account == "" {
+		account = userInfo.Username
 	}
+	return nil, nil, "", errors.New("未找到关联的学生或用户，请联系管理员。你的统一身份认证账号为：" + account)
+}
 
-	if len(studentsData) == 0 {
-		return "", nil, errors.New("无法生成学生登录凭证，请联系系统管理员")
+// findOIDCStudent 按配置的匹配方式查找统一身份认证对应的学生
+func findOIDCStudent(userInfo *utils.OIDCUserInfo) (*types.Student, error) {
+	if config.Get().OIDC.MatchBy == utils.OIDCMatchByUsername {
+		if userInfo.Username == "" {
+			return nil, errors.New("学生不存在")
+		}
+		return models.GetStudentByUsername(userInfo.Username)
 	}
 
-	// 返回所有学生信息和对应的token
-	return "", studentsData, nil
+	// 未设置外部ID的学生不能被匹配
+	if userInfo.ExternalID == "" {
+		return nil, errors.New("学生不存在")
+	}
+	return models.GetStudentByExternalID(userInfo.ExternalID)
 }
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+)
+
+// consentCheckInterval 家长确认过期与提醒的检查间隔
+const consentCheckInterval = 10 * time.Minute
+
+// buildConsentURL 构建家长确认页面链接
+func buildConsentURL(token string) string {
+	domain := strings.TrimRight(config.Get().Website.Domain, "/")
+	if domain != "" && !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+		domain = "https://" + domain
+	}
+	return fmt.Sprintf("%s/consent/%s", domain, token)
+}
+
+// NotifyGuardians 向学生家长发送报名确认卡片
+// isReminder: 是否为提醒消息
+func NotifyGuardians(consent *types.RegistrationConsent, isReminder bool) error {
+	// 获取学生家长的钉钉ID
+	parentIDs, err := models.GetParentsByStudentID(consent.StudentID)
+	if err != nil {
+		return err
+	}
+	if len(parentIDs) == 0 {
+		return errors.New("未找到该学生关联的家长，请联系管理员线下确认")
+	}
+
+	title := "运动会报名确认"
+	if isReminder {
+		title = "运动会报名确认提醒"
+	}
+
+	card := utils.ActionCardMessage{
+		Title: title,
+		Markdown: fmt.Sprintf("### %s\n\n您的孩子 **%s %s** 报名了比赛项目 **%s**，该项目需要家长同意后报名方可生效。\n\n请在 %s 前完成确认。",
+			title, consent.ClassName, consent.StudentName, consent.CompetitionName, consent.ExpiresAt.Format("2006-01-02 15:04")),
+		SingleTitle: "查看并确认",
+		SingleURL:   buildConsentURL(consent.Token),
+	}
+
+	if err := utils.SendDingTalkActionCard(parentIDs, card); err != nil {
+		return err
+	}
+
+	return models.MarkConsentNotified(consent.ID, isReminder)
+}
+
+// processConsents 处理过期的确认记录并发送提醒
+func processConsents() {
+	// 标记过期记录
+	if count, err := models.ExpireOverdueConsents(); err != nil {
+		utils.LogError("标记过期家长确认失败: " + err.Error())
+	} else if count > 0 {
+		log.Printf("已将 %d 条家长确认标记为过期", count)
+	}
+
+	// 发送提醒
+	cfg := config.Get()
+	if cfg.Consent.MaxReminders <= 0 || cfg.Consent.ReminderIntervalHours <= 0 {
+		return
+	}
+
+	interval := time.Duration(cfg.Consent.ReminderIntervalHours) * time.Hour
+	consents, err := models.GetConsentsDueForReminder(interval, cfg.Consent.MaxReminders)
+	if err != nil {
+		utils.LogError("获取待提醒的家长确认失败: " + err.Error())
+		return
+	}
+
+	for _, consent := range consents {
+		// 首次通知失败（如家长关系未同步）的记录，这里会作为首次通知补发
+		isReminder := consent.LastNotifiedAt != nil
+		if err := NotifyGuardians(consent, isReminder); err != nil {
+			utils.LogError(fmt.Sprintf("发送家长确认提醒失败 (确认ID: %d): %v", consent.ID, err))
+			// 发送失败也计入提醒次数，避免无限重试
+			if err := models.MarkConsentNotified(consent.ID, true); err != nil {
+				utils.LogError("更新家长确认提醒记录失败: " + err.Error())
+			}
+		}
+	}
+}
+
+// StartConsentWorker 启动家长确认后台任务
+func StartConsentWorker() {
+	go func() {
+		ticker := time.NewTicker(consentCheckInterval)
+		defer ticker.Stop()
+
+		processConsents()
+		for range ticker.C {
+			processConsents()
+		}
+	}()
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -91,7 +92,7 @@ func RebuildParentStudentMapping() error {
 	if err != nil {
 		errMsg := fmt.Sprintf("获取班级列表失败: %v", err)
 		addMappingLog(errMsg)
-		return fmt.Errorf(errMsg)
+		return errors.New(errMsg)
 	}
 
 	addMappingLog(fmt.Sprintf("共获取到 %d 个班级需要处理", len(classIDs)))
@@ -100,7 +101,7 @@ func RebuildParentStudentMapping() error {
 	if err := models.ClearAllParentStudentRelations(); err != nil {
 		errMsg := fmt.Sprintf("清空映射关系失败: %v", err)
 		addMappingLog(errMsg)
-		return fmt.Errorf(errMsg)
+		return errors.New(errMsg)
 	}
 
 	addMappingLog("已清空现有映射关系")
@@ -0,0 +1,436 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/SHXZ-OSS/sports-meeting-system/database"
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	testOIDCClientID     = "sports-meeting"
+	testOIDCClientSecret = "client-secret"
+	testOIDCRedirectURL  = "https://sports.example.com/login/oidc"
+	testOIDCKeyID        = "test-key"
+)
+
+// testOIDCAuthorization 测试身份提供方记录的一次授权
+type testOIDCAuthorization struct {
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims // 覆盖身份令牌中的声明
+}
+
+// testOIDCProvider 测试用身份提供方，提供发现文档、签名公钥与令牌接口
+type testOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]testOIDCAuthorization
+	count int
+}
+
+// newTestOIDCProvider 启动测试身份提供方，并将统一身份认证配置指向它
+func newTestOIDCProvider(t *testing.T, matchBy string) *testOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成签名密钥失败: %v", err)
+	}
+	provider := &testOIDCProvider{key: key, codes: map[string]testOIDCAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", provider.handleDiscovery)
+	mux.HandleFunc("/jwks", provider.handleJWKS)
+	mux.HandleFunc("/token", provider.handleToken)
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	cfg := config.Get()
+	previous := cfg.OIDC
+	t.Cleanup(func() { config.Get().OIDC = previous })
+
+	cfg.OIDC.Enabled = true
+	cfg.OIDC.Issuer = provider.server.URL
+	cfg.OIDC.ClientID = testOIDCClientID
+	cfg.OIDC.ClientSecret = testOIDCClientSecret
+	cfg.OIDC.RedirectURL = testOIDCRedirectURL
+	cfg.OIDC.Scopes = []string{"openid", "profile"}
+	cfg.OIDC.MatchBy = matchBy
+	cfg.OIDC.UsernameClaim = "preferred_username"
+	cfg.OIDC.ExternalIDClaim = "employee_id"
+
+	return provider
+}
+
+func (p *testOIDCProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeTestJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                 p.server.URL,
+		"authorization_endpoint": p.server.URL + "/authorize",
+		"token_endpoint":         p.server.URL + "/token",
+		"jwks_uri":               p.server.URL + "/jwks",
+	})
+}
+
+func (p *testOIDCProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeTestJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]interface{}{{
+			"kid": testOIDCKeyID,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// handleToken 校验客户端凭据与 PKCE 后签发身份令牌，授权码只能使用一次
+func (p *testOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTestJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_request"})
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != testOIDCClientID || clientSecret != testOIDCClientSecret {
+		writeTestJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != testOIDCRedirectURL {
+		writeTestJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_request"})
+		return
+	}
+
+	p.mu.Lock()
+	authorization, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	if !ok || utils.OIDCCodeChallenge(r.PostForm.Get("code_verifier")) != authorization.codeChallenge {
+		writeTestJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   testOIDCClientID,
+		"sub":   "subject",
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": authorization.nonce,
+	}
+	for name, value := range authorization.claims {
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testOIDCKeyID
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeTestJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "server_error"})
+		return
+	}
+	writeTestJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+// authorize 模拟用户在身份提供方完成登录，返回授权码与 state
+func (p *testOIDCProvider) authorize(t *testing.T, authURL string, claims jwt.MapClaims) (string, string) {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("解析授权地址失败: %v", err)
+	}
+	if parsed.Path != "/authorize" {
+		t.Fatalf("授权地址 = %s，应指向身份提供方的授权接口", authURL)
+	}
+	query := parsed.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != testOIDCClientID ||
+		query.Get("redirect_uri") != testOIDCRedirectURL || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("授权参数不正确: %v", query)
+	}
+	if query.Get("state") == "" || query.Get("nonce") == "" || query.Get("code_challenge") == "" {
+		t.Fatalf("授权地址缺少 state、nonce 或 code_challenge: %v", query)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.count++
+	code := "code-" + big.NewInt(int64(p.count)).String()
+	p.codes[code] = testOIDCAuthorization{
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		claims:        claims,
+	}
+	return code, query.Get("state")
+}
+
+// login 发起授权并完成统一身份认证登录
+func (p *testOIDCProvider) login(t *testing.T, claims jwt.MapClaims) (*TokenPair, interface{}, string, error) {
+	t.Helper()
+
+	authURL, err := OIDCAuthorize()
+	if err != nil {
+		t.Fatalf("OIDCAuthorize() error = %v", err)
+	}
+	code, state := p.authorize(t, authURL, claims)
+	return OIDCLogin(code, state, ClientInfo{})
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// createOIDCTestAccounts 创建一个学生与一个管理员，两者的外部ID与用户名互不相同
+func createOIDCTestAccounts(t *testing.T) (*types.Student, *types.User) {
+	t.Helper()
+	clearTestTables(t, &types.Session{}, &types.OIDCState{}, &types.TwoFactorChallenge{}, &types.Student{}, &types.User{}, &types.Class{})
+
+	db := database.GetDB()
+	class := types.Class{Name: "高一(1)班"}
+	if err := db.Create(&class).Error; err != nil {
+		t.Fatalf("创建班级失败: %v", err)
+	}
+	student := types.Student{Username: "student1", Password: "-", FullName: "学生", Gender: 1, ClassID: class.ID, ExternalID: "S100"}
+	if err := db.Create(&student).Error; err != nil {
+		t.Fatalf("创建学生失败: %v", err)
+	}
+	user := types.User{Username: "admin1", Password: "-", FullName: "管理员", ExternalID: "A100"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("创建管理员失败: %v", err)
+	}
+	return &student, &user
+}
+
+func TestOIDCLoginRoundTrip(t *testing.T) {
+	student, _ := createOIDCTestAccounts(t)
+	provider := newTestOIDCProvider(t, utils.OIDCMatchByExternalID)
+
+	authURL, err := OIDCAuthorize()
+	if err != nil {
+		t.Fatalf("OIDCAuthorize() error = %v", err)
+	}
+	code, state := provider.authorize(t, authURL, jwt.MapClaims{"employee_id": "S100"})
+
+	tokens, account, challengeToken, err := OIDCLogin(code, state, ClientInfo{})
+	if err != nil {
+		t.Fatalf("OIDCLogin() error = %v", err)
+	}
+	if tokens == nil || tokens.AccessToken == "" || challengeToken != "" {
+		t.Fatalf("OIDCLogin() 应直接签发令牌，tokens = %v，challenge = %q", tokens, challengeToken)
+	}
+	matched, ok := account.(*types.Student)
+	if !ok || matched.ID != student.ID {
+		t.Fatalf("OIDCLogin() account = %#v，应为学生 %d", account, student.ID)
+	}
+
+	var session types.Session
+	if err := database.GetDB().Where("user_id = ? AND role = ?", student.ID, types.SessionRoleStudent).First(&session).Error; err != nil {
+		t.Fatalf("查询登录会话失败: %v", err)
+	}
+	if session.Method != types.LoginMethodOIDC {
+		t.Errorf("会话登录方式 = %q，应为 %q", session.Method, types.LoginMethodOIDC)
+	}
+
+	// state 只能使用一次
+	if _, _, _, err := OIDCLogin(code, state, ClientInfo{}); !errors.Is(err, models.ErrOIDCStateInvalid) {
+		t.Errorf("重复使用 state 的错误 = %v，应为 %v", err, models.ErrOIDCStateInvalid)
+	}
+
+	// 未由本系统发起的 state 被拒绝
+	if _, _, _, err := OIDCLogin(code, "unknown-state", ClientInfo{}); !errors.Is(err, models.ErrOIDCStateInvalid) {
+		t.Errorf("未知 state 的错误 = %v，应为 %v", err, models.ErrOIDCStateInvalid)
+	}
+}
+
+func TestOIDCLoginRejectsInvalidToken(t *testing.T) {
+	createOIDCTestAccounts(t)
+	provider := newTestOIDCProvider(t, utils.OIDCMatchByExternalID)
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"签发方不一致", jwt.MapClaims{"iss": "https://evil.example.com"}},
+		{"受众不包含本系统", jwt.MapClaims{"aud": "another-client"}},
+		{"受众数组不包含本系统", jwt.MapClaims{"aud": []string{"another-client"}}},
+		{"nonce 不一致", jwt.MapClaims{"nonce": "another-nonce"}},
+		{"已过期", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["employee_id"] = "S100"
+			tokens, _, _, err := provider.login(t, tt.claims)
+			if !errors.Is(err, utils.ErrOIDCInvalidToken) {
+				t.Fatalf("OIDCLogin() error = %v，应为 %v", err, utils.ErrOIDCInvalidToken)
+			}
+			if tokens != nil {
+				t.Fatalf("身份令牌无效时不应签发令牌")
+			}
+		})
+	}
+
+	// 受众数组包含本系统时接受
+	if _, _, _, err := provider.login(t, jwt.MapClaims{"aud": []string{"another-client", testOIDCClientID}, "employee_id": "S100"}); err != nil {
+		t.Errorf("受众数组包含本系统时 OIDCLogin() error = %v", err)
+	}
+
+	// PKCE 校验码与授权请求不一致时身份提供方拒绝换取令牌
+	authURL, err := OIDCAuthorize()
+	if err != nil {
+		t.Fatalf("OIDCAuthorize() error = %v", err)
+	}
+	code, state := provider.authorize(t, authURL, jwt.MapClaims{"employee_id": "S100"})
+	provider.mu.Lock()
+	authorization := provider.codes[code]
+	authorization.codeChallenge = utils.OIDCCodeChallenge("attacker-verifier")
+	provider.codes[code] = authorization
+	provider.mu.Unlock()
+	if tokens, _, _, err := OIDCLogin(code, state, ClientInfo{}); err == nil || tokens != nil {
+		t.Errorf("PKCE 校验失败时 OIDCLogin() 应返回错误，error = %v", err)
+	}
+}
+
+func TestOIDCLoginMatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		matchBy string
+		claims  jwt.MapClaims
+		want    string // student、admin 或空（登录失败）
+	}{
+		{"按外部ID匹配学生", utils.OIDCMatchByExternalID, jwt.MapClaims{"employee_id": "S100", "preferred_username": "admin1"}, "student"},
+		{"按外部ID匹配管理员", utils.OIDCMatchByExternalID, jwt.MapClaims{"employee_id": "A100"}, "admin"},
+		{"按外部ID匹配时忽略用户名", utils.OIDCMatchByExternalID, jwt.MapClaims{"employee_id": "X999", "preferred_username": "student1"}, ""},
+		{"按用户名匹配学生", utils.OIDCMatchByUsername, jwt.MapClaims{"preferred_username": "student1"}, "student"},
+		{"按用户名匹配时管理员仍按外部ID匹配", utils.OIDCMatchByUsername, jwt.MapClaims{"preferred_username": "nobody", "employee_id": "A100"}, "admin"},
+		{"管理员不按用户名匹配", utils.OIDCMatchByUsername, jwt.MapClaims{"preferred_username": "admin1"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			student, user := createOIDCTestAccounts(t)
+			provider := newTestOIDCProvider(t, tt.matchBy)
+
+			tokens, account, _, err := provider.login(t, tt.claims)
+			switch tt.want {
+			case "student":
+				matched, ok := account.(*types.Student)
+				if err != nil || tokens == nil || !ok || matched.ID != student.ID {
+					t.Fatalf("OIDCLogin() = %#v, %v，应匹配学生 %d", account, err, student.ID)
+				}
+			case "admin":
+				matched, ok := account.(*types.User)
+				if err != nil || tokens == nil || !ok || matched.ID != user.ID {
+					t.Fatalf("OIDCLogin() = %#v, %v，应匹配管理员 %d", account, err, user.ID)
+				}
+			default:
+				if err == nil || tokens != nil {
+					t.Fatalf("OIDCLogin() = %#v，不应匹配任何账号", account)
+				}
+			}
+		})
+	}
+}
+
+// testTOTPCode 计算当前时间的动态验证码（RFC 6238，与验证器应用一致）
+func testTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		t.Fatalf("解析动态验证码密钥失败: %v", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func TestOIDCLoginTwoFactor(t *testing.T) {
+	_, user := createOIDCTestAccounts(t)
+	provider := newTestOIDCProvider(t, utils.OIDCMatchByExternalID)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	db := database.GetDB()
+	if err := db.Model(user).Updates(map[string]interface{}{"two_factor_enabled": true, "totp_secret": secret}).Error; err != nil {
+		t.Fatalf("启用两步验证失败: %v", err)
+	}
+
+	// 已启用两步验证的管理员不会直接获得令牌
+	tokens, account, challengeToken, err := provider.login(t, jwt.MapClaims{"employee_id": "A100"})
+	if err != nil {
+		t.Fatalf("OIDCLogin() error = %v", err)
+	}
+	if tokens != nil || challengeToken == "" {
+		t.Fatalf("OIDCLogin() 应返回两步验证登录凭据，tokens = %v，challenge = %q", tokens, challengeToken)
+	}
+	if matched, ok := account.(*types.User); !ok || matched.ID != user.ID {
+		t.Fatalf("OIDCLogin() account = %#v，应为管理员 %d", account, user.ID)
+	}
+	var count int64
+	db.Model(&types.Session{}).Where("user_id = ? AND role = ?", user.ID, types.SessionRoleAdmin).Count(&count)
+	if count != 0 {
+		t.Fatalf("完成两步验证前不应创建登录会话，实际 %d 个", count)
+	}
+
+	// 验证码错误时不签发令牌
+	if tokens, _, err := TwoFactorLogin(challengeToken, "000000", ClientInfo{}); err == nil || tokens != nil {
+		t.Fatalf("验证码错误时 TwoFactorLogin() 应返回错误，error = %v", err)
+	}
+
+	tokens, _, err = TwoFactorLogin(challengeToken, testTOTPCode(t, secret), ClientInfo{})
+	if err != nil {
+		t.Fatalf("TwoFactorLogin() error = %v", err)
+	}
+	claims, err := ValidateToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	var session types.Session
+	if err := db.Where("token_id = ?", claims.Id).First(&session).Error; err != nil {
+		t.Fatalf("查询登录会话失败: %v", err)
+	}
+	if session.UserID != user.ID || session.Role != types.SessionRoleAdmin || session.Method != types.LoginMethodOIDC {
+		t.Errorf("登录会话 = %+v，应为管理员 %d 的统一身份认证会话", session, user.ID)
+	}
+
+	// 登录凭据只能使用一次
+	if _, _, err := TwoFactorLogin(challengeToken, testTOTPCode(t, secret), ClientInfo{}); err == nil {
+		t.Errorf("重复使用两步验证登录凭据时 TwoFactorLogin() 应返回错误")
+	}
+}
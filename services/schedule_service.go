@@ -0,0 +1,316 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/types"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+)
+
+const (
+	defaultHeatSize       = 8               // 默认每组人数
+	defaultMinutesPerHeat = 10              // 默认每组用时（分钟）
+	scheduleSlotStep      = 5 * time.Minute // 排程时间粒度
+)
+
+// scheduleItem 排程中的比赛项目
+type scheduleItem struct {
+	entry    *types.ScheduleEntry
+	name     string
+	venue    *types.Venue
+	students map[int]bool
+}
+
+// groupRegistrations 按比赛项目统计报名学生和班级
+func groupRegistrations(registrations []*types.Registration) (map[int]map[int]bool, map[int]map[int]bool) {
+	students := make(map[int]map[int]bool)
+	classes := make(map[int]map[int]bool)
+	for _, reg := range registrations {
+		if reg.StudentID != nil {
+			if students[reg.CompetitionID] == nil {
+				students[reg.CompetitionID] = make(map[int]bool)
+			}
+			students[reg.CompetitionID][*reg.StudentID] = true
+		}
+		if reg.ClassID != nil {
+			if classes[reg.CompetitionID] == nil {
+				classes[reg.CompetitionID] = make(map[int]bool)
+			}
+			classes[reg.CompetitionID][*reg.ClassID] = true
+		}
+	}
+	return students, classes
+}
+
+// sharedStudentCount 统计两个比赛项目共同的参赛学生数
+func sharedStudentCount(a, b map[int]bool) int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	count := 0
+	for studentID := range a {
+		if b[studentID] {
+			count++
+		}
+	}
+	return count
+}
+
+// checkItemConflict 检查两个已排程项目之间的冲突，无冲突返回空字符串
+func checkItemConflict(a, b *scheduleItem, rest time.Duration) string {
+	// 同一场地不能同时进行两项比赛
+	if a.venue != nil && b.venue != nil && a.venue.ID == b.venue.ID &&
+		a.entry.StartTime.Before(b.entry.EndTime) && b.entry.StartTime.Before(a.entry.EndTime) {
+		return fmt.Sprintf("与 %s 在场地 %s 的时间重叠", b.name, a.venue.Name)
+	}
+
+	// 同一学生的两项比赛之间需要留出休息时间
+	if a.entry.StartTime.Before(b.entry.EndTime.Add(rest)) && b.entry.StartTime.Before(a.entry.EndTime.Add(rest)) {
+		if shared := sharedStudentCount(a.students, b.students); shared > 0 {
+			if a.entry.StartTime.Before(b.entry.EndTime) && b.entry.StartTime.Before(a.entry.EndTime) {
+				return fmt.Sprintf("与 %s 时间重叠，有 %d 名学生同时报名", b.name, shared)
+			}
+			return fmt.Sprintf("与 %s 间隔不足 %d 分钟，有 %d 名学生同时报名", b.name, int(rest.Minutes()), shared)
+		}
+	}
+
+	return ""
+}
+
+// findScheduleConflicts 检查日程中所有项目之间的冲突
+func findScheduleConflicts(items []*scheduleItem, rest time.Duration) []types.ScheduleIssue {
+	conflicts := []types.ScheduleIssue{}
+	for i := 0; i < len(items); i++ {
+		for j := i + 1; j < len(items); j++ {
+			if message := checkItemConflict(items[i], items[j], rest); message != "" {
+				conflicts = append(conflicts, types.ScheduleIssue{
+					CompetitionID:   items[i].entry.CompetitionID,
+					CompetitionName: items[i].name,
+					Message:         message,
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// GenerateSchedule 根据报名情况自动生成日程草稿
+// 按参赛人数从多到少依次为每个项目寻找最早的可用时间段，保证同一学生的比赛不重叠且留有休息时间
+func GenerateSchedule(options types.ScheduleOptions) (*types.ScheduleDraft, error) {
+	if len(options.Sessions) == 0 {
+		return nil, errors.New("请至少设置一个比赛时间段")
+	}
+	sessions := make([]types.ScheduleSession, len(options.Sessions))
+	copy(sessions, options.Sessions)
+	for _, session := range sessions {
+		if !session.StartTime.Before(session.EndTime) {
+			return nil, errors.New("时间段的开始时间必须早于结束时间")
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+
+	if options.RestMinutes < 0 {
+		return nil, errors.New("休息时间不能为负数")
+	}
+	rest := time.Duration(options.RestMinutes) * time.Minute
+	if options.DefaultHeatSize <= 0 {
+		options.DefaultHeatSize = defaultHeatSize
+	}
+	if options.DefaultMinutesPerHeat <= 0 {
+		options.DefaultMinutesPerHeat = defaultMinutesPerHeat
+	}
+
+	// 获取场地信息
+	venues, err := models.GetAllVenues()
+	if err != nil {
+		return nil, err
+	}
+	venueMap := make(map[int]*types.Venue)
+	for _, venue := range venues {
+		venueMap[venue.ID] = venue
+	}
+
+	competitionOptions := make(map[int]types.ScheduleCompetitionOption)
+	for _, option := range options.Competitions {
+		competitionOptions[option.CompetitionID] = option
+	}
+
+	// 获取可排程的比赛项目及报名情况
+	competitions, err := models.GetSchedulableCompetitions()
+	if err != nil {
+		return nil, err
+	}
+	competitionIDs := make([]int, 0, len(competitions))
+	for _, competition := range competitions {
+		competitionIDs = append(competitionIDs, competition.ID)
+	}
+	registrations, err := models.GetRegistrationsByCompetitionIDs(competitionIDs)
+	if err != nil {
+		return nil, err
+	}
+	studentsByCompetition, classesByCompetition := groupRegistrations(registrations)
+
+	// 根据参赛人数和分组计算每个项目的预计用时
+	items := make([]*scheduleItem, 0, len(competitions))
+	durations := make(map[int]time.Duration)
+	for _, competition := range competitions {
+		option := competitionOptions[competition.ID]
+		// 未指定场地时使用项目已设置的场地
+		venueID := option.VenueID
+		if venueID == nil {
+			venueID = competition.VenueID
+		}
+		var venue *types.Venue
+		if venueID != nil {
+			venue = venueMap[*venueID]
+			if venue == nil {
+				return nil, fmt.Errorf("比赛项目 %s 的场地不存在", competition.Name)
+			}
+		}
+
+		heatSize := option.HeatSize
+		if heatSize <= 0 {
+			heatSize = options.DefaultHeatSize
+		}
+		// 每组人数不能超过场地容量
+		if venue != nil && venue.Capacity > 0 && heatSize > venue.Capacity {
+			heatSize = venue.Capacity
+		}
+		minutesPerHeat := option.MinutesPerHeat
+		if minutesPerHeat <= 0 {
+			minutesPerHeat = options.DefaultMinutesPerHeat
+		}
+
+		participants := len(studentsByCompetition[competition.ID])
+		if competition.CompetitionType == types.TypeTeam {
+			participants = len(classesByCompetition[competition.ID])
+		}
+		heats := (participants + heatSize - 1) / heatSize
+		if heats < 1 {
+			heats = 1
+		}
+
+		durations[competition.ID] = time.Duration(heats*minutesPerHeat) * time.Minute
+		items = append(items, &scheduleItem{
+			entry: &types.ScheduleEntry{
+				CompetitionID:   competition.ID,
+				CompetitionName: competition.Name,
+				VenueID:         venueID,
+				Participants:    participants,
+				Heats:           heats,
+			},
+			name:     competition.Name,
+			venue:    venue,
+			students: studentsByCompetition[competition.ID],
+		})
+	}
+
+	// 约束多的项目优先排程
+	sort.SliceStable(items, func(i, j int) bool {
+		if len(items[i].students) != len(items[j].students) {
+			return len(items[i].students) > len(items[j].students)
+		}
+		return durations[items[i].entry.CompetitionID] > durations[items[j].entry.CompetitionID]
+	})
+
+	draft := &types.ScheduleDraft{
+		Entries:     []*types.ScheduleEntry{},
+		Unscheduled: []types.ScheduleIssue{},
+		Conflicts:   []types.ScheduleIssue{},
+	}
+	var placed []*scheduleItem
+	for _, item := range items {
+		duration := durations[item.entry.CompetitionID]
+		if !placeScheduleItem(item, duration, sessions, placed, rest) {
+			draft.Unscheduled = append(draft.Unscheduled, types.ScheduleIssue{
+				CompetitionID:   item.entry.CompetitionID,
+				CompetitionName: item.name,
+				Message:         fmt.Sprintf("无法在已设置的时间段内安排（预计用时 %d 分钟）", int(duration.Minutes())),
+			})
+			continue
+		}
+		if item.venue != nil {
+			item.entry.VenueName = item.venue.Name
+		}
+		placed = append(placed, item)
+		draft.Entries = append(draft.Entries, item.entry)
+	}
+
+	sort.SliceStable(draft.Entries, func(i, j int) bool {
+		return draft.Entries[i].StartTime.Before(draft.Entries[j].StartTime)
+	})
+
+	// 保存草稿
+	if err := models.SaveScheduleDraft(draft.Entries); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// placeScheduleItem 为项目寻找最早的无冲突时间段
+func placeScheduleItem(item *scheduleItem, duration time.Duration, sessions []types.ScheduleSession, placed []*scheduleItem, rest time.Duration) bool {
+	for _, session := range sessions {
+		for start := session.StartTime; !start.Add(duration).After(session.EndTime); start = start.Add(scheduleSlotStep) {
+			item.entry.StartTime = start
+			item.entry.EndTime = start.Add(duration)
+
+			// 需在场地开放时间内
+			if item.venue != nil && !utils.IsWithinVenueHours(item.venue, item.entry.StartTime, item.entry.EndTime) {
+				continue
+			}
+
+			conflict := false
+			for _, other := range placed {
+				if checkItemConflict(item, other, rest) != "" {
+					conflict = true
+					break
+				}
+			}
+			if !conflict {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetScheduleDraft 获取日程草稿并检查手动调整后的冲突
+func GetScheduleDraft(restMinutes int) (*types.ScheduleDraft, error) {
+	entries, err := models.GetScheduleDraft()
+	if err != nil {
+		return nil, err
+	}
+
+	competitionIDs := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		competitionIDs = append(competitionIDs, entry.CompetitionID)
+	}
+	registrations, err := models.GetRegistrationsByCompetitionIDs(competitionIDs)
+	if err != nil {
+		return nil, err
+	}
+	studentsByCompetition, _ := groupRegistrations(registrations)
+
+	items := make([]*scheduleItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, &scheduleItem{
+			entry:    entry,
+			name:     entry.CompetitionName,
+			venue:    entry.Venue,
+			students: studentsByCompetition[entry.CompetitionID],
+		})
+	}
+
+	return &types.ScheduleDraft{
+		Entries:     entries,
+		Unscheduled: []types.ScheduleIssue{},
+		Conflicts:   findScheduleConflicts(items, time.Duration(restMinutes)*time.Minute),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/models"
+	"github.com/SHXZ-OSS/sports-meeting-system/utils"
+)
+
+// shortlistCheckInterval 投票结束后生成入围报告的检查间隔
+const shortlistCheckInterval = 1 * time.Minute
+
+// processShortlists 为投票已结束的届次生成待确认的入围报告
+func processShortlists() {
+	count, err := models.GenerateDueShortlistReports()
+	if err != nil {
+		utils.LogError("生成投票入围报告失败: " + err.Error())
+	}
+	if count > 0 {
+		log.Printf("已生成 %d 份投票入围报告，等待项目管理员确认", count)
+	}
+}
+
+// StartShortlistWorker 启动投票入围后台任务
+func StartShortlistWorker() {
+	go func() {
+		ticker := time.NewTicker(shortlistCheckInterval)
+		defer ticker.Stop()
+
+		processShortlists()
+		for range ticker.C {
+			processShortlists()
+		}
+	}()
+}
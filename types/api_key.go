@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// APIKeyPrefix API密钥前缀，用于在 Authorization 头中区分API密钥与登录令牌
+const APIKeyPrefix = "smsk_"
+
+// APIKey 供大屏、成绩打印脚本等程序调用管理接口的API密钥
+// 密钥以创建者的身份调用接口，实际权限为密钥权限与创建者当前权限的交集
+type APIKey struct {
+	ID            int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name          string     `json:"name" gorm:"not null"`                                // 名称，如"大屏显示"
+	Prefix        string     `json:"prefix" gorm:"not null"`                              // 密钥开头部分，用于识别密钥
+	KeyHash       string     `json:"-" gorm:"uniqueIndex;not null"`                       // 密钥摘要（不保存明文）
+	Permission    int        `json:"permission" gorm:"not null;default:0"`                // 权限（与用户权限相同的位定义）
+	ClassScopes   []Class    `json:"class_scopes" gorm:"many2many:api_key_class_scopes;"` // 班级权限范围，为空表示沿用创建者的范围
+	CreatedByID   int        `json:"created_by_id" gorm:"not null;index"`                 // 创建者
+	CreatedByName string     `json:"created_by_name" gorm:"-"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`   // 过期时间，为空表示不过期
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"` // 最近使用时间
+	LastUsedIP    string     `json:"last_used_ip"`           // 最近使用IP
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`   // 撤销时间
+}
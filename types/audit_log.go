@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog 管理操作审计日志（只追加，不提供修改与删除）
+// 每个会修改数据的管理员接口调用都会记录一条，before/after 为操作前后的数据快照（敏感字段已脱敏）
+type AuditLog struct {
+	ID         int             `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorID    int             `json:"actor_id" gorm:"not null;index"` // 操作人（管理员用户ID）
+	ActorName  string          `json:"actor_name"`                     // 操作人用户名（记录时的快照）
+	APIKeyID   *int            `json:"api_key_id,omitempty"`           // 通过API密钥调用时的密钥ID
+	Action     string          `json:"action" gorm:"not null;index"`   // 操作，如 "POST /api/admin/competitions/:id/approve"
+	Path       string          `json:"path"`                           // 实际请求路径
+	TargetType string          `json:"target_type" gorm:"index"`       // 操作对象类型，如 competitions、students
+	TargetID   string          `json:"target_id" gorm:"index"`         // 操作对象ID
+	Before     json.RawMessage `json:"before,omitempty"`               // 操作前的数据
+	After      json.RawMessage `json:"after,omitempty"`                // 操作后的数据（未指定时为请求内容）
+	StatusCode int             `json:"status_code"`                    // 响应中的状态码
+	Message    string          `json:"message"`                        // 响应消息
+	IPAddress  string          `json:"ip_address"`                     // 操作IP
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// AuditLogFilter 审计日志查询条件，零值表示不限制
+type AuditLogFilter struct {
+	ActorID    int
+	Action     string    // 操作（模糊匹配）
+	TargetType string    // 操作对象类型
+	TargetID   string    // 操作对象ID
+	Keyword    string    // 关键字（匹配操作人、路径、响应消息）
+	Success    *bool     // 是否成功
+	StartTime  time.Time // 起始时间（含）
+	EndTime    time.Time // 截止时间（不含）
+}
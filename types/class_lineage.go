@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// ClassLineage 班级沿革（同一个班级在不同届次运动会中的对应关系，如各年份的"3班"）
+type ClassLineage struct {
+	ID          int                   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string                `json:"name" gorm:"unique;not null"`
+	Description string                `json:"description" gorm:"default:''"`
+	CreatedAt   time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	Members     []*ClassLineageMember `json:"members" gorm:"foreignKey:LineageID"`
+}
+
+// ClassLineageMember 班级沿革成员（某一届次运动会中对应的班级）
+// 每个沿革在同一届次中只对应一个班级，同一届次中的班级也只能属于一个沿革
+type ClassLineageMember struct {
+	ID        int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	LineageID int    `json:"lineage_id" gorm:"not null;uniqueIndex:idx_lineage_event"`
+	EventID   int    `json:"event_id" gorm:"not null;uniqueIndex:idx_lineage_event;uniqueIndex:idx_lineage_event_class"`
+	ClassID   int    `json:"class_id" gorm:"not null;uniqueIndex:idx_lineage_event_class"`
+	EventName string `json:"event_name" gorm:"-"`
+	ClassName string `json:"class_name" gorm:"-"`
+
+	// 关联关系
+	Event *Event `json:"-" gorm:"foreignKey:EventID"`
+	Class *Class `json:"-" gorm:"foreignKey:ClassID"`
+}
@@ -19,6 +19,10 @@ const (
 	StatusRejected           CompetitionStatus = "rejected"             // 审核失败
 	StatusPendingScoreReview CompetitionStatus = "pending_score_review" // 等待成绩审核
 	StatusCompleted          CompetitionStatus = "completed"            // 已完成
+	StatusInProgress         CompetitionStatus = "in_progress"          // 比赛进行中
+	StatusPostponed          CompetitionStatus = "postponed"            // 已延期
+	StatusCancelled          CompetitionStatus = "cancelled"            // 已取消（保留报名记录）
+	StatusChangesRequested   CompetitionStatus = "changes_requested"    // 需要修改后重新提交（学生推荐项目）
 )
 
 const (
@@ -58,8 +62,14 @@ type Competition struct {
 	ReviewedAt              *time.Time        `json:"reviewed_at,omitempty"`
 	ScoreReviewedAt         *time.Time        `json:"score_reviewed_at,omitempty"`
 	ScoreCreatedAt          *time.Time        `json:"score_created_at,omitempty"`
-	StartTime               *time.Time        `json:"start_time,omitempty"`         // 比赛开始时间
-	EndTime                 *time.Time        `json:"end_time,omitempty"`           // 比赛结束时间
+	StartTime               *time.Time        `json:"start_time,omitempty"`                              // 比赛开始时间
+	EndTime                 *time.Time        `json:"end_time,omitempty"`                                // 比赛结束时间
+	RequiresGuardianConsent bool              `json:"requires_guardian_consent" gorm:"default:false"`    // 报名是否需要家长确认
+	StatusReason            string            `json:"status_reason,omitempty" gorm:"default:''"`         // 最近一次状态变更原因（如延期、取消、审核拒绝原因）
+	VenueID                 *int              `json:"venue_id,omitempty" gorm:"index"`                   // 比赛场地
+	MergedIntoID            *int              `json:"merged_into_id,omitempty" gorm:"index"`             // 作为重复推荐项目被合并到的项目
+	Venue                   *Venue            `json:"venue,omitempty" gorm:"foreignKey:VenueID"`         // 场地信息，公开给观众
+	Credits                 []*ProposalCredit `json:"credits,omitempty" gorm:"foreignKey:CompetitionID"` // 合并重复推荐项目后共同署名的学生
 
 	// 关联关系，不响应到前端
 	Submitter      *Student       `json:"-" gorm:"foreignKey:SubmitterID"`
@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+// OfficialRole 比赛裁判角色
+type OfficialRole string
+
+const (
+	OfficialRoleScorer   OfficialRole = "scorer"   // 记分员：录入成绩
+	OfficialRoleReviewer OfficialRole = "reviewer" // 审核员：审核成绩
+	OfficialRoleStarter  OfficialRole = "starter"  // 发令员：查看检录名单
+)
+
+// CompetitionOfficial 比赛项目指派的裁判
+type CompetitionOfficial struct {
+	ID            int          `json:"id" gorm:"primaryKey;autoIncrement"`
+	CompetitionID int          `json:"competition_id" gorm:"not null;uniqueIndex:idx_competition_official"`
+	UserID        int          `json:"user_id" gorm:"not null;uniqueIndex:idx_competition_official;index"`
+	Role          OfficialRole `json:"role" gorm:"not null;uniqueIndex:idx_competition_official"`
+	UserName      string       `json:"user_name,omitempty" gorm:"-"` // 忽略该字段，通过join获取
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+
+	// 关联关系
+	User *User `json:"-" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// CompetitionRevision 学生推荐项目的提交版本（首次提交与每次修改后重新提交各保存一份）
+type CompetitionRevision struct {
+	ID                      int             `json:"id" gorm:"primaryKey;autoIncrement"`
+	CompetitionID           int             `json:"competition_id" gorm:"not null;index"`
+	Revision                int             `json:"revision" gorm:"not null"` // 版本号，从1开始
+	Name                    string          `json:"name"`
+	Description             string          `json:"description"`
+	ImagePath               string          `json:"image_path"`
+	RankingMode             RankingMode     `json:"ranking_mode"`
+	Unit                    string          `json:"unit"`
+	Gender                  int             `json:"gender"`
+	CompetitionType         CompetitionType `json:"competition_type"`
+	MinParticipantsPerClass int             `json:"min_participants_per_class"`
+	MaxParticipantsPerClass int             `json:"max_participants_per_class"`
+	StartTime               *time.Time      `json:"start_time,omitempty"`
+	EndTime                 *time.Time      `json:"end_time,omitempty"`
+	SubmitterID             *int            `json:"submitter_id,omitempty"`
+	CreatedAt               time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// CompetitionStatusLog 比赛状态变更记录
+type CompetitionStatusLog struct {
+	ID            int               `json:"id" gorm:"primaryKey;autoIncrement"`
+	CompetitionID int               `json:"competition_id" gorm:"not null;index"`
+	FromStatus    CompetitionStatus `json:"from_status"`
+	ToStatus      CompetitionStatus `json:"to_status"`
+	Reason        string            `json:"reason" gorm:"default:''"`
+	OldStartTime  *time.Time        `json:"old_start_time,omitempty"`
+	OldEndTime    *time.Time        `json:"old_end_time,omitempty"`
+	NewStartTime  *time.Time        `json:"new_start_time,omitempty"`
+	NewEndTime    *time.Time        `json:"new_end_time,omitempty"`
+	ChangedByID   *int              `json:"changed_by_id,omitempty"`            // 操作人（管理员ID）
+	ChangedByName string            `json:"changed_by_name,omitempty" gorm:"-"` // 忽略该字段，通过join获取
+	CreatedAt     time.Time         `json:"created_at" gorm:"autoCreateTime"`
+
+	// 关联关系
+	ChangedBy *User `json:"-" gorm:"foreignKey:ChangedByID"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// ConsentStatus 家长确认状态
+type ConsentStatus string
+
+const (
+	ConsentPending  ConsentStatus = "pending"  // 等待家长确认
+	ConsentApproved ConsentStatus = "approved" // 家长已同意
+	ConsentRejected ConsentStatus = "rejected" // 家长已拒绝
+	ConsentExpired  ConsentStatus = "expired"  // 已过期
+)
+
+// RegistrationConsent 报名家长确认记录
+// 需要家长确认的比赛，报名时先创建确认记录，家长同意后才生成正式的报名记录
+type RegistrationConsent struct {
+	ID              int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	StudentID       int           `json:"student_id" gorm:"not null;index"`
+	CompetitionID   int           `json:"competition_id" gorm:"not null;index"`
+	Token           string        `json:"-" gorm:"uniqueIndex;not null"` // 确认链接令牌，不响应到前端
+	Status          ConsentStatus `json:"status" gorm:"not null;default:'pending'"`
+	ExpiresAt       time.Time     `json:"expires_at"`                               // 过期时间
+	ReminderCount   int           `json:"reminder_count" gorm:"default:0"`          // 已发送提醒次数
+	LastNotifiedAt  *time.Time    `json:"last_notified_at,omitempty"`               // 最近一次通知家长的时间
+	RespondedBy     string        `json:"responded_by,omitempty" gorm:"default:''"` // 处理人：guardian（通过确认链接，无法识别具体家长）、parent:<家长钉钉ID> 或 admin:<管理员用户名>
+	RespondedAt     *time.Time    `json:"responded_at,omitempty"`
+	CreatedAt       time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	StudentName     string        `json:"student_name,omitempty" gorm:"-"`     // 忽略该字段，通过join获取
+	ClassName       string        `json:"class_name,omitempty" gorm:"-"`       // 忽略该字段，通过join获取
+	CompetitionName string        `json:"competition_name,omitempty" gorm:"-"` // 忽略该字段，通过join获取
+
+	// 关联关系
+	Student     *Student    `json:"-" gorm:"foreignKey:StudentID"`
+	Competition Competition `json:"-" gorm:"foreignKey:CompetitionID"`
+}
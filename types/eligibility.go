@@ -0,0 +1,35 @@
+package types
+
+import "time"
+
+// EligibilityType 参赛资格限制类型
+type EligibilityType string
+
+const (
+	EligibilityMedicalExemption EligibilityType = "medical_exemption" // 医疗免赛
+	EligibilityDisciplinaryBan  EligibilityType = "disciplinary_ban"  // 违纪禁赛
+)
+
+// 受影响项目类别的特殊取值，其他取值为比赛项目ID
+const (
+	EligibilityCategoryAll        = "all"        // 全部项目
+	EligibilityCategoryIndividual = "individual" // 全部个人项目
+	EligibilityCategoryTeam       = "team"       // 全部团体项目
+)
+
+// StudentEligibility 学生参赛资格限制记录（仅对拥有学生管理权限的管理员可见）
+type StudentEligibility struct {
+	ID         int             `json:"id" gorm:"primaryKey;autoIncrement"`
+	StudentID  int             `json:"student_id" gorm:"not null;index"`
+	Type       EligibilityType `json:"type" gorm:"not null"`
+	StartDate  *time.Time      `json:"start_date,omitempty"`                     // 生效时间，为空表示立即生效
+	EndDate    *time.Time      `json:"end_date,omitempty"`                       // 失效时间，为空表示长期有效
+	Categories string          `json:"categories" gorm:"not null;default:'all'"` // 受影响的项目类别，逗号分隔
+	Note       string          `json:"note" gorm:"default:''"`                   // 备注（如病历说明、处分文号）
+	CreatedBy  *int            `json:"created_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime"`
+
+	// 关联关系
+	Student *Student `json:"-" gorm:"foreignKey:StudentID"`
+	Creator *User    `json:"-" gorm:"foreignKey:CreatedBy"`
+}
@@ -1,7 +1,32 @@
 package types
 
+// VotingRules 项目投票规则
+type VotingRules struct {
+	MaxUpvotesPerStudent int           `json:"max_upvotes_per_student" gorm:"default:0"` // 每名学生在本届最多可投的赞成票数，0表示无限制
+	DisableDownvotes     bool          `json:"disable_downvotes" gorm:"default:false"`   // 是否禁止投反对票
+	ShortlistMode        ShortlistMode `json:"shortlist_mode" gorm:"default:''"`         // 投票结束后的自动入围方式，为空表示不自动入围
+	ShortlistTopK        int           `json:"shortlist_top_k" gorm:"default:0"`         // 按名次入围时的入围项目数（并列时一并入围）
+	ShortlistThreshold   int           `json:"shortlist_threshold" gorm:"default:0"`     // 按票数入围时的最低得票数（赞成票减反对票）
+}
+
+// EventSettings 运动会届次设置（征集、投票、报名时间，报名上限、投票规则与得分映射）
+// 各届运动会独立保存，切换届次后无需重新填写，往届成绩也不会因新的得分映射被重新计算
+type EventSettings struct {
+	SubmissionStartTime       string             `json:"submission_start_time" gorm:"default:''"`          // 项目征集开始时间
+	SubmissionEndTime         string             `json:"submission_end_time" gorm:"default:''"`            // 项目征集结束时间
+	VotingStartTime           string             `json:"voting_start_time" gorm:"default:''"`              // 项目投票开始时间
+	VotingEndTime             string             `json:"voting_end_time" gorm:"default:''"`                // 项目投票结束时间
+	RegistrationStartTime     string             `json:"registration_start_time" gorm:"default:''"`        // 报名开始时间
+	RegistrationEndTime       string             `json:"registration_end_time" gorm:"default:''"`          // 报名结束时间
+	MaxRegistrationsPerPerson int                `json:"max_registrations_per_person" gorm:"default:0"`    // 每个人最多可报名的个人比赛项目数量，0表示无限制
+	VotingRules               VotingRules        `json:"voting_rules" gorm:"embedded"`                     // 项目投票规则
+	TeamPointsMapping         map[string]float64 `json:"team_points_mapping" gorm:"serializer:json"`       // 团体赛名次得分映射
+	IndividualPointsMapping   map[string]float64 `json:"individual_points_mapping" gorm:"serializer:json"` // 个人赛名次得分映射
+}
+
 // Event 运动会届次模型
 type Event struct {
-	ID   int    `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name string `json:"name" gorm:"unique;not null"`
+	ID       int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name     string        `json:"name" gorm:"unique;not null"`
+	Settings EventSettings `json:"settings" gorm:"embedded"`
 }
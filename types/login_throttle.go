@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// 登录限制的统计对象
+const (
+	LoginThrottleUsername = "username" // 按账号统计
+	LoginThrottleIP       = "ip"       // 按IP统计
+)
+
+// LoginThrottle 登录失败记录
+// 按账号和IP分别统计连续失败次数，失败次数增加后需等待的时间逐渐变长，达到上限后临时锁定
+type LoginThrottle struct {
+	ID           int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	Kind         string     `json:"kind" gorm:"not null;uniqueIndex:idx_login_throttle"`  // username 或 ip
+	Value        string     `json:"value" gorm:"not null;uniqueIndex:idx_login_throttle"` // 账号或IP地址
+	Failures     int        `json:"failures" gorm:"not null;default:0"`                   // 连续失败次数
+	LastFailedAt time.Time  `json:"last_failed_at"`                                       // 最近失败时间
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`                               // 锁定截止时间
+	Locked       bool       `json:"locked" gorm:"-"`                                      // 当前是否处于锁定状态
+	RetryAfter   int        `json:"retry_after" gorm:"-"`                                 // 距离可再次尝试的秒数
+}
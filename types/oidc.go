@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// OIDCState 统一身份认证授权请求
+// 跳转到身份提供方前生成，回调时校验 state 并取出 nonce 与 PKCE 校验码，使用一次后删除
+type OIDCState struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	State        string    `json:"-" gorm:"uniqueIndex;not null"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+}
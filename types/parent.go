@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// Parent 家长账号
+// 家长通过钉钉登录，首次登录时创建；关联的学生以家校通讯录同步的家长学生关系为准
+type Parent struct {
+	ID          int            `json:"id" gorm:"primaryKey;autoIncrement"`
+	DingTalkID  string         `json:"ding_talk_id" gorm:"uniqueIndex;not null"` // 家长钉钉ID
+	Name        string         `json:"name"`                                     // 钉钉中的姓名
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	LastLoginAt *time.Time     `json:"last_login_at,omitempty"`
+	Children    []*ParentChild `json:"children,omitempty" gorm:"-"` // 关联的学生
+}
+
+// ParentChild 家长关联的学生
+type ParentChild struct {
+	StudentID int    `json:"student_id"`
+	FullName  string `json:"full_name"`
+	ClassID   int    `json:"class_id"`
+	ClassName string `json:"class_name"`
+	Relation  string `json:"relation"` // 关系描述，如"父亲"
+}
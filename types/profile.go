@@ -0,0 +1,53 @@
+package types
+
+// StudentCompetitionResult 学生在某个比赛中的成绩
+type StudentCompetitionResult struct {
+	CompetitionID   int             `json:"competition_id"`
+	CompetitionName string          `json:"competition_name"`
+	CompetitionType CompetitionType `json:"competition_type"`
+	Unit            string          `json:"unit"`
+	Score           float64         `json:"score"`
+	Ranking         int             `json:"ranking"` // 名次
+	Point           float64         `json:"point"`   // 名次对应的得分
+}
+
+// StudentEventProfile 学生在某一届次运动会中的参赛情况
+type StudentEventProfile struct {
+	EventID       int                         `json:"event_id"`
+	EventName     string                      `json:"event_name"`
+	Registrations int                         `json:"registrations"` // 报名项目数
+	Results       []*StudentCompetitionResult `json:"results"`       // 已审核的成绩
+	TotalPoints   float64                     `json:"total_points"`  // 该届次总得分
+	Rank          int                         `json:"rank"`          // 该届次学生得分排名，0表示无得分
+}
+
+// StudentPersonalBest 学生在同名个人赛中的最好成绩
+type StudentPersonalBest struct {
+	CompetitionName string      `json:"competition_name"`
+	Unit            string      `json:"unit"`
+	RankingMode     RankingMode `json:"ranking_mode"`
+	Score           float64     `json:"score"`
+	CompetitionID   int         `json:"competition_id"`
+	EventID         int         `json:"event_id"`
+	EventName       string      `json:"event_name"`
+}
+
+// StudentRecord 学生创造的运动会纪录（同名个人赛中超越此前所有届次的最好成绩）
+type StudentRecord struct {
+	CompetitionName string  `json:"competition_name"`
+	Unit            string  `json:"unit"`
+	Score           float64 `json:"score"`
+	CompetitionID   int     `json:"competition_id"`
+	EventID         int     `json:"event_id"`
+	EventName       string  `json:"event_name"`
+	Standing        bool    `json:"standing"` // 是否仍为当前纪录
+}
+
+// StudentProfile 学生跨届次参赛档案
+type StudentProfile struct {
+	Student       *Student               `json:"student"`
+	Events        []*StudentEventProfile `json:"events"`         // 参加过的届次，按届次先后排列
+	PersonalBests []*StudentPersonalBest `json:"personal_bests"` // 各同名个人赛的最好成绩
+	Records       []*StudentRecord       `json:"records"`        // 创造的纪录
+	TotalPoints   float64                `json:"total_points"`   // 历届总得分
+}
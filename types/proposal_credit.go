@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// ProposalCredit 推荐项目署名（重复推荐项目合并后，被合并项目的提交者共同署名）
+type ProposalCredit struct {
+	ID             int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	CompetitionID  int       `json:"competition_id" gorm:"not null;uniqueIndex:idx_proposal_credit"`
+	StudentID      int       `json:"student_id" gorm:"not null;uniqueIndex:idx_proposal_credit"`
+	StudentName    string    `json:"student_name" gorm:"-"`
+	MergedFromID   int       `json:"merged_from_id" gorm:"not null"` // 被合并的推荐项目
+	MergedFromName string    `json:"merged_from_name"`               // 被合并项目的名称
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// 关联关系
+	Student *Student `json:"-" gorm:"foreignKey:StudentID"`
+}
+
+// DuplicateCandidate 疑似重复的推荐项目
+type DuplicateCandidate struct {
+	CompetitionID int               `json:"competition_id"`
+	Name          string            `json:"name"`
+	Status        CompetitionStatus `json:"status"`
+	VoteCount     int               `json:"vote_count"`
+	Similarity    float64           `json:"similarity"` // 名称相似度，0~1
+}
@@ -0,0 +1,43 @@
+package types
+
+// ClassLineageEventReport 班级沿革在某一届次的统计
+type ClassLineageEventReport struct {
+	EventID       int     `json:"event_id"`
+	EventName     string  `json:"event_name"`
+	ClassID       int     `json:"class_id"`
+	ClassName     string  `json:"class_name"`
+	Participants  int     `json:"participants"`   // 参赛人数（去重）
+	Registrations int     `json:"registrations"`  // 报名人次
+	FirstPlaces   int     `json:"first_places"`   // 获得第一名的次数
+	TotalPoints   float64 `json:"total_points"`   // 总得分
+	RankingPoints float64 `json:"ranking_points"` // 来自排名的得分
+	CustomPoints  float64 `json:"custom_points"`  // 来自自定义加分
+	Rank          int     `json:"rank"`           // 在该届次中的班级排名，0表示无记录
+}
+
+// ClassLineageReport 班级沿革跨届次对比
+type ClassLineageReport struct {
+	LineageID   int                        `json:"lineage_id"`
+	LineageName string                     `json:"lineage_name"`
+	Events      []*ClassLineageEventReport `json:"events"` // 按所选届次顺序排列，未对应班级的届次不包含在内
+}
+
+// CompetitionEventReport 同名比赛项目在某一届次的统计
+type CompetitionEventReport struct {
+	EventID         int               `json:"event_id"`
+	EventName       string            `json:"event_name"`
+	CompetitionID   int               `json:"competition_id"`
+	Status          CompetitionStatus `json:"status"`
+	CompetitionType CompetitionType   `json:"competition_type"`
+	RankingMode     RankingMode       `json:"ranking_mode"`
+	Unit            string            `json:"unit"`
+	Participants    int               `json:"participants"`            // 报名人数
+	WinningScore    *float64          `json:"winning_score,omitempty"` // 第一名成绩，比赛未完成时为空
+	Winners         []string          `json:"winners"`                 // 第一名（并列时有多个）
+}
+
+// CompetitionReport 同名比赛项目跨届次对比
+type CompetitionReport struct {
+	CompetitionName string                    `json:"competition_name"`
+	Events          []*CompetitionEventReport `json:"events"`
+}
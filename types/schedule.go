@@ -0,0 +1,59 @@
+package types
+
+import "time"
+
+// ScheduleEntry 日程草稿条目
+// 自动生成的日程先保存为草稿，管理员调整后发布到比赛项目的开始/结束时间
+type ScheduleEntry struct {
+	ID              int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID         int       `json:"event_id" gorm:"not null;index"`
+	CompetitionID   int       `json:"competition_id" gorm:"not null;uniqueIndex"`
+	CompetitionName string    `json:"competition_name,omitempty" gorm:"-"` // 忽略该字段，通过join获取
+	VenueID         *int      `json:"venue_id,omitempty" gorm:"index"`     // 比赛场地，同一场地的比赛不能重叠
+	VenueName       string    `json:"venue_name,omitempty" gorm:"-"`       // 忽略该字段，通过join获取
+	StartTime       time.Time `json:"start_time" gorm:"not null"`
+	EndTime         time.Time `json:"end_time" gorm:"not null"`
+	Participants    int       `json:"participants" gorm:"default:0"` // 参赛人数（团体赛为班级数）
+	Heats           int       `json:"heats" gorm:"default:1"`        // 分组数
+
+	// 关联关系
+	Competition Competition `json:"-" gorm:"foreignKey:CompetitionID"`
+	Venue       *Venue      `json:"-" gorm:"foreignKey:VenueID"`
+}
+
+// ScheduleSession 运动会的一个可用时间段（如第一天上午）
+type ScheduleSession struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ScheduleCompetitionOption 单个比赛项目的排程参数
+type ScheduleCompetitionOption struct {
+	CompetitionID  int  `json:"competition_id"`
+	VenueID        *int `json:"venue_id"`         // 比赛场地，为空时使用项目已设置的场地
+	HeatSize       int  `json:"heat_size"`        // 每组人数，0表示使用默认值
+	MinutesPerHeat int  `json:"minutes_per_heat"` // 每组用时（分钟），0表示使用默认值
+}
+
+// ScheduleOptions 自动排程参数
+type ScheduleOptions struct {
+	Sessions              []ScheduleSession           `json:"sessions"`                 // 可用时间段
+	RestMinutes           int                         `json:"rest_minutes"`             // 同一学生两项比赛之间的最短间隔（分钟）
+	DefaultHeatSize       int                         `json:"default_heat_size"`        // 默认每组人数
+	DefaultMinutesPerHeat int                         `json:"default_minutes_per_heat"` // 默认每组用时（分钟）
+	Competitions          []ScheduleCompetitionOption `json:"competitions"`             // 各比赛项目的排程参数
+}
+
+// ScheduleIssue 日程问题（冲突或无法排入的项目）
+type ScheduleIssue struct {
+	CompetitionID   int    `json:"competition_id"`
+	CompetitionName string `json:"competition_name"`
+	Message         string `json:"message"`
+}
+
+// ScheduleDraft 日程草稿
+type ScheduleDraft struct {
+	Entries     []*ScheduleEntry `json:"entries"`
+	Unscheduled []ScheduleIssue  `json:"unscheduled"` // 无法排入的项目
+	Conflicts   []ScheduleIssue  `json:"conflicts"`   // 草稿中存在的冲突
+}
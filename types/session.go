@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+// 会话所属用户类型
+const (
+	SessionRoleAdmin   = "admin"   // 管理员（users 表）
+	SessionRoleStudent = "student" // 学生（students 表）
+	SessionRoleParent  = "parent"  // 家长（parents 表）
+)
+
+// 登录方式
+const (
+	LoginMethodPassword = "password" // 账号密码登录
+	LoginMethodDingTalk = "dingtalk" // 钉钉免登录
+	LoginMethodOIDC     = "oidc"     // 统一身份认证
+)
+
+// Session 登录会话
+// 每次登录生成一个会话，访问令牌中携带会话的令牌ID，撤销会话后对应令牌立即失效
+type Session struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	TokenID    string     `json:"-" gorm:"uniqueIndex;not null"`                  // 令牌ID（JWT jti）
+	UserID     int        `json:"user_id" gorm:"not null;index:idx_session_user"` // 管理员或学生ID
+	Role       string     `json:"role" gorm:"not null;index:idx_session_user"`    // admin、student 或 parent
+	Method     string     `json:"method"`                                         // 登录方式
+	UserAgent  string     `json:"user_agent"`                                     // 登录设备
+	IPAddress  string     `json:"ip_address"`                                     // 登录IP
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`               // 登录时间
+	LastSeenAt time.Time  `json:"last_seen_at"`                                   // 最近使用时间
+	ExpiresAt  time.Time  `json:"expires_at"`                                     // 过期时间
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`                           // 撤销时间
+	Current    bool       `json:"current" gorm:"-"`                               // 是否为当前请求使用的会话
+}
+
+// RefreshToken 刷新令牌（仅保存摘要）
+// 每次刷新后旧令牌作废并签发新令牌，已使用的令牌再次出现时视为被盗用，整个会话将被撤销
+type RefreshToken struct {
+	ID        int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	SessionID int        `json:"session_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UsedAt    *time.Time `json:"used_at,omitempty"` // 已用于刷新的时间
+}
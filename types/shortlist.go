@@ -0,0 +1,59 @@
+package types
+
+import "time"
+
+// ShortlistMode 投票结束后的自动入围方式
+type ShortlistMode string
+
+const (
+	ShortlistModeNone      ShortlistMode = ""          // 不自动入围
+	ShortlistModeTopK      ShortlistMode = "top_k"     // 得票前K名入围
+	ShortlistModeThreshold ShortlistMode = "threshold" // 得票不低于阈值的入围
+)
+
+// ShortlistStatus 入围报告状态
+type ShortlistStatus string
+
+const (
+	ShortlistStatusPending   ShortlistStatus = "pending"   // 待确认
+	ShortlistStatusApplied   ShortlistStatus = "applied"   // 已确认并执行
+	ShortlistStatusDiscarded ShortlistStatus = "discarded" // 已作废
+)
+
+// ShortlistDecision 入围结果
+type ShortlistDecision string
+
+const (
+	ShortlistDecisionApprove ShortlistDecision = "approve" // 入围（审核通过）
+	ShortlistDecisionReject  ShortlistDecision = "reject"  // 未入围（审核拒绝）
+)
+
+// ShortlistReport 投票入围报告
+// 投票结束后根据投票规则生成，项目管理员确认后才会批量通过或拒绝推荐项目
+type ShortlistReport struct {
+	ID              int             `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID         int             `json:"event_id" gorm:"not null;index"`
+	Mode            ShortlistMode   `json:"mode"`
+	TopK            int             `json:"top_k"`
+	Threshold       int             `json:"threshold"`
+	Status          ShortlistStatus `json:"status" gorm:"default:'pending'"`
+	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	ConfirmedAt     *time.Time      `json:"confirmed_at,omitempty"`
+	ConfirmedByID   *int            `json:"confirmed_by_id,omitempty"`
+	ConfirmedByName string          `json:"confirmed_by_name,omitempty" gorm:"-"` // 忽略该字段，通过join获取
+
+	// 关联关系
+	Items []*ShortlistItem `json:"items,omitempty" gorm:"foreignKey:ReportID"`
+}
+
+// ShortlistItem 入围报告中的单个推荐项目
+type ShortlistItem struct {
+	ID              int               `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReportID        int               `json:"report_id" gorm:"not null;index"`
+	CompetitionID   int               `json:"competition_id" gorm:"not null"`
+	CompetitionName string            `json:"competition_name"`
+	VoteCount       int               `json:"vote_count"`
+	Rank            int               `json:"rank"`
+	Decision        ShortlistDecision `json:"decision"`
+	Skipped         bool              `json:"skipped" gorm:"default:false"` // 确认时项目已不再待审核（如已被人工审核），未做处理
+}
@@ -6,6 +6,7 @@ type Statistics struct {
 	LatestScores              []*Score               `json:"latest_scores,omitempty"`
 	CompletedCompetitionCount int                    `json:"completed_competition_count"`
 	RemainingCompetitionCount int                    `json:"remaining_competition_count"`
+	CancelledCompetitionCount int                    `json:"cancelled_competition_count"`
 	TopClasses                []ClassPointsSummary   `json:"top_classes,omitempty"`  // 前8名班级
 	TopStudents               []StudentPointsSummary `json:"top_students,omitempty"` // 前8名学生
 }
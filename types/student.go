@@ -10,5 +10,8 @@ type Student struct {
 	ClassID    int    `json:"class_id" gorm:"not null"`
 	ClassName  string `json:"class_name" gorm:"-"` // 忽略该字段，通过join获取
 	DingTalkID string `json:"ding_talk_id" gorm:"default:'0'"`
+	ExternalID string `json:"external_id" gorm:"index"` // 统一身份认证中的外部ID
 	Class      Class  `json:"class" gorm:"foreignKey:ClassID"`
+	// 需修改密码：账号创建或重置密码后，须先修改密码才能使用其他功能
+	MustChangePassword bool `json:"must_change_password" gorm:"default:false"`
 }
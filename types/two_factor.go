@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+// TwoFactorRecoveryCode 两步验证恢复码
+// 验证器应用丢失时可代替动态验证码登录，每个恢复码只能使用一次
+type TwoFactorRecoveryCode struct {
+	ID        int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int        `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"` // 恢复码摘要（不保存明文）
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TwoFactorChallenge 两步验证登录请求
+// 账号密码、钉钉或统一身份认证验证通过后生成，凭其提交动态验证码完成登录，使用一次或验证码错误次数过多后删除
+type TwoFactorChallenge struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int       `json:"user_id" gorm:"not null;index"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex;not null"`
+	Method    string    `json:"method" gorm:"not null;default:password"` // 第一步验证使用的登录方式，完成后按此方式创建会话
+	Attempts  int       `json:"attempts" gorm:"not null;default:0"`      // 验证码错误次数
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+}
@@ -2,11 +2,20 @@ package types
 
 // User 用户模型
 type User struct {
-	ID          int     `json:"id" gorm:"primaryKey;autoIncrement"`
-	Username    string  `json:"username" gorm:"unique;not null"`
-	Password    string  `json:"-" gorm:"not null"` // 不暴露密码
-	FullName    string  `json:"full_name" gorm:"not null"`
-	Permission  int     `json:"permission" gorm:"not null;default:0"`
-	DingTalkID  string  `json:"ding_talk_id" gorm:"default:'0'"`
-	ClassScopes []Class `json:"class_scopes,omitempty" gorm:"many2many:user_class_scopes;"` // 班级权限范围
+	ID         int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Username   string `json:"username" gorm:"unique;not null"`
+	Password   string `json:"-" gorm:"not null"` // 不暴露密码
+	FullName   string `json:"full_name" gorm:"not null"`
+	Permission int    `json:"permission" gorm:"not null;default:0"`
+	DingTalkID string `json:"ding_talk_id" gorm:"default:'0'"`
+	ExternalID string `json:"external_id" gorm:"index"` // 统一身份认证中的外部ID
+	// 裁判账号：仅能查看、录入和审核被指派的比赛项目的成绩
+	CompetitionScoped bool `json:"competition_scoped" gorm:"default:false"`
+	// 需修改密码：账号由管理员创建或重置密码后，须先修改密码才能使用其他功能
+	MustChangePassword bool `json:"must_change_password" gorm:"default:false"`
+	// 两步验证：启用后账号密码、钉钉与统一身份认证登录还需输入验证器应用生成的动态验证码
+	TwoFactorEnabled bool    `json:"two_factor_enabled" gorm:"default:false"`
+	TOTPSecret       string  `json:"-"`                                                          // 动态验证码密钥（启用前为待确认的密钥）
+	TOTPLastUsedStep int64   `json:"-" gorm:"default:0"`                                         // 最近一次使用的验证码时间片，防止验证码被重复使用
+	ClassScopes      []Class `json:"class_scopes,omitempty" gorm:"many2many:user_class_scopes;"` // 班级权限范围
 }
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// Venue 比赛场地（如田径场、跳远沙坑、体育馆）
+type Venue struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"default:''"`
+	Capacity    int       `json:"capacity" gorm:"default:0"`    // 同时容纳的参赛人数，0表示无限制
+	OpenTime    string    `json:"open_time" gorm:"default:''"`  // 每日开放时间，格式 HH:MM，为空表示不限制
+	CloseTime   string    `json:"close_time" gorm:"default:''"` // 每日关闭时间，格式 HH:MM，为空表示不限制
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
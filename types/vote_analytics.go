@@ -0,0 +1,59 @@
+package types
+
+// VoteTally 赞成票与反对票统计
+type VoteTally struct {
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+}
+
+// ClassVoteTally 按投票学生班级统计的投票
+type ClassVoteTally struct {
+	ClassID   int    `json:"class_id"`
+	ClassName string `json:"class_name"`
+	Grade     string `json:"grade"`
+	VoteTally
+}
+
+// GradeVoteTally 按投票学生年级统计的投票
+type GradeVoteTally struct {
+	Grade string `json:"grade"`
+	VoteTally
+}
+
+// GenderVoteTally 按投票学生性别统计的投票
+type GenderVoteTally struct {
+	Gender int `json:"gender"` // 1: 女, 2: 男
+	VoteTally
+}
+
+// CompetitionVoteAnalytics 单个推荐项目的投票分布
+type CompetitionVoteAnalytics struct {
+	CompetitionID   int               `json:"competition_id"`
+	CompetitionName string            `json:"competition_name"`
+	Status          CompetitionStatus `json:"status"`
+	VoteCount       int               `json:"vote_count"` // 净票数（赞成票减反对票）
+	VoteTally
+	ByClass  []*ClassVoteTally  `json:"by_class"`
+	ByGrade  []*GradeVoteTally  `json:"by_grade"`
+	ByGender []*GenderVoteTally `json:"by_gender"`
+}
+
+// ClassVoteTurnout 班级投票参与率
+type ClassVoteTurnout struct {
+	ClassID      int     `json:"class_id"`
+	ClassName    string  `json:"class_name"`
+	Grade        string  `json:"grade"`
+	StudentCount int     `json:"student_count"` // 班级学生人数
+	VoterCount   int     `json:"voter_count"`   // 至少投过一票的学生人数
+	Turnout      float64 `json:"turnout"`       // 参与率（百分比）
+}
+
+// VoteAnalytics 届次投票分析报表
+type VoteAnalytics struct {
+	EventID        int                         `json:"event_id"`
+	TotalStudents  int                         `json:"total_students"`
+	TotalVoters    int                         `json:"total_voters"`
+	OverallTurnout float64                     `json:"overall_turnout"` // 全校参与率（百分比）
+	Competitions   []*CompetitionVoteAnalytics `json:"competitions"`
+	Turnout        []*ClassVoteTurnout         `json:"turnout"`
+}
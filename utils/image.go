@@ -48,3 +48,19 @@ func SaveBase64Image(base64Data, directory, prefix string, timestamp interface{}
 
 	return fileName, nil
 }
+
+// CopyImage 复制已上传的图片为新文件，返回新文件名
+// imagePath 为图片的访问路径（如 /uploads/xxx.jpg），文件需位于 directory 中
+func CopyImage(imagePath, directory, prefix string, timestamp interface{}) (string, error) {
+	data, err := os.ReadFile(filepath.Join(directory, filepath.Base(imagePath)))
+	if err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("%s_%v.jpg", prefix, timestamp)
+	if err := os.WriteFile(filepath.Join(directory, fileName), data, 0644); err != nil {
+		return "", err
+	}
+
+	return fileName, nil
+}
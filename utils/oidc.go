@@ -0,0 +1,355 @@
+package utils
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SHXZ-OSS/sports-meeting-system/config"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// OIDC 账号匹配方式
+const (
+	OIDCMatchByUsername   = "username"
+	OIDCMatchByExternalID = "external_id"
+)
+
+var (
+	ErrOIDCDisabled      = errors.New("未启用统一身份认证登录")
+	ErrOIDCInvalidToken  = errors.New("统一身份认证返回的身份令牌无效")
+	ErrOIDCClaimNotFound = errors.New("统一身份认证返回的信息中缺少用于匹配账号的字段")
+)
+
+// oidcMetadataTTL 身份提供方配置与签名公钥的缓存时长
+const oidcMetadataTTL = time.Hour
+
+// oidcHTTPClient 请求身份提供方使用的客户端
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// OIDCProviderMetadata 身份提供方配置（/.well-known/openid-configuration）
+type OIDCProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCUserInfo 身份令牌中用于匹配账号的信息
+type OIDCUserInfo struct {
+	Subject    string // 身份提供方中的用户标识（sub）
+	ExternalID string // 外部ID声明的值
+	Username   string // 用户名声明的值（仅在按用户名匹配时提取）
+	Name       string // 姓名
+}
+
+// oidcCache 身份提供方配置与签名公钥缓存
+var oidcCache struct {
+	sync.Mutex
+	issuer    string
+	metadata  *OIDCProviderMetadata
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// getOIDCMetadata 获取身份提供方配置，配置变更或缓存过期时重新获取
+func getOIDCMetadata() (*OIDCProviderMetadata, error) {
+	cfg := config.Get()
+	if !cfg.OIDC.Enabled || cfg.OIDC.Issuer == "" || cfg.OIDC.ClientID == "" {
+		return nil, ErrOIDCDisabled
+	}
+	issuer := strings.TrimSuffix(cfg.OIDC.Issuer, "/")
+
+	oidcCache.Lock()
+	defer oidcCache.Unlock()
+
+	if oidcCache.metadata != nil && oidcCache.issuer == issuer && time.Since(oidcCache.fetchedAt) < oidcMetadataTTL {
+		return oidcCache.metadata, nil
+	}
+
+	var metadata OIDCProviderMetadata
+	if err := oidcGetJSON(issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("获取统一身份认证配置失败: %v", err)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" {
+		return nil, errors.New("统一身份认证配置缺少授权或令牌地址")
+	}
+
+	oidcCache.issuer = issuer
+	oidcCache.metadata = &metadata
+	oidcCache.keys = nil
+	oidcCache.fetchedAt = time.Now()
+	return &metadata, nil
+}
+
+// getOIDCSigningKey 获取指定 kid 的签名公钥，找不到时重新获取公钥（身份提供方可能已轮换密钥）
+func getOIDCSigningKey(metadata *OIDCProviderMetadata, kid string) (*rsa.PublicKey, error) {
+	oidcCache.Lock()
+	defer oidcCache.Unlock()
+
+	if key := findOIDCKey(oidcCache.keys, kid); key != nil {
+		return key, nil
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := oidcGetJSON(metadata.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("获取统一身份认证签名公钥失败: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	oidcCache.keys = keys
+
+	if key := findOIDCKey(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, ErrOIDCInvalidToken
+}
+
+// findOIDCKey 按 kid 查找公钥，令牌未指定 kid 且只有一个公钥时使用该公钥
+func findOIDCKey(keys map[string]*rsa.PublicKey, kid string) *rsa.PublicKey {
+	if key, ok := keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// oidcGetJSON 请求并解析 JSON
+func oidcGetJSON(url string, v interface{}) error {
+	resp, err := oidcHTTPClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// OIDCCodeChallenge 计算 PKCE 的 code_challenge（S256）
+func OIDCCodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// GetOIDCAuthorizationURL 生成跳转到身份提供方的授权地址
+func GetOIDCAuthorizationURL(state, nonce, codeVerifier string) (string, error) {
+	metadata, err := getOIDCMetadata()
+	if err != nil {
+		return "", err
+	}
+	cfg := config.Get()
+
+	scopes := cfg.OIDC.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid"}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.OIDC.ClientID)
+	query.Set("redirect_uri", cfg.OIDC.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", OIDCCodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// ExchangeOIDCCode 使用授权码换取身份令牌，并验证后返回用于匹配账号的信息
+func ExchangeOIDCCode(code, codeVerifier, nonce string) (*OIDCUserInfo, error) {
+	metadata, err := getOIDCMetadata()
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.Get()
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.OIDC.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cfg.OIDC.ClientID), url.QueryEscape(cfg.OIDC.ClientSecret))
+
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求统一身份认证令牌失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取统一身份认证令牌失败: %v", err)
+	}
+
+	var result struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析统一身份认证令牌失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Error != "" {
+		return nil, fmt.Errorf("统一身份认证授权失败: %s %s", result.Error, result.ErrorDescription)
+	}
+	if result.IDToken == "" {
+		return nil, ErrOIDCInvalidToken
+	}
+
+	claims, err := verifyOIDCIDToken(metadata, result.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return oidcUserInfoFromClaims(claims)
+}
+
+// verifyOIDCIDToken 验证身份令牌的签名、签发方、受众、有效期与 nonce
+func verifyOIDCIDToken(metadata *OIDCProviderMetadata, rawToken, nonce string) (jwt.MapClaims, error) {
+	cfg := config.Get()
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA:
+			kid, _ := token.Header["kid"].(string)
+			return getOIDCSigningKey(metadata, kid)
+		case *jwt.SigningMethodHMAC:
+			// 部分身份提供方使用客户端密钥签名
+			if cfg.OIDC.ClientSecret == "" {
+				return nil, ErrOIDCInvalidToken
+			}
+			return []byte(cfg.OIDC.ClientSecret), nil
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrOIDCInvalidToken
+	}
+
+	// 签发方
+	issuer := metadata.Issuer
+	if issuer == "" {
+		issuer = strings.TrimSuffix(cfg.OIDC.Issuer, "/")
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, ErrOIDCInvalidToken
+	}
+
+	// 受众需包含本系统的客户端ID（可能为字符串或数组）
+	audienceMatched := false
+	switch aud := claims["aud"].(type) {
+	case string:
+		audienceMatched = aud == cfg.OIDC.ClientID
+	case []interface{}:
+		for _, item := range aud {
+			if item == cfg.OIDC.ClientID {
+				audienceMatched = true
+				break
+			}
+		}
+	}
+	if !audienceMatched {
+		return nil, ErrOIDCInvalidToken
+	}
+
+	// 必须包含有效期，并与授权请求的 nonce 一致
+	if _, ok := claims["exp"]; !ok {
+		return nil, ErrOIDCInvalidToken
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, ErrOIDCInvalidToken
+	}
+
+	return claims, nil
+}
+
+// oidcUserInfoFromClaims 按配置的匹配方式从身份令牌中提取账号信息
+// 用户名声明通常可由用户在身份提供方自行修改，仅在显式配置按用户名匹配时提取
+func oidcUserInfoFromClaims(claims jwt.MapClaims) (*OIDCUserInfo, error) {
+	cfg := config.Get()
+
+	info := &OIDCUserInfo{
+		Subject:    oidcClaimString(claims, "sub"),
+		ExternalID: oidcClaimString(claims, cfg.OIDC.ExternalIDClaim),
+		Name:       oidcClaimString(claims, "name"),
+	}
+	if cfg.OIDC.MatchBy == OIDCMatchByUsername {
+		info.Username = oidcClaimString(claims, cfg.OIDC.UsernameClaim)
+	}
+	if info.ExternalID == "" && info.Username == "" {
+		return nil, ErrOIDCClaimNotFound
+	}
+
+	return info, nil
+}
+
+// oidcClaimString 将声明的值转换为字符串（部分身份提供方的编号为数字）
+func oidcClaimString(claims jwt.MapClaims, name string) string {
+	switch value := claims[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -2,10 +2,21 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"math/big"
 )
 
+// MinPasswordLength 用户自行设置的密码最小长度
+const MinPasswordLength = 8
+
+var (
+	ErrPasswordTooShort  = errors.New("新密码长度不能少于8位")
+	ErrPasswordUnchanged = errors.New("新密码不能与原密码相同")
+)
+
 const (
 	lowercaseChars = "abcdefghijklmnopqrstuvwxyz"
 	uppercaseChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -79,3 +90,37 @@ func GenerateSecureToken(length int) (string, error) {
 	// 转换为Base64
 	return base64.StdEncoding.EncodeToString(b)[:length], nil
 }
+
+// GenerateURLSafeToken 生成可直接放入链接的随机令牌
+func GenerateURLSafeToken(length int) (string, error) {
+	if length < 16 {
+		length = 16
+	}
+
+	// 生成随机字节
+	b := make([]byte, length)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	// 转换为URL安全的Base64
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken 计算令牌的 SHA-256 摘要，用于在数据库中保存刷新令牌等敏感令牌
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateNewPassword 验证用户自行设置的新密码
+func ValidateNewPassword(oldPassword, newPassword string) error {
+	if len(newPassword) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if newPassword == oldPassword {
+		return ErrPasswordUnchanged
+	}
+	return nil
+}
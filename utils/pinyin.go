@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
+	"unicode"
 
 	"github.com/mozillazg/go-pinyin"
 )
@@ -41,3 +42,100 @@ func GenerateStudentUsername(fullName string) (string, error) {
 
 	return username, nil
 }
+
+// DuplicateNameThreshold 名称相似度达到该值即视为疑似重复的推荐项目
+const DuplicateNameThreshold = 0.75
+
+// competitionNameSuffixes 比赛名称中不影响含义的后缀，归一化时去除
+var competitionNameSuffixes = []string{"比赛", "竞赛", "项目", "大赛", "赛", "competition", "contest", "race", "game"}
+
+// competitionNameAliases 常见项目的英文名称，归一化时替换为中文名称后再转换为拼音
+var competitionNameAliases = map[string]string{
+	"tugofwar":     "拔河",
+	"ropeskipping": "跳绳",
+	"jumprope":     "跳绳",
+	"longjump":     "跳远",
+	"highjump":     "跳高",
+	"shotput":      "铅球",
+	"relay":        "接力",
+	"basketball":   "篮球",
+	"football":     "足球",
+	"soccer":       "足球",
+	"volleyball":   "排球",
+	"badminton":    "羽毛球",
+	"tabletennis":  "乒乓球",
+	"pingpong":     "乒乓球",
+	"sprint":       "短跑",
+	"marathon":     "长跑",
+}
+
+// NormalizeCompetitionName 将比赛名称归一化为小写拼音（去除空白、标点与常见后缀，常见英文名称替换为中文）
+// 用于重复推荐项目检测，例如"拔河"、"拔河比赛"与"Tug of War"均归一化为"bahe"
+func NormalizeCompetitionName(name string) string {
+	// 去除空白与标点，统一小写
+	var cleaned strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			cleaned.WriteRune(r)
+		}
+	}
+	normalized := cleaned.String()
+
+	// 去除常见后缀（仅去除一次，避免名称被清空）
+	for _, suffix := range competitionNameSuffixes {
+		if strings.HasSuffix(normalized, suffix) && len(normalized) > len(suffix) {
+			normalized = strings.TrimSuffix(normalized, suffix)
+			break
+		}
+	}
+
+	if alias, ok := competitionNameAliases[normalized]; ok {
+		normalized = alias
+	}
+
+	// 汉字转换为拼音，其余字符保留
+	args := pinyin.NewArgs()
+	args.Fallback = func(r rune, a pinyin.Args) []string {
+		return []string{string(r)}
+	}
+
+	var result strings.Builder
+	for _, py := range pinyin.Pinyin(normalized, args) {
+		if len(py) > 0 {
+			result.WriteString(py[0])
+		}
+	}
+	return result.String()
+}
+
+// CompetitionNameSimilarity 计算两个比赛名称归一化后的相似度（0~1，基于编辑距离）
+func CompetitionNameSimilarity(a, b string) float64 {
+	na := []rune(NormalizeCompetitionName(a))
+	nb := []rune(NormalizeCompetitionName(b))
+	if len(na) == 0 || len(nb) == 0 {
+		return 0
+	}
+	if string(na) == string(nb) {
+		return 1
+	}
+
+	// 计算编辑距离
+	prev := make([]int, len(nb)+1)
+	curr := make([]int, len(nb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(na); i++ {
+		curr[0] = i
+		for j := 1; j <= len(nb); j++ {
+			cost := 1
+			if na[i-1] == nb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(nb)])/float64(max(len(na), len(nb)))
+}
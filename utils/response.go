@@ -42,6 +42,16 @@ func ResponseSuccessWithCustomMessage(c *gin.Context, message string) {
 	c.JSON(http.StatusOK, response)
 }
 
+// ResponseOKWithMessage 成功响应，带自定义消息和数据
+func ResponseOKWithMessage(c *gin.Context, message string, data any) {
+	response := Response{
+		Code:    200,
+		Message: message,
+		Data:    data,
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 // ResponsePaginated 分页成功响应
 func ResponsePaginated(c *gin.Context, data any, total, page, size int) {
 	response := PaginatedResponse{
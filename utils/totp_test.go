@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret RFC 6238 附录B中 SHA1 测试向量使用的密钥 "12345678901234567890"（Base32 编码）
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("解码密钥失败: %v", err)
+	}
+
+	// RFC 6238 给出的是8位验证码，这里取其后6位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		if got := totpCode(key, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("解码密钥失败: %v", err)
+	}
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+	codeAt := func(step int64) string { return totpCode(key, step) }
+
+	tests := []struct {
+		name         string
+		secret       string
+		code         string
+		lastUsedStep int64
+		wantStep     int64
+		wantOK       bool
+	}{
+		{"当前时间片", rfc6238Secret, codeAt(current), 0, current, true},
+		{"前一个时间片", rfc6238Secret, codeAt(current - 1), 0, current - 1, true},
+		{"后一个时间片", rfc6238Secret, codeAt(current + 1), 0, current + 1, true},
+		{"超出允许偏差的旧验证码", rfc6238Secret, codeAt(current - 2), 0, 0, false},
+		{"超出允许偏差的新验证码", rfc6238Secret, codeAt(current + 2), 0, 0, false},
+		{"同一时间片不能重复使用", rfc6238Secret, codeAt(current), current, 0, false},
+		{"不能使用早于已用时间片的验证码", rfc6238Secret, codeAt(current - 1), current - 1, 0, false},
+		{"已用前一时间片时仍可使用当前时间片", rfc6238Secret, codeAt(current), current - 1, current, true},
+		{"忽略首尾空格", rfc6238Secret, " " + codeAt(current) + " ", 0, current, true},
+		{"密钥不区分大小写", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", codeAt(current), 0, current, true},
+		{"验证码位数不正确", rfc6238Secret, "12345", 0, 0, false},
+		{"验证码错误", rfc6238Secret, "000000", 0, 0, false},
+		{"密钥无效", "!!!", codeAt(current), 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, tt.lastUsedStep, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"ABCDE-FGHJK", "ABCDEFGHJK"},
+		{"abcde-fghjk", "ABCDEFGHJK"},
+		{" ABCDE FGHJK ", "ABCDEFGHJK"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.code); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
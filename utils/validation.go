@@ -2,6 +2,8 @@ package utils
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/SHXZ-OSS/sports-meeting-system/config"
@@ -26,6 +28,25 @@ var (
 	ErrMaxLessThanMin               = errors.New("最大报名人数不能小于最小报名人数")
 	ErrInvalidStatusForRegistration = errors.New("当前比赛状态不允许报名或取消报名")
 	ErrEndTimeBeforeStartTime       = errors.New("结束时间不能早于开始时间")
+	ErrConsentPending               = errors.New("已提交报名，正在等待家长确认")
+	ErrClassParticipantsFull        = errors.New("该班级报名人数已达到该项目的每班人数上限")
+	ErrStudentIneligible            = errors.New("该学生当前不符合该项目的参赛资格")
+	ErrInvalidEligibilityType       = errors.New("无效的参赛资格限制类型")
+	ErrInvalidEligibilityCategory   = errors.New("受影响的项目类别须为 all、individual、team 或比赛项目ID，多个以逗号分隔")
+	ErrInvalidStatusTransition      = errors.New("不允许从当前状态变更为目标状态")
+	ErrStatusReasonRequired         = errors.New("延期或取消比赛时必须填写原因")
+	ErrInvalidStatusForScoreInput   = errors.New("该项目当前状态不允许录入成绩")
+	ErrVenueNotFound                = errors.New("比赛场地不存在")
+	ErrVenueDoubleBooked            = errors.New("该场地在此时间段已安排其他比赛")
+	ErrVenueClosed                  = errors.New("比赛时间不在场地开放时间内")
+	ErrInvalidVenueHours            = errors.New("场地开放时间格式应为 HH:MM，且开放时间需早于关闭时间")
+	ErrProposalNotEditable          = errors.New("该项目当前状态不允许修改")
+	ErrNotProposalSubmitter         = errors.New("只能修改自己提交的项目")
+	ErrChangesReasonRequired        = errors.New("要求修改时必须填写原因")
+	ErrDownvoteDisabled             = errors.New("本届运动会不允许投反对票")
+	ErrUpvoteLimitReached           = errors.New("已达到本届可投赞成票数量上限")
+	ErrInvalidVotingRules           = errors.New("无效的投票规则")
+	ErrVotingNotEnded               = errors.New("项目投票尚未结束")
 )
 
 // 性别常量
@@ -59,11 +80,15 @@ func NewRegistrationValidator(db *gorm.DB) *RegistrationValidator {
 
 // IsTimeInRange 检查当前时间是否在指定范围内
 func IsTimeInRange(startTime, endTime string) bool {
+	return IsTimeInRangeAt(startTime, endTime, time.Now())
+}
+
+// IsTimeInRangeAt 检查指定时间是否在指定范围内
+func IsTimeInRangeAt(startTime, endTime string, now time.Time) bool {
 	if startTime == "" || endTime == "" {
 		return true // 如果没有配置时间限制，默认允许
 	}
 
-	now := time.Now()
 	start, err := time.Parse("2006-01-02 15:04:05", startTime)
 	if err != nil {
 		return true // 解析失败时默认允许
@@ -76,31 +101,108 @@ func IsTimeInRange(startTime, endTime string) bool {
 	return now.After(start) && now.Before(end)
 }
 
-// IsSubmissionAllowed 检查是否允许项目征集提交
-func IsSubmissionAllowed() bool {
+// DefaultEventSettings 根据全局配置生成运动会届次的默认设置
+func DefaultEventSettings() types.EventSettings {
+	settings := types.EventSettings{}
 	cfg := config.Get()
 	if cfg == nil {
+		return settings
+	}
+	settings.SubmissionStartTime = cfg.Competition.SubmissionStartTime
+	settings.SubmissionEndTime = cfg.Competition.SubmissionEndTime
+	settings.VotingStartTime = cfg.Competition.VotingStartTime
+	settings.VotingEndTime = cfg.Competition.VotingEndTime
+	settings.RegistrationStartTime = cfg.Competition.RegistrationStartTime
+	settings.RegistrationEndTime = cfg.Competition.RegistrationEndTime
+	settings.MaxRegistrationsPerPerson = cfg.Competition.MaxRegistrationsPerPerson
+	settings.TeamPointsMapping = cfg.Scoring.TeamPointsMapping
+	settings.IndividualPointsMapping = cfg.Scoring.IndividualPointsMapping
+	return settings
+}
+
+// LoadEventSettings 获取指定运动会届次的设置
+// 届次不存在或尚未设置得分映射时，使用全局配置中的默认值
+func LoadEventSettings(db *gorm.DB, eventID int) (*types.EventSettings, error) {
+	var event types.Event
+	if err := db.First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			settings := DefaultEventSettings()
+			return &settings, nil
+		}
+		return nil, err
+	}
+
+	settings := event.Settings
+	if settings.TeamPointsMapping == nil || settings.IndividualPointsMapping == nil {
+		defaults := DefaultEventSettings()
+		if settings.TeamPointsMapping == nil {
+			settings.TeamPointsMapping = defaults.TeamPointsMapping
+		}
+		if settings.IndividualPointsMapping == nil {
+			settings.IndividualPointsMapping = defaults.IndividualPointsMapping
+		}
+	}
+	return &settings, nil
+}
+
+// IsSubmissionAllowed 检查是否允许项目征集提交
+func IsSubmissionAllowed(settings *types.EventSettings) bool {
+	if settings == nil {
 		return true
 	}
-	return IsTimeInRange(cfg.Competition.SubmissionStartTime, cfg.Competition.SubmissionEndTime)
+	return IsTimeInRange(settings.SubmissionStartTime, settings.SubmissionEndTime)
 }
 
 // IsVotingAllowed 检查是否允许投票
-func IsVotingAllowed() bool {
-	cfg := config.Get()
-	if cfg == nil {
+func IsVotingAllowed(settings *types.EventSettings) bool {
+	if settings == nil {
 		return true
 	}
-	return IsTimeInRange(cfg.Competition.VotingStartTime, cfg.Competition.VotingEndTime)
+	return IsTimeInRange(settings.VotingStartTime, settings.VotingEndTime)
+}
+
+// IsVotingEnded 检查项目投票是否已结束
+// 未配置或无法解析投票结束时间时视为未结束
+func IsVotingEnded(settings *types.EventSettings) bool {
+	if settings == nil || settings.VotingEndTime == "" {
+		return false
+	}
+	end, err := time.Parse("2006-01-02 15:04:05", settings.VotingEndTime)
+	if err != nil {
+		return false
+	}
+	return time.Now().After(end)
+}
+
+// ValidateVotingRules 验证投票规则
+func ValidateVotingRules(rules *types.VotingRules) error {
+	if rules.MaxUpvotesPerStudent < 0 || rules.ShortlistTopK < 0 {
+		return ErrInvalidVotingRules
+	}
+	switch rules.ShortlistMode {
+	case types.ShortlistModeNone, types.ShortlistModeThreshold:
+		return nil
+	case types.ShortlistModeTopK:
+		if rules.ShortlistTopK == 0 {
+			return ErrInvalidVotingRules
+		}
+		return nil
+	default:
+		return ErrInvalidVotingRules
+	}
 }
 
 // IsRegistrationAllowed 检查是否允许报名
-func IsRegistrationAllowed() bool {
-	cfg := config.Get()
-	if cfg == nil {
+func IsRegistrationAllowed(settings *types.EventSettings) bool {
+	return IsRegistrationAllowedAt(settings, time.Now())
+}
+
+// IsRegistrationAllowedAt 检查指定时间是否处于报名时间内
+func IsRegistrationAllowedAt(settings *types.EventSettings, at time.Time) bool {
+	if settings == nil {
 		return true
 	}
-	return IsTimeInRange(cfg.Competition.RegistrationStartTime, cfg.Competition.RegistrationEndTime)
+	return IsTimeInRangeAt(settings.RegistrationStartTime, settings.RegistrationEndTime, at)
 }
 
 // ==== 比赛相关验证函数 ====
@@ -138,6 +240,59 @@ func IsCompetitionStatusValidForRegistration(status types.CompetitionStatus) boo
 	return status == types.StatusApproved
 }
 
+// IsProposalStatus 检查比赛是否仍处于项目征集审核阶段（待审核、已拒绝、需修改），此类项目不对外公开
+func IsProposalStatus(status types.CompetitionStatus) bool {
+	switch status {
+	case types.StatusPendingApproval, types.StatusRejected, types.StatusChangesRequested:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsProposalEditable 检查学生推荐项目当前状态是否允许修改并重新提交
+func IsProposalEditable(status types.CompetitionStatus) bool {
+	return status == types.StatusPendingApproval || status == types.StatusChangesRequested
+}
+
+// IsCompetitionStatusValidForScoreInput 检查比赛状态是否允许录入成绩
+func IsCompetitionStatusValidForScoreInput(status types.CompetitionStatus) bool {
+	switch status {
+	case types.StatusApproved, types.StatusInProgress, types.StatusPendingScoreReview, types.StatusCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// competitionStatusTransitions 比赛状态手动变更规则（源状态 -> 允许的目标状态）
+// 审核、成绩提交与成绩审核引起的状态变化由对应流程处理，不在此表中
+var competitionStatusTransitions = map[types.CompetitionStatus][]types.CompetitionStatus{
+	types.StatusApproved:   {types.StatusInProgress, types.StatusPostponed, types.StatusCancelled},
+	types.StatusInProgress: {types.StatusApproved, types.StatusPostponed, types.StatusCancelled},
+	types.StatusPostponed:  {types.StatusApproved, types.StatusInProgress, types.StatusCancelled},
+	types.StatusCancelled:  {types.StatusApproved},
+}
+
+// IsStatusTransitionAllowed 检查比赛状态能否从 from 手动变更为 to
+func IsStatusTransitionAllowed(from, to types.CompetitionStatus) bool {
+	for _, status := range competitionStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAllowedStatusTransitions 获取比赛当前状态允许变更的目标状态
+func GetAllowedStatusTransitions(from types.CompetitionStatus) []types.CompetitionStatus {
+	allowed := competitionStatusTransitions[from]
+	if allowed == nil {
+		return []types.CompetitionStatus{}
+	}
+	return allowed
+}
+
 // ValidateCompetitionStatus 验证比赛状态
 func ValidateCompetitionStatus(status types.CompetitionStatus) bool {
 	validStatuses := []types.CompetitionStatus{
@@ -146,6 +301,10 @@ func ValidateCompetitionStatus(status types.CompetitionStatus) bool {
 		types.StatusRejected,
 		types.StatusPendingScoreReview,
 		types.StatusCompleted,
+		types.StatusInProgress,
+		types.StatusPostponed,
+		types.StatusCancelled,
+		types.StatusChangesRequested,
 	}
 
 	for _, validStatus := range validStatuses {
@@ -163,8 +322,14 @@ func (cv *CompetitionValidator) ValidateCompetitionSubmission(name, unit string,
 	currentEventID := cfg.CurrentEventID
 
 	// 检查项目征集时间限制（管理员无此限制）
-	if !isAdmin && !IsSubmissionAllowed() {
-		return ErrSubmissionNotAllowed
+	if !isAdmin {
+		settings, err := LoadEventSettings(cv.db, currentEventID)
+		if err != nil {
+			return err
+		}
+		if !IsSubmissionAllowed(settings) {
+			return ErrSubmissionNotAllowed
+		}
 	}
 
 	// 检查性别是否合法
@@ -199,6 +364,19 @@ func (cv *CompetitionValidator) ValidateCompetitionSubmission(name, unit string,
 	return nil
 }
 
+// ValidateProposalResubmission 验证学生修改并重新提交推荐项目（需在项目所属届次的征集时间内）
+func (cv *CompetitionValidator) ValidateProposalResubmission(competition *types.Competition) error {
+	settings, err := LoadEventSettings(cv.db, competition.EventID)
+	if err != nil {
+		return err
+	}
+	if !IsSubmissionAllowed(settings) {
+		return ErrSubmissionNotAllowed
+	}
+
+	return cv.ValidateCompetitionUpdate(competition)
+}
+
 // ValidateCompetitionUpdate 验证比赛项目更新
 func (cv *CompetitionValidator) ValidateCompetitionUpdate(competition *types.Competition) error {
 	// 获取当前选中的 EventID
@@ -258,10 +436,95 @@ func (cv *CompetitionValidator) CheckCompetitionExists(id int) (*types.Competiti
 	return &competition, nil
 }
 
+// ==== 场地相关验证函数 ====
+
+// ValidateVenueHours 验证场地开放时间格式
+func ValidateVenueHours(openTime, closeTime string) error {
+	if openTime == "" && closeTime == "" {
+		return nil
+	}
+	openAt, err := time.Parse("15:04", openTime)
+	if err != nil {
+		return ErrInvalidVenueHours
+	}
+	closeAt, err := time.Parse("15:04", closeTime)
+	if err != nil {
+		return ErrInvalidVenueHours
+	}
+	if !openAt.Before(closeAt) {
+		return ErrInvalidVenueHours
+	}
+	return nil
+}
+
+// IsWithinVenueHours 检查比赛时间是否在场地开放时间内（比赛需在同一天内开始和结束）
+func IsWithinVenueHours(venue *types.Venue, startTime, endTime time.Time) bool {
+	if venue.OpenTime == "" || venue.CloseTime == "" {
+		return true // 未设置开放时间，默认允许
+	}
+	if startTime.Year() != endTime.Year() || startTime.YearDay() != endTime.YearDay() {
+		return false
+	}
+	start := startTime.Format("15:04")
+	end := endTime.Format("15:04")
+	return start >= venue.OpenTime && end <= venue.CloseTime
+}
+
+// ValidateVenueBooking 验证比赛场地安排，同一场地的比赛时间不能重叠
+// competitionID: 当前比赛ID，新建比赛时为0
+func (cv *CompetitionValidator) ValidateVenueBooking(competitionID int, venueID *int, startTime, endTime *time.Time) error {
+	if venueID == nil {
+		return nil
+	}
+
+	var venue types.Venue
+	if err := cv.db.First(&venue, *venueID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVenueNotFound
+		}
+		return err
+	}
+
+	// 未设置比赛时间时无法判断冲突
+	if startTime == nil || endTime == nil {
+		return nil
+	}
+
+	if !IsWithinVenueHours(&venue, *startTime, *endTime) {
+		return ErrVenueClosed
+	}
+
+	// 检查同一届运动会中同一场地的时间冲突（已拒绝和已取消的项目不占用场地）
+	var count int64
+	if err := cv.db.Model(&types.Competition{}).
+		Where("venue_id = ? AND id != ? AND event_id = ? AND status NOT IN ?", *venueID, competitionID, config.Get().CurrentEventID,
+			[]types.CompetitionStatus{types.StatusRejected, types.StatusCancelled}).
+		Where("start_time IS NOT NULL AND end_time IS NOT NULL AND start_time < ? AND end_time > ?", *endTime, *startTime).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrVenueDoubleBooked
+	}
+
+	return nil
+}
+
 // ==== 报名相关验证函数 ====
 
 // ValidateRegistration 验证报名请求
 func (rv *RegistrationValidator) ValidateRegistration(studentID *int, classID *int, competitionID int, user *types.User) error {
+	return rv.validateRegistration(studentID, classID, competitionID, user, time.Now())
+}
+
+// ValidateConsentApproval 家长同意报名时重新验证报名请求
+// 报名时间按学生提交报名（创建确认记录）的时间检查，报名时间内提交的报名在确认有效期内均可同意
+func (rv *RegistrationValidator) ValidateConsentApproval(studentID, competitionID int, requestedAt time.Time) error {
+	return rv.validateRegistration(&studentID, nil, competitionID, nil, requestedAt)
+}
+
+// validateRegistration 验证报名请求，registeredAt 为检查报名时间所用的报名时刻
+func (rv *RegistrationValidator) validateRegistration(studentID *int, classID *int, competitionID int, user *types.User, registeredAt time.Time) error {
 	// 获取当前选中的 EventID
 	cfg := config.Get()
 	currentEventID := cfg.CurrentEventID
@@ -269,19 +532,25 @@ func (rv *RegistrationValidator) ValidateRegistration(studentID *int, classID *i
 	// 检查报名时间限制（学生报名时需要检查，非全局管理员也需要检查）
 	// 全局管理员的判断标准：user不为空且ClassScopes为空（没有班级范围限制）
 	isGlobalAdmin := user != nil && len(user.ClassScopes) == 0
-	if !isGlobalAdmin && !IsRegistrationAllowed() {
-		return ErrRegistrationNotAllowed
-	}
 
 	// 检查比赛是否存在
 	var competition types.Competition
-	if err := rv.db.Select("status, gender, competition_type").First(&competition, competitionID).Where("event_id = ?", currentEventID).Error; err != nil {
+	if err := rv.db.Select("status, gender, competition_type, event_id").Where("event_id = ?", currentEventID).First(&competition, competitionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrCompetitionNotFound
 		}
 		return err
 	}
 
+	// 读取比赛所属届次的设置
+	settings, err := LoadEventSettings(rv.db, competition.EventID)
+	if err != nil {
+		return err
+	}
+	if !isGlobalAdmin && !IsRegistrationAllowedAt(settings, registeredAt) {
+		return ErrRegistrationNotAllowed
+	}
+
 	if !IsCompetitionStatusValidForRegistration(competition.Status) {
 		return ErrInvalidStatusForRegistration
 	}
@@ -305,6 +574,11 @@ func (rv *RegistrationValidator) ValidateRegistration(studentID *int, classID *i
 		return ErrGenderMismatch
 	}
 
+	// 检查学生参赛资格（医疗免赛、违纪禁赛等）
+	if err := rv.CheckStudentEligibility(*studentID, competitionID); err != nil {
+		return err
+	}
+
 	// 检查是否已经报名
 	var count int64
 	if err := rv.db.Model(&types.Registration{}).Where("student_id = ? AND competition_id = ?", *studentID, competitionID).Count(&count).Error; err != nil {
@@ -314,20 +588,35 @@ func (rv *RegistrationValidator) ValidateRegistration(studentID *int, classID *i
 		return ErrAlreadyRegistered
 	}
 
+	// 检查是否有等待家长确认的报名
+	if err := rv.db.Model(&types.RegistrationConsent{}).Where("student_id = ? AND competition_id = ? AND status = ?", *studentID, competitionID, types.ConsentPending).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrConsentPending
+	}
+
 	// 检查学生报名数量限制（全局管理员无此限制，非全局管理员需要检查，仅统计个人比赛）
 	// 团体比赛不受个人报名数量限制
 	if !isGlobalAdmin && competition.CompetitionType == types.TypeIndividual {
-		cfg := config.Get()
-		if cfg != nil && cfg.Competition.MaxRegistrationsPerPerson > 0 {
+		if settings.MaxRegistrationsPerPerson > 0 {
 			var studentRegistrationCount int64
 			// 只统计个人比赛的报名数量，团体比赛不计入限制
 			if err := rv.db.Model(&types.Registration{}).
 				Joins("JOIN competitions ON registrations.competition_id = competitions.id").
-				Where("registrations.student_id = ? AND competitions.competition_type = ?", *studentID, types.TypeIndividual).
+				Where("registrations.student_id = ? AND competitions.competition_type = ? AND competitions.event_id = ?", *studentID, types.TypeIndividual, competition.EventID).
 				Count(&studentRegistrationCount).Error; err != nil {
 				return err
 			}
-			if int(studentRegistrationCount) >= cfg.Competition.MaxRegistrationsPerPerson {
+			// 等待家长确认的个人比赛报名同样占用名额
+			var pendingConsentCount int64
+			if err := rv.db.Model(&types.RegistrationConsent{}).
+				Joins("JOIN competitions ON registration_consents.competition_id = competitions.id").
+				Where("registration_consents.student_id = ? AND registration_consents.status = ? AND competitions.competition_type = ? AND competitions.event_id = ?", *studentID, types.ConsentPending, types.TypeIndividual, competition.EventID).
+				Count(&pendingConsentCount).Error; err != nil {
+				return err
+			}
+			if int(studentRegistrationCount+pendingConsentCount) >= settings.MaxRegistrationsPerPerson {
 				return ErrMaxRegistrationsReached
 			}
 		}
@@ -345,19 +634,25 @@ func (rv *RegistrationValidator) ValidateUnregistration(studentID *int, classID
 	// 检查报名时间限制（学生报名时需要检查，非全局管理员也需要检查）
 	// 全局管理员的判断标准：user不为空且ClassScopes为空（没有班级范围限制）
 	isGlobalAdmin := user != nil && len(user.ClassScopes) == 0
-	if !isGlobalAdmin && !IsRegistrationAllowed() {
-		return ErrRegistrationNotAllowed
-	}
 
 	// 检查比赛是否存在
 	var competition types.Competition
-	if err := rv.db.Select("status").First(&competition, competitionID).Where("event_id = ?", currentEventID).Error; err != nil {
+	if err := rv.db.Select("status, event_id").Where("event_id = ?", currentEventID).First(&competition, competitionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrCompetitionNotFound
 		}
 		return err
 	}
 
+	// 读取比赛所属届次的设置
+	settings, err := LoadEventSettings(rv.db, competition.EventID)
+	if err != nil {
+		return err
+	}
+	if !isGlobalAdmin && !IsRegistrationAllowed(settings) {
+		return ErrRegistrationNotAllowed
+	}
+
 	if !IsCompetitionStatusValidForRegistration(competition.Status) {
 		return ErrInvalidStatusForRegistration
 	}
@@ -390,6 +685,29 @@ func (rv *RegistrationValidator) CheckRegistrationExists(studentID, competitionI
 	return nil
 }
 
+// CheckClassParticipantLimit 检查班级报名人数是否已达到比赛的每班人数上限
+func (rv *RegistrationValidator) CheckClassParticipantLimit(classID, competitionID int) error {
+	var competition types.Competition
+	if err := rv.db.Select("max_participants_per_class").First(&competition, competitionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCompetitionNotFound
+		}
+		return err
+	}
+	if competition.MaxParticipantsPerClass <= 0 {
+		return nil
+	}
+
+	var count int64
+	if err := rv.db.Model(&types.Registration{}).Where("competition_id = ? AND class_id = ?", competitionID, classID).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) >= competition.MaxParticipantsPerClass {
+		return ErrClassParticipantsFull
+	}
+	return nil
+}
+
 // CheckStudentExists 检查学生是否存在
 func (rv *RegistrationValidator) CheckStudentExists(studentID int) (*types.Student, error) {
 	var student types.Student
@@ -402,17 +720,126 @@ func (rv *RegistrationValidator) CheckStudentExists(studentID int) (*types.Stude
 	return &student, nil
 }
 
+// ==== 参赛资格相关验证函数 ====
+
+// IsEligibilityTypeValid 检查参赛资格限制类型是否有效
+func IsEligibilityTypeValid(eligibilityType types.EligibilityType) bool {
+	return eligibilityType == types.EligibilityMedicalExemption || eligibilityType == types.EligibilityDisciplinaryBan
+}
+
+// SplitEligibilityCategories 拆分受影响项目类别，去除空白并忽略空项
+func SplitEligibilityCategories(categories string) []string {
+	var result []string
+	for _, category := range strings.Split(categories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			result = append(result, category)
+		}
+	}
+	return result
+}
+
+// ValidateEligibilityCategories 检查受影响项目类别是否有效
+// 每一项须为 all、individual、team 或比赛项目ID，空项忽略，但至少需要一项
+func ValidateEligibilityCategories(categories string) error {
+	items := SplitEligibilityCategories(categories)
+	if len(items) == 0 {
+		return ErrInvalidEligibilityCategory
+	}
+	for _, category := range items {
+		switch category {
+		case types.EligibilityCategoryAll, types.EligibilityCategoryIndividual, types.EligibilityCategoryTeam:
+			continue
+		}
+		if id, err := strconv.Atoi(category); err != nil || id <= 0 {
+			return ErrInvalidEligibilityCategory
+		}
+	}
+	return nil
+}
+
+// IsEligibilityActive 检查限制记录在指定时间是否有效
+func IsEligibilityActive(eligibility *types.StudentEligibility, at time.Time) bool {
+	if eligibility.StartDate != nil && at.Before(*eligibility.StartDate) {
+		return false
+	}
+	if eligibility.EndDate != nil && at.After(*eligibility.EndDate) {
+		return false
+	}
+	return true
+}
+
+// IsEligibilityApplicable 检查限制记录是否限制学生参加指定比赛
+// 以比赛开始时间判断有效期，比赛未设置时间时以当前时间判断
+func IsEligibilityApplicable(eligibility *types.StudentEligibility, competition *types.Competition) bool {
+	at := time.Now()
+	if competition.StartTime != nil {
+		at = *competition.StartTime
+	}
+	if !IsEligibilityActive(eligibility, at) {
+		return false
+	}
+
+	for _, category := range SplitEligibilityCategories(eligibility.Categories) {
+		switch category {
+		case types.EligibilityCategoryAll:
+			return true
+		case types.EligibilityCategoryIndividual:
+			if competition.CompetitionType == types.TypeIndividual {
+				return true
+			}
+		case types.EligibilityCategoryTeam:
+			if competition.CompetitionType == types.TypeTeam {
+				return true
+			}
+		default:
+			// 其他取值为比赛项目ID
+			if id, err := strconv.Atoi(category); err == nil && id == competition.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckStudentEligibility 检查学生是否有资格参加指定比赛
+func (rv *RegistrationValidator) CheckStudentEligibility(studentID, competitionID int) error {
+	var eligibilities []types.StudentEligibility
+	if err := rv.db.Where("student_id = ?", studentID).Find(&eligibilities).Error; err != nil {
+		return err
+	}
+	if len(eligibilities) == 0 {
+		return nil
+	}
+
+	var competition types.Competition
+	if err := rv.db.Select("id", "name", "competition_type", "start_time").First(&competition, competitionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCompetitionNotFound
+		}
+		return err
+	}
+
+	for i := range eligibilities {
+		if IsEligibilityApplicable(&eligibilities[i], &competition) {
+			return ErrStudentIneligible
+		}
+	}
+	return nil
+}
+
 // ==== 用户管理相关验证函数 ====
 
 var (
-	ErrScopeNotAllowedForPermissions    = errors.New("用户拥有学生管理和报名管理以外的权限时不能指定班级scope")
+	ErrScopeNotAllowedForPermissions    = errors.New("用户拥有学生管理和报名管理以外的权限时不能指定班级scope（启用班级成绩模式后还可拥有成绩提交权限）")
 	ErrPermissionExceedsOperator        = errors.New("不能创建/修改超出自己权限范围的用户")
 	ErrCannotModifyHigherPermissionUser = errors.New("不能修改权限更高的用户")
 	ErrNoPermissionsAssigned            = errors.New("必须为用户分配至少一种权限")
+	ErrCompetitionScopeNotAllowed       = errors.New("仅拥有成绩提交或成绩审核权限的用户可以限定为只处理指派的比赛项目")
 )
 
 // ValidateUserCreateOrUpdate 验证用户创建或更新操作
-func ValidateUserCreateOrUpdate(operatorPermission, targetPermission, originalPermission int, classScopes []int) error {
+// competitionScoped 为 true 时用户只能处理被指派的比赛项目，此时仅允许成绩提交与成绩审核权限
+func ValidateUserCreateOrUpdate(operatorPermission, targetPermission, originalPermission int, classScopes []int, competitionScoped bool) error {
 	// 检查是否设置超出自己权限范围的权限
 	if HasMorePermissions(targetPermission, operatorPermission) {
 		return ErrPermissionExceedsOperator
@@ -423,9 +850,9 @@ func ValidateUserCreateOrUpdate(operatorPermission, targetPermission, originalPe
 		return ErrCannotModifyHigherPermissionUser
 	}
 
-	// 验证权限和scope的配置
+	// 验证权限和scope的配置（成绩提交仅在启用班级成绩模式时支持scope，成绩审核始终不支持）
 	hasNonScopedPermissions := HasPermission(targetPermission, PermissionProjectManagement) ||
-		HasPermission(targetPermission, PermissionScoreInput) ||
+		(HasPermission(targetPermission, PermissionScoreInput) && !config.Get().ScoreEntry.ClassScoped) ||
 		HasPermission(targetPermission, PermissionScoreReview) ||
 		HasPermission(targetPermission, PermissionUserManagement) ||
 		HasPermission(targetPermission, PermissionWebsiteManagement)
@@ -437,6 +864,11 @@ func ValidateUserCreateOrUpdate(operatorPermission, targetPermission, originalPe
 		return ErrScopeNotAllowedForPermissions
 	}
 
+	// 限定指派比赛的用户（裁判账号）只能拥有成绩相关权限
+	if competitionScoped && targetPermission&^(PermissionScoreInput|PermissionScoreReview) != 0 {
+		return ErrCompetitionScopeNotAllowed
+	}
+
 	// 不能创建没有任何权限的用户
 	if targetPermission == 0 {
 		return ErrNoPermissionsAssigned